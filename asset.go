@@ -0,0 +1,25 @@
+package main
+
+// maxAssetCode is the longest code a Stellar asset can have, alphanum12
+const maxAssetCode = 12
+
+// validAssetCode reports whether code can be the code of a Stellar asset, 1 to 12 ASCII letters and digits
+func validAssetCode(code string) bool {
+	if len(code) == 0 || len(code) > maxAssetCode {
+		return false
+	}
+	for _, c := range code {
+		if (c < 'A' || c > 'Z') && (c < 'a' || c > 'z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// assetDescriptor is the CODE:ISSUER form wallets, anchors and the Stellar CLI take an asset in, empty without a code
+func assetDescriptor(code, issuer string) string {
+	if code == "" {
+		return ""
+	}
+	return code + ":" + issuer
+}
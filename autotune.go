@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"     // used for listing the measured rates
+	"log"     // used for announcing the measurements and the result
+	"strings" // used for joining the measured rates
+	"time"    // used for the length of every measurement and the time between them
+)
+
+// coresAuto is the -cores value that measures how many workers are worth running instead of taking a number
+const coresAuto = "auto"
+
+// tuneShares are the shares of the cores -cores auto measures, in percent
+var tuneShares = []int{25, 50, 75, 100}
+
+// tuneStep is how long -cores auto measures every share, all of them take about the first minute
+const tuneStep = 15 * time.Second
+
+// tuneEvery is how long -cores auto keeps the workers it settled on before measuring again, others may have started or
+// stopped using the cores of a shared machine in the meantime
+const tuneEvery = 30 * time.Minute
+
+// tuneMarginal is how much of the rate of a worker at the smallest share the workers added by a bigger share must each
+// contribute for it to be worth the power; hyper-threads and a busy machine add less than that
+const tuneMarginal = 0.5
+
+// autoTuner measures the rate of the workers at every share of tuneShares for -cores auto, settles on the biggest one
+// whose added workers still pay for themselves, and starts over every tuneEvery
+type autoTuner struct {
+	counts  []int     // the workers to measure, one per distinct share, fewest first
+	rates   []float64 // addresses per second measured for every count
+	step    int       // the index of counts being measured, -1 once settled
+	started time.Time // when the current measurement started, or when the tuner settled
+	scanned int64     // the addresses scanned when the current measurement started
+}
+
+// newAutoTuner returns the tuner of limit cores, measuring the fewest workers first; nil when there is nothing to
+// choose from
+func newAutoTuner(limit int) *autoTuner {
+	var counts []int
+	for _, share := range tuneShares {
+		count := max(1, (limit*share+99)/100)
+		if len(counts) == 0 || counts[len(counts)-1] != count {
+			counts = append(counts, count)
+		}
+	}
+	if len(counts) < 2 {
+		return nil
+	}
+	return &autoTuner{counts: counts, rates: make([]float64, len(counts))}
+}
+
+// Start begins measuring the first count at now with scanned addresses so far and returns it
+func (t *autoTuner) Start(now time.Time, scanned int64) int {
+	t.step, t.started, t.scanned = 0, now, scanned
+	log.Printf("-cores auto: measuring %s workers for %s each", joinInts(t.counts), tuneStep)
+	return t.counts[0]
+}
+
+// Tick takes the progress at now and returns how many workers should run from now on, 0 to leave them as they are; a
+// measurement during which the workers were paused starts over
+func (t *autoTuner) Tick(now time.Time, scanned int64, paused bool) int {
+	if t.step < 0 {
+		if now.Sub(t.started) < tuneEvery {
+			return 0
+		}
+		return t.Start(now, scanned)
+	}
+	if paused {
+		t.started, t.scanned = now, scanned
+		return 0
+	}
+	elapsed := now.Sub(t.started)
+	if elapsed < tuneStep {
+		return 0
+	}
+	t.rates[t.step] = float64(scanned-t.scanned) / elapsed.Seconds()
+	if t.step++; t.step < len(t.counts) {
+		t.started, t.scanned = now, scanned
+		return t.counts[t.step]
+	}
+
+	best := 0
+	perWorker := t.rates[0] / float64(t.counts[0])
+	for i := 1; i < len(t.counts); i++ {
+		added := (t.rates[i] - t.rates[best]) / float64(t.counts[i]-t.counts[best])
+		if added >= tuneMarginal*perWorker {
+			best = i
+		}
+	}
+	rates := make([]string, len(t.counts))
+	for i, rate := range t.rates {
+		rates[i] = fmt.Sprintf("%d: %s/s", t.counts[i], FormatInt64(int64(rate)))
+	}
+	log.Printf("-cores auto: settled on %d workers (%s), measuring again in %s", t.counts[best], strings.Join(rates, ", "), tuneEvery)
+	t.step, t.started = -1, now
+	return t.counts[best]
+}
+
+// joinInts lists numbers separated by commas
+func joinInts(numbers []int) string {
+	parts := make([]string, len(numbers))
+	for i, n := range numbers {
+		parts[i] = fmt.Sprint(n)
+	}
+	return strings.Join(parts, ", ")
+}
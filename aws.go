@@ -0,0 +1,262 @@
+package main
+
+import (
+	"context"                                                           // every AWS call runs with a timeout
+	"crypto/aes"                                                        // the data key is an AES-256 key
+	"crypto/cipher"                                                     // used for sealing seeds with AES-GCM
+	"crypto/rand"                                                       // used for the AES-GCM nonces
+	"encoding/base64"                                                   // used for storing the sealed seed as text
+	"encoding/json"                                                     // used for the Secrets Manager secret value
+	"errors"                                                            // used for reporting a missing region
+	"flag"                                                              // the kms subcommand parses its own arguments
+	"fmt"                                                               // used for wrapping errors with the key or secret
+	"github.com/aws/aws-sdk-go-v2/aws"                                  // used for the AWS configuration and pointers
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"                     // used for loading credentials like the aws CLI
+	"github.com/aws/aws-sdk-go-v2/service/kms"                          // the -kms-key envelope encryption
+	kmstypes "github.com/aws/aws-sdk-go-v2/service/kms/types"           // used for requesting AES-256 data keys
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"               // the secretsmanager:// -output
+	smtypes "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types" // used for tagging secrets with their pattern
+	"os"                                                                // access the filesystem
+	"strings"                                                           // used for parsing the secretsmanager:// -output
+	"time"                                                              // used for the AWS call timeout
+)
+
+// secretsManagerScheme prefixes an -output that stores every seed as its own AWS Secrets Manager secret
+const secretsManagerScheme = "secretsmanager://"
+
+// awsTimeout bounds every call to AWS
+const awsTimeout = 30 * time.Second
+
+// loadAWSConfig resolves the region and credentials the way the aws CLI does, from the environment, the shared config
+// files and finally the instance role of an EC2 (spot) instance, and makes sure credentials are actually available
+func loadAWSConfig() (aws.Config, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), awsTimeout)
+	defer cancel()
+	cfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return cfg, fmt.Errorf("failed to load the AWS configuration: %w", err)
+	}
+	if cfg.Region == "" {
+		return cfg, errors.New("no AWS region configured, set AWS_REGION")
+	}
+	if _, err := cfg.Credentials.Retrieve(ctx); err != nil {
+		return cfg, fmt.Errorf("no AWS credentials: %w", err)
+	}
+	return cfg, nil
+}
+
+// sealedSeed is a seed envelope-encrypted with -kms-key: a fresh AES-256 data key from KMS seals the seed and only the
+// copy of the data key encrypted by KMS is kept next to it, so reading the seed back takes kms:Decrypt on the key
+type sealedSeed struct {
+	KeyID      string `json:"key_id"`     // the ARN of the KMS key that encrypted DataKey
+	DataKey    string `json:"data_key"`   // the base64 data key encrypted by KMS, with the address as encryption context
+	Nonce      string `json:"nonce"`      // the base64 AES-GCM nonce
+	Ciphertext string `json:"ciphertext"` // the base64 AES-256-GCM sealed seed, authenticated together with the address
+}
+
+// kmsEnvelope seals every seed in the output files with a data key of its KMS key
+type kmsEnvelope struct {
+	client *kms.Client
+	keyID  string // the -kms-key ID, ARN or alias
+}
+
+// openKMSEnvelope checks that keyID can be used, so a typo or a missing permission fails now and not at the first match
+func openKMSEnvelope(keyID string) (*kmsEnvelope, error) {
+	cfg, err := loadAWSConfig()
+	if err != nil {
+		return nil, err
+	}
+	e := &kmsEnvelope{client: kms.NewFromConfig(cfg), keyID: keyID}
+	ctx, cancel := context.WithTimeout(context.Background(), awsTimeout)
+	defer cancel()
+	key, err := e.client.DescribeKey(ctx, &kms.DescribeKeyInput{KeyId: aws.String(keyID)})
+	if err != nil {
+		return nil, fmt.Errorf("-kms-key %s: %w", keyID, err)
+	}
+	debugf("sealing seeds with KMS key %s", aws.ToString(key.KeyMetadata.Arn))
+	return e, nil
+}
+
+// Store replaces the seed of r with the seed sealed by a new data key of the KMS key
+func (e *kmsEnvelope) Store(r *result) error {
+	ctx, cancel := context.WithTimeout(context.Background(), awsTimeout)
+	defer cancel()
+	dataKey, err := e.client.GenerateDataKey(ctx, &kms.GenerateDataKeyInput{
+		KeyId:             aws.String(e.keyID),
+		KeySpec:           kmstypes.DataKeySpecAes256,
+		EncryptionContext: map[string]string{"address": r.Address},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to generate a data key with %s: %w", e.keyID, err)
+	}
+	defer zeroize(dataKey.Plaintext)
+
+	gcm, err := newSeedCipher(dataKey.Plaintext)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	seed := []byte(r.Seed)
+	defer zeroize(seed)
+	r.EncryptedSeed = &sealedSeed{
+		KeyID:      aws.ToString(dataKey.KeyId),
+		DataKey:    base64.StdEncoding.EncodeToString(dataKey.CiphertextBlob),
+		Nonce:      base64.StdEncoding.EncodeToString(nonce),
+		Ciphertext: base64.StdEncoding.EncodeToString(gcm.Seal(nil, nonce, seed, []byte(r.Address))),
+	}
+	r.Seed = ""
+	return nil
+}
+
+// newSeedCipher returns the AES-GCM cipher of a data key
+func newSeedCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// unsealSeed asks KMS to decrypt the data key of r and opens its sealed seed with it
+func unsealSeed(client *kms.Client, r result) (string, error) {
+	sealed := r.EncryptedSeed
+	blob, blobErr := base64.StdEncoding.DecodeString(sealed.DataKey)
+	nonce, nonceErr := base64.StdEncoding.DecodeString(sealed.Nonce)
+	ciphertext, ciphertextErr := base64.StdEncoding.DecodeString(sealed.Ciphertext)
+	if err := errors.Join(blobErr, nonceErr, ciphertextErr); err != nil {
+		return "", fmt.Errorf("corrupt encrypted_seed: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), awsTimeout)
+	defer cancel()
+	dataKey, err := client.Decrypt(ctx, &kms.DecryptInput{
+		CiphertextBlob:    blob,
+		KeyId:             aws.String(sealed.KeyID),
+		EncryptionContext: map[string]string{"address": r.Address},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt the data key with %s: %w", sealed.KeyID, err)
+	}
+	defer zeroize(dataKey.Plaintext)
+
+	gcm, err := newSeedCipher(dataKey.Plaintext)
+	if err != nil {
+		return "", err
+	}
+	if len(nonce) != gcm.NonceSize() {
+		return "", errors.New("corrupt encrypted_seed: invalid nonce")
+	}
+	seed, err := gcm.Open(nil, nonce, ciphertext, []byte(r.Address))
+	if err != nil {
+		return "", fmt.Errorf("corrupt encrypted_seed: %w", err)
+	}
+	defer zeroize(seed)
+	return string(seed), nil
+}
+
+// runKMS implements `xlm-vanity-address-finder kms decrypt <results.json>...`, printing address<TAB>seed for every
+// result sealed with -kms-key after checking the seed derives its address; it returns the process exit code
+func runKMS(args []string) int {
+	flags := flag.NewFlagSet("kms", flag.ExitOnError)
+	auditPath := flags.String("audit-log", defaultAuditLog, "Audit log of a -compliance run every exported seed is recorded in, when it exists")
+	flags.Usage = func() {
+		_, _ = fmt.Fprintf(flags.Output(), "Usage: %s kms decrypt <results.json>...\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args) // ExitOnError handles the error
+	if flags.NArg() < 2 || flags.Arg(0) != "decrypt" {
+		flags.Usage()
+		return 2
+	}
+
+	cfg, err := loadAWSConfig()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	client := kms.NewFromConfig(cfg)
+	code := 0
+	for _, path := range flags.Args()[1:] {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "%v\n", err)
+			return 1
+		}
+		doc, err := decodeDocument(data)
+		zeroize(data)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "%s: failed to decode: %v\n", path, err)
+			return 1
+		}
+		for _, r := range doc.Results {
+			if r.EncryptedSeed == nil {
+				continue
+			}
+			seed, err := unsealSeed(client, r)
+			if err == nil {
+				err = verifySeed(path, r.Address, seed).Err
+			}
+			if err == nil {
+				err = auditExport(*auditPath, "kms decrypt "+path, r.Address)
+			}
+			if err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "%s %s: %v\n", path, r.Address, err)
+				code = 1
+				continue
+			}
+			fmt.Printf("%s\t%s\n", r.Address, seed)
+		}
+	}
+	return code
+}
+
+// secretsManagerBackend stores every seed as the secret <prefix>/<address> in AWS Secrets Manager
+type secretsManagerBackend struct {
+	client *secretsmanager.Client
+	prefix string // the secret name prefix from secretsmanager://<prefix>
+	kmsKey string // the -kms-key the secrets are encrypted with, the aws/secretsmanager key when empty
+}
+
+// openSecretsManager parses output as secretsmanager://<prefix>
+func openSecretsManager(output, kmsKey string) (*secretsManagerBackend, error) {
+	prefix := strings.Trim(strings.TrimPrefix(output, secretsManagerScheme), "/")
+	if prefix == "" {
+		return nil, fmt.Errorf("invalid -output %s: expected %s<prefix>", output, secretsManagerScheme)
+	}
+	cfg, err := loadAWSConfig()
+	if err != nil {
+		return nil, err
+	}
+	debugf("storing seeds in AWS Secrets Manager under %s/ in %s", prefix, cfg.Region)
+	return &secretsManagerBackend{client: secretsmanager.NewFromConfig(cfg), prefix: prefix, kmsKey: kmsKey}, nil
+}
+
+// Store creates the secret <prefix>/<address> holding the seed of r and leaves the secretsmanager:// reference instead
+func (s *secretsManagerBackend) Store(r *result) error {
+	name := s.prefix + "/" + r.Address
+	value, err := json.Marshal(map[string]any{"address": r.Address, "seed": r.Seed, "pattern": r.Pattern, "found_at": r.FoundAt})
+	if err != nil {
+		return err
+	}
+	defer zeroize(value)
+
+	input := &secretsmanager.CreateSecretInput{
+		Name:         aws.String(name),
+		Description:  aws.String("Stellar vanity address " + r.Address),
+		SecretString: aws.String(string(value)),
+		Tags:         []smtypes.Tag{{Key: aws.String("pattern"), Value: aws.String(r.Pattern)}},
+	}
+	if s.kmsKey != "" {
+		input.KmsKeyId = aws.String(s.kmsKey)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), awsTimeout)
+	defer cancel()
+	if _, err := s.client.CreateSecret(ctx, input); err != nil {
+		return fmt.Errorf("failed to create secret %s: %w", name, err)
+	}
+	r.SeedRef, r.Seed = secretsManagerScheme+name, ""
+	return nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"                                                           // used for uploading the appended lines
+	"context"                                                         // every Azure call runs with a timeout
+	"errors"                                                          // used for reporting a missing storage account
+	"fmt"                                                             // used for building the URL of the blob
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"                    // used for the ETag matching any blob
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/streaming"          // used for wrapping the appended lines
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"                // used for discovering the Azure credentials
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/appendblob" // the azblob:// -output
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"       // used for the shared key and the conditions
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"  // used for detecting a missing blob on first run
+	"io"                                                              // used for reading the blob
+	"os"                                                              // used for reading the storage account from the environment
+	"time"                                                            // used for the Azure call timeout
+)
+
+// azureScheme prefixes an -output (or -output-template) that appends every result to an Azure append blob, as
+// azblob://<container>/<blob> in the storage account of AZURE_STORAGE_ACCOUNT
+const azureScheme = "azblob://"
+
+// azureTimeout bounds every call to Azure
+const azureTimeout = 30 * time.Second
+
+// azureObject is the append blob of an azblob:// -output, the one kind of object that appends natively; every block is
+// appended at the size the blob had when it was last read or appended to, so two writers can't interleave
+type azureObject struct {
+	client *appendblob.Client
+	size   int64 // bytes of the blob, -1 until it exists
+}
+
+// openAzureObject returns the append blob name of container with the credentials found in the environment, in this
+// order: AZURE_STORAGE_CONNECTION_STRING (Azurite included), AZURE_STORAGE_ACCOUNT with AZURE_STORAGE_KEY, then
+// AZURE_STORAGE_ACCOUNT with the credentials of the az CLI, a service principal, a workload or a managed identity
+func openAzureObject(container, name string) (*azureObject, error) {
+	var client *appendblob.Client
+	var err error
+	account := os.Getenv("AZURE_STORAGE_ACCOUNT")
+	url := fmt.Sprintf("https://%s.blob.core.windows.net/%s/%s", account, container, name)
+	switch {
+	case os.Getenv("AZURE_STORAGE_CONNECTION_STRING") != "":
+		client, err = appendblob.NewClientFromConnectionString(os.Getenv("AZURE_STORAGE_CONNECTION_STRING"), container, name, nil)
+	case account == "":
+		return nil, errors.New("no Azure storage account, set AZURE_STORAGE_ACCOUNT or AZURE_STORAGE_CONNECTION_STRING")
+	case os.Getenv("AZURE_STORAGE_KEY") != "":
+		var key *blob.SharedKeyCredential
+		if key, err = blob.NewSharedKeyCredential(account, os.Getenv("AZURE_STORAGE_KEY")); err == nil {
+			client, err = appendblob.NewClientWithSharedKeyCredential(url, key, nil)
+		}
+	default:
+		credential, credentialErr := azidentity.NewDefaultAzureCredential(nil)
+		if credentialErr != nil {
+			return nil, fmt.Errorf("no Azure credentials: %w", credentialErr)
+		}
+		client, err = appendblob.NewClient(url, credential, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &azureObject{client: client, size: -1}, nil
+}
+
+// Read returns the blob, nothing when it doesn't exist yet, and remembers its size
+func (o *azureObject) Read() ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), azureTimeout)
+	defer cancel()
+	download, err := o.client.DownloadStream(ctx, nil)
+	if bloberror.HasCode(err, bloberror.BlobNotFound, bloberror.ContainerNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = download.Body.Close() }()
+	data, err := io.ReadAll(download.Body)
+	if err != nil {
+		zeroize(data)
+		return nil, err
+	}
+	o.size = int64(len(data))
+	return data, nil
+}
+
+// Append adds lines as a block at the end of the blob, creating it when it doesn't exist yet
+func (o *azureObject) Append(lines []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), azureTimeout)
+	defer cancel()
+	if o.size < 0 {
+		contentType, anyBlob := "application/x-ndjson", azcore.ETagAny
+		noBlob := blob.AccessConditions{ModifiedAccessConditions: &blob.ModifiedAccessConditions{IfNoneMatch: &anyBlob}}
+		_, err := o.client.Create(ctx, &appendblob.CreateOptions{HTTPHeaders: &blob.HTTPHeaders{BlobContentType: &contentType},
+			AccessConditions: &noBlob})
+		if err != nil && !bloberror.HasCode(err, bloberror.BlobAlreadyExists) { // created by an attempt that timed out
+			return err
+		}
+		o.size = 0
+	}
+	position := o.size
+	_, err := o.client.AppendBlock(ctx, streaming.NopCloser(bytes.NewReader(lines)), &appendblob.AppendBlockOptions{
+		AppendPositionAccessConditions: &appendblob.AppendPositionAccessConditions{AppendPosition: &position}})
+	if err != nil {
+		return err
+	}
+	o.size += int64(len(lines))
+	return nil
+}
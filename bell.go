@@ -0,0 +1,51 @@
+package main
+
+import (
+	"errors" // used for reporting a platform without a sound player
+	"fmt"    // used for describing a missing -bell-sound
+	"io"     // the bell goes to STDERR
+	"os"     // used for checking the -bell-sound exists
+)
+
+// errSoundUnsupported is returned by soundCommand when the platform has no sound player it knows of
+var errSoundUnsupported = errors.New("no sound player was found")
+
+// bell rings for every saved match with -bell: the terminal bell on STDERR, which stays a terminal when STDOUT is piped
+// to the next program, and with -bell-sound a sound file played by the player of the desktop
+type bell struct {
+	w     io.Writer // STDERR
+	sound string    // the -bell-sound, empty for the terminal bell alone
+}
+
+// newBell returns the bell of -bell, rejecting a -bell-sound that doesn't exist or that no player of the platform
+// could play
+func newBell(w io.Writer, sound string) (*bell, error) {
+	if sound != "" {
+		if _, err := os.Stat(sound); err != nil {
+			return nil, fmt.Errorf("invalid -bell-sound: %w", err)
+		}
+		if _, err := soundCommand(sound); err != nil {
+			return nil, fmt.Errorf("invalid -bell-sound %s: %w", sound, err)
+		}
+	}
+	return &bell{w: w, sound: sound}, nil
+}
+
+// Ring rings the terminal bell and starts playing the sound file, without waiting for it to end
+func (b *bell) Ring() error {
+	if _, err := io.WriteString(b.w, "\a"); err != nil {
+		return err
+	}
+	if b.sound == "" {
+		return nil
+	}
+	cmd, err := soundCommand(b.sound)
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to play %s: %w", b.sound, err)
+	}
+	go func() { _ = cmd.Wait() }() // reaped once the sound ends, the search goes on meanwhile
+	return nil
+}
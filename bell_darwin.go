@@ -0,0 +1,14 @@
+//go:build darwin
+
+package main
+
+import "os/exec" // used for running afplay
+
+// soundCommand returns afplay playing the sound file at path, which every macOS ships with
+func soundCommand(path string) (*exec.Cmd, error) {
+	player, err := exec.LookPath("afplay")
+	if err != nil {
+		return nil, err
+	}
+	return exec.Command(player, path), nil
+}
@@ -0,0 +1,23 @@
+//go:build linux
+
+package main
+
+import (
+	"fmt"     // used for listing the players looked for
+	"os/exec" // used for running paplay or aplay
+	"strings" // used for listing the players looked for
+)
+
+// soundPlayers are the players soundCommand looks for in order: paplay of PulseAudio, which PipeWire desktops provide
+// as well, then aplay of ALSA
+var soundPlayers = []string{"paplay", "aplay"}
+
+// soundCommand returns the first of soundPlayers installed playing the sound file at path
+func soundCommand(path string) (*exec.Cmd, error) {
+	for _, name := range soundPlayers {
+		if player, err := exec.LookPath(name); err == nil {
+			return exec.Command(player, path), nil
+		}
+	}
+	return nil, fmt.Errorf("%w, install one of %s", errSoundUnsupported, strings.Join(soundPlayers, " or "))
+}
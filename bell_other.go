@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !windows
+
+package main
+
+import "os/exec" // the command soundCommand would return
+
+// soundCommand knows of no sound player on this platform, -bell still rings the terminal bell
+func soundCommand(string) (*exec.Cmd, error) {
+	return nil, errSoundUnsupported
+}
@@ -0,0 +1,23 @@
+//go:build windows
+
+package main
+
+import (
+	"os"      // used for passing the path through the environment
+	"os/exec" // used for running PowerShell
+)
+
+// playSound is the PowerShell playing the WAV file of XLM_VANITY_SOUND, which takes the path as it is rather than
+// quoted into the script
+const playSound = "(New-Object Media.SoundPlayer $env:XLM_VANITY_SOUND).PlaySync()"
+
+// soundCommand returns PowerShell playing the WAV file at path with the SoundPlayer of .NET
+func soundCommand(path string) (*exec.Cmd, error) {
+	player, err := exec.LookPath("powershell.exe")
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command(player, "-NoProfile", "-NonInteractive", "-Command", playSound)
+	cmd.Env = append(os.Environ(), "XLM_VANITY_SOUND="+path)
+	return cmd, nil
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"encoding/json"                                              // the measurements are saved as JSON
+	"errors"                                                     // used for reporting too few saved runs to compare
+	"flag"                                                       // bench compare parses its own arguments
+	"fmt"                                                        // used for printing the comparison
+	"github.com/andreimerlescu/xlm-vanity-address-finder/vanity" // the encoder of the addresses measured
+	"os"                                                         // access the filesystem
+	"path/filepath"                                              // used for locating the saved runs
+	"runtime"                                                    // used for describing the machine of a run
+	"runtime/debug"                                              // used for the version of the binary
+	"sort"                                                       // used for finding the latest runs and listing the core counts in order
+	"strconv"                                                    // used for parsing -cores
+	"strings"                                                    // used for splitting -cores and joining patterns
+	"text/tabwriter"                                             // used for aligning the comparison
+	"time"                                                       // used for naming and dating the runs
+)
+
+// benchRun is everything a bench measured, saved to the bench directory or -save so bench compare can diff two runs
+type benchRun struct {
+	StartedAt    time.Time     `json:"started_at"`
+	Hostname     string        `json:"hostname"`
+	Version      string        `json:"version"`    // the module version of the binary, (devel) when built from a checkout
+	GoVersion    string        `json:"go_version"` // the Go release it was built with
+	Encoder      string        `json:"encoder"`    // the instructions the addresses were encoded with, avx2, neon or generic
+	OS           string        `json:"os"`
+	Arch         string        `json:"arch"`
+	CPUs         int           `json:"cpus"` // the CPUs of the machine
+	Args         []string      `json:"args"` // the arguments of bench, what a flag change changed
+	Measurements []benchReport `json:"measurements"`
+}
+
+// benchDelta is a line of bench compare, the measurements of both runs with as many cores
+type benchDelta struct {
+	Cores  int     `json:"cores"`
+	Before float64 `json:"before"` // addresses per second of the first run, 0 when it didn't measure as many cores
+	After  float64 `json:"after"`  // addresses per second of the second run, 0 when it didn't measure as many cores
+	Delta  float64 `json:"delta"`  // After relative to Before, 0.1 for 10% faster, 0 unless both measured
+}
+
+// defaultBenchDir is where bench saves its runs when -save isn't set, next to the other per-user state
+func defaultBenchDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "bench"
+	}
+	return filepath.Join(dir, keyringService, "bench")
+}
+
+// parseCores parses the -cores of bench, a count or several separated by commas
+func parseCores(value string) ([]int, error) {
+	var counts []int
+	for _, field := range strings.Split(value, ",") {
+		cores, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || cores < 1 {
+			return nil, fmt.Errorf("-cores %s: expected counts of at least 1, separated by commas", value)
+		}
+		counts = append(counts, cores)
+	}
+	return counts, nil
+}
+
+// newBenchRun returns the run of bench with args, started now on this machine
+func newBenchRun(args []string) benchRun {
+	run := benchRun{StartedAt: time.Now().UTC().Truncate(time.Second), Version: "(devel)", GoVersion: runtime.Version(),
+		OS: runtime.GOOS, Arch: runtime.GOARCH, CPUs: runtime.NumCPU(), Encoder: vanity.Encoder(), Args: append([]string{}, args...)}
+	run.Hostname, _ = os.Hostname()
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		run.Version = info.Main.Version
+	}
+	return run
+}
+
+// saveBenchRun writes run to path, or to a new file in the bench directory when path is empty, and returns where
+func saveBenchRun(run benchRun, path string) (string, error) {
+	if path == "" {
+		dir := defaultBenchDir()
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return "", err
+		}
+		path = filepath.Join(dir, "bench-"+run.StartedAt.Format("20060102T150405Z")+".json")
+	}
+	data, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return path, os.WriteFile(path, append(data, '\n'), 0600)
+}
+
+// loadBenchRun reads a run saved by bench
+func loadBenchRun(path string) (benchRun, error) {
+	var run benchRun
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return run, err
+	}
+	if err := json.Unmarshal(data, &run); err != nil {
+		return run, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(run.Measurements) == 0 {
+		return run, fmt.Errorf("%s: expected a run saved by bench, found no measurements", path)
+	}
+	return run, nil
+}
+
+// latestBenchRuns returns the two runs saved last in the bench directory, the older first
+func latestBenchRuns() (string, string, error) {
+	dir := defaultBenchDir()
+	paths, err := filepath.Glob(filepath.Join(dir, "bench-*.json"))
+	if err != nil {
+		return "", "", err
+	}
+	if len(paths) < 2 {
+		return "", "", errors.New("bench compare needs two runs, " + dir + " has " + strconv.Itoa(len(paths)) +
+			": run bench before and after the change, or name two files")
+	}
+	sort.Strings(paths) // named after when they started
+	return paths[len(paths)-2], paths[len(paths)-1], nil
+}
+
+// compareBenchRuns returns the rate of before and after for every core count either measured, the latest measurement
+// of a count winning when a run measured it more than once
+func compareBenchRuns(before, after benchRun) []benchDelta {
+	rates := func(run benchRun) map[int]float64 {
+		byCores := make(map[int]float64)
+		for _, measurement := range run.Measurements {
+			byCores[measurement.Cores] = measurement.Rate
+		}
+		return byCores
+	}
+	from, to := rates(before), rates(after)
+	var deltas []benchDelta
+	for cores := range from {
+		deltas = append(deltas, benchDelta{Cores: cores})
+	}
+	for cores := range to {
+		if _, ok := from[cores]; !ok {
+			deltas = append(deltas, benchDelta{Cores: cores})
+		}
+	}
+	sort.Slice(deltas, func(i, j int) bool { return deltas[i].Cores < deltas[j].Cores })
+	for i := range deltas {
+		deltas[i].Before, deltas[i].After = from[deltas[i].Cores], to[deltas[i].Cores]
+		if deltas[i].Before > 0 && deltas[i].After > 0 {
+			deltas[i].Delta = deltas[i].After/deltas[i].Before - 1
+		}
+	}
+	return deltas
+}
+
+// describeBenchRun is how bench compare names a run
+func describeBenchRun(path string, run benchRun) string {
+	return fmt.Sprintf("%s (%s, %s %s/%s %s, %s, %s)", path, run.Hostname, run.GoVersion, run.OS, run.Arch, run.Encoder,
+		run.StartedAt.Local().Format(time.DateTime), strings.Join(run.Args, " "))
+}
+
+// runBenchCompare implements `xlm-vanity-address-finder bench compare`, printing how the addresses per second of every
+// core count changed from one saved run to another; it returns the process exit code
+func runBenchCompare(args []string) int {
+	flags := flag.NewFlagSet("bench compare", flag.ExitOnError)
+	flags.Usage = func() {
+		_, _ = fmt.Fprintf(flags.Output(), "Usage: %s bench compare [-json] [before.json after.json]\n\n"+
+			"Without files, compares the two runs saved last in %s.\n", os.Args[0], defaultBenchDir())
+		flags.PrintDefaults()
+	}
+	asJSON := flags.Bool("json", jsonOutput, "Print a single JSON document instead of the comparison")
+	_ = flags.Parse(args) // ExitOnError handles the error
+	if flags.NArg() != 0 && flags.NArg() != 2 {
+		flags.Usage()
+		return 2
+	}
+
+	beforePath, afterPath := flags.Arg(0), flags.Arg(1)
+	if flags.NArg() == 0 {
+		var err error
+		if beforePath, afterPath, err = latestBenchRuns(); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "%v\n", err)
+			return 1
+		}
+	}
+	before, err := loadBenchRun(beforePath)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	after, err := loadBenchRun(afterPath)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	deltas := compareBenchRuns(before, after)
+	if *asJSON {
+		if err := printJSON(deltas); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "%v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	fmt.Printf("before: %s\nafter:  %s\n\n", describeBenchRun(beforePath, before), describeBenchRun(afterPath, after))
+	rate := func(rate float64) string {
+		if rate == 0 {
+			return "-"
+		}
+		return FormatInt64(int64(rate))
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	_, _ = fmt.Fprintln(w, "CORES\tBEFORE/S\tAFTER/S\tDELTA\tPER CORE BEFORE\tPER CORE AFTER\t")
+	for _, delta := range deltas {
+		change := "-"
+		if delta.Before > 0 && delta.After > 0 {
+			change = fmt.Sprintf("%+.1f%%", delta.Delta*100)
+		}
+		_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t\n", delta.Cores, rate(delta.Before), rate(delta.After), change,
+			rate(delta.Before/float64(delta.Cores)), rate(delta.After/float64(delta.Cores)))
+	}
+	_ = w.Flush()
+	if strings.Join(before.Measurements[0].Patterns, ",") != strings.Join(after.Measurements[0].Patterns, ",") {
+		fmt.Println("\nThe runs matched different patterns, so the rates also differ by the cost of matching them.")
+	}
+	return 0
+}
@@ -0,0 +1,71 @@
+//go:build linux
+
+package main
+
+import (
+	"os"            // access the filesystem
+	"path/filepath" // used for walking up the cgroup hierarchy
+	"slices"        // used for finding the cpu controller of cgroup v1
+	"strconv"       // used for parsing the quotas
+	"strings"       // used for splitting /proc/self/cgroup
+)
+
+// cgroupRoots are where cgroup v2 is mounted, alone or next to cgroup v1 on hybrid systems
+var cgroupRoots = []string{"/sys/fs/cgroup", "/sys/fs/cgroup/unified"}
+
+// cgroupV1CPU is where the cpu controller of cgroup v1 is mounted
+const cgroupV1CPU = "/sys/fs/cgroup/cpu"
+
+// cgroupQuota returns the CPUs the cgroups of the process may use, the lowest quota of its cgroup and every parent, as
+// Kubernetes sets from the CPU limit of a container; 0 when there is no quota
+func cgroupQuota() float64 {
+	data, err := os.ReadFile("/proc/self/cgroup")
+	if err != nil {
+		return 0
+	}
+	quota := 0.0
+	lower := func(cpus float64) {
+		if cpus > 0 && (quota == 0 || cpus < quota) {
+			quota = cpus
+		}
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		fields := strings.SplitN(line, ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		if fields[0] == "0" && fields[1] == "" { // cgroup v2, cpu.max holds "<quota> <period>" or "max <period>"
+			for _, root := range cgroupRoots {
+				for dir := filepath.Join(root, fields[2]); strings.HasPrefix(dir, root); dir = filepath.Dir(dir) {
+					if limit, period, ok := strings.Cut(readCgroup(filepath.Join(dir, "cpu.max")), " "); ok {
+						lower(cgroupCPUs(limit, period))
+					}
+					if dir == root {
+						break
+					}
+				}
+			}
+		} else if slices.Contains(strings.Split(fields[1], ","), "cpu") { // cgroup v1, -1 is no quota
+			for _, dir := range []string{filepath.Join(cgroupV1CPU, fields[2]), cgroupV1CPU} { // the host's path, or the container's
+				lower(cgroupCPUs(readCgroup(filepath.Join(dir, "cpu.cfs_quota_us")), readCgroup(filepath.Join(dir, "cpu.cfs_period_us"))))
+			}
+		}
+	}
+	return quota
+}
+
+// readCgroup returns the trimmed content of a cgroup file, nothing when it can't be read
+func readCgroup(path string) string {
+	data, _ := os.ReadFile(path)
+	return strings.TrimSpace(string(data))
+}
+
+// cgroupCPUs returns the CPUs of a quota of microseconds per period, 0 when either isn't a positive number
+func cgroupCPUs(quota, period string) float64 {
+	q, qErr := strconv.ParseFloat(quota, 64)
+	p, pErr := strconv.ParseFloat(period, 64)
+	if qErr != nil || pErr != nil || q <= 0 || p <= 0 {
+		return 0
+	}
+	return q / p
+}
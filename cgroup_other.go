@@ -0,0 +1,8 @@
+//go:build !linux
+
+package main
+
+// cgroupQuota returns 0, there are no cgroups on this platform
+func cgroupQuota() float64 {
+	return 0
+}
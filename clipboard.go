@@ -0,0 +1,45 @@
+package main
+
+import (
+	"errors"  // used for reporting a platform without a clipboard command
+	"fmt"     // used for describing a failed copy
+	"strings" // the text is copied through the STDIN of the clipboard command
+)
+
+// errClipboardUnsupported is returned by clipboardCommand when the platform has no clipboard command it knows of
+var errClipboardUnsupported = errors.New("no clipboard command was found")
+
+// clipboard copies every saved match to the system clipboard with -clipboard, so the most recent one is there to be
+// pasted: its address, or its seed with -clipboard-seed
+type clipboard struct {
+	seed bool // -clipboard-seed, the seed is copied instead of the address
+}
+
+// newClipboard returns the clipboard of -clipboard, rejecting a platform without a clipboard command before the search
+// starts rather than at the first match
+func newClipboard(seed bool) (*clipboard, error) {
+	if _, err := clipboardCommand(); err != nil {
+		return nil, err
+	}
+	return &clipboard{seed: seed}, nil
+}
+
+// Copy copies the address of r to the clipboard, or its seed with -clipboard-seed
+func (c *clipboard) Copy(r result) error {
+	text := r.Address
+	if c.seed {
+		text = r.Seed
+	}
+	if text == "" {
+		return fmt.Errorf("%s has no seed to copy", r.Address)
+	}
+	cmd, err := clipboardCommand()
+	if err != nil {
+		return err
+	}
+	cmd.Stdin = strings.NewReader(text) // no STDOUT or STDERR, xclip and wl-copy leave a child serving the clipboard holding on to them
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w", cmd.Path, err)
+	}
+	return nil
+}
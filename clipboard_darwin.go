@@ -0,0 +1,14 @@
+//go:build darwin
+
+package main
+
+import "os/exec" // used for running pbcopy
+
+// clipboardCommand returns pbcopy, which every macOS ships with
+func clipboardCommand() (*exec.Cmd, error) {
+	path, err := exec.LookPath("pbcopy")
+	if err != nil {
+		return nil, err
+	}
+	return exec.Command(path), nil
+}
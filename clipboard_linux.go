@@ -0,0 +1,33 @@
+//go:build linux
+
+package main
+
+import (
+	"fmt"     // used for listing the commands looked for
+	"os"      // used for telling Wayland and X11 sessions apart
+	"os/exec" // used for running wl-copy, xclip or xsel
+)
+
+// clipboardCommands are the commands clipboardCommand looks for in order, each along with its arguments and the
+// variable its display server is found by: wl-copy of Wayland first, then xclip and xsel of X11, which XWayland serves
+var clipboardCommands = []struct {
+	display string
+	args    []string
+}{
+	{"WAYLAND_DISPLAY", []string{"wl-copy"}},
+	{"DISPLAY", []string{"xclip", "-selection", "clipboard"}},
+	{"DISPLAY", []string{"xsel", "--clipboard", "--input"}},
+}
+
+// clipboardCommand returns the first of clipboardCommands installed whose display server is running
+func clipboardCommand() (*exec.Cmd, error) {
+	for _, command := range clipboardCommands {
+		if os.Getenv(command.display) == "" {
+			continue
+		}
+		if path, err := exec.LookPath(command.args[0]); err == nil {
+			return exec.Command(path, command.args[1:]...), nil
+		}
+	}
+	return nil, fmt.Errorf("%w, it takes wl-copy, xclip or xsel in a desktop session", errClipboardUnsupported)
+}
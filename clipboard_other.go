@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !windows
+
+package main
+
+import "os/exec" // the command clipboardCommand would return
+
+// clipboardCommand knows of no clipboard command on this platform
+func clipboardCommand() (*exec.Cmd, error) {
+	return nil, errClipboardUnsupported
+}
@@ -0,0 +1,15 @@
+//go:build windows
+
+package main
+
+import "os/exec" // used for running clip.exe
+
+// clipboardCommand returns clip.exe, which every Windows ships with; addresses and seeds are ASCII, so the code page it
+// reads its STDIN in makes no difference
+func clipboardCommand() (*exec.Cmd, error) {
+	path, err := exec.LookPath("clip.exe")
+	if err != nil {
+		return nil, err
+	}
+	return exec.Command(path), nil
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"encoding/json"             // the instances exchange their state over HTTP as JSON
+	"fmt"                       // used for naming the instances and reporting failed requests
+	"github.com/hashicorp/mdns" // the instances of -cluster lan find each other with mDNS
+	"log"                       // used for reporting who joined, left and coordinates
+	"net"                       // used for the port of the -listen server and the addresses of the peers
+	"net/http"                  // used for asking the coordinator and the peers for their state
+	"slices"                    // used for comparing the assigned patterns
+	"sort"                      // used for electing the coordinator
+	"strings"                   // used for parsing the TXT records and naming the instances
+	"sync"                      // used for guarding the peers the /cluster handler reads
+	"time"                      // used for the rounds and the start of every instance
+)
+
+// clusterLAN is the -cluster value that finds the other instances on the local network with mDNS
+const clusterLAN = "lan"
+
+// clusterService is the mDNS service every -cluster lan instance advertises its -listen server under
+const clusterService = "_xlm-vanity._tcp"
+
+// clusterEvery is how often the instances of a -cluster look for each other and the coordinator merges their stats
+const clusterEvery = 10 * time.Second
+
+// clusterMissed is how many rounds in a row a peer may go unseen before its patterns go to the others
+const clusterMissed = 3
+
+// clusterPeer is an instance of a -cluster
+type clusterPeer struct {
+	ID       string    `json:"id"`                 // hostname-pid, unique on the network
+	URL      string    `json:"url"`                // where its -listen server answers
+	Started  time.Time `json:"started"`            // when it joined, the earliest one coordinates
+	Patterns []string  `json:"patterns,omitempty"` // what the coordinator assigned it
+	Attempts int64     `json:"attempts"`           // addresses it scanned, as of the last round
+	Rate     float64   `json:"rate"`               // addresses per second it scanned during the last round
+	Matches  int       `json:"matches"`            // matches it saved
+	missed   int       // rounds in a row it went unseen
+}
+
+// clusterState is what GET /cluster answers: who coordinates, the patterns of the cluster and how they are split, and
+// on the coordinator the merged stats of every instance
+type clusterState struct {
+	Self        string        `json:"self"`        // the instance answering
+	Coordinator string        `json:"coordinator"` // the instance splitting the patterns and merging the stats
+	Patterns    []string      `json:"patterns"`    // the -find patterns of the coordinator, split among Peers
+	Peers       []clusterPeer `json:"peers"`       // every instance seen, the coordinator first
+	Attempts    int64         `json:"attempts"`    // addresses scanned by all of Peers
+	Rate        float64       `json:"rate"`        // addresses per second of all of Peers
+	Matches     int           `json:"matches"`     // matches saved by all of Peers
+}
+
+// cluster is the membership of this instance in -cluster lan: every round it looks for the others with mDNS and
+// elects the one that started first as the coordinator, which splits its -find patterns among all of them and merges
+// their GET /status; the others take their share from the GET /cluster of the coordinator
+type cluster struct {
+	self     clusterPeer
+	patterns []string                // the -find patterns of this instance, split among the peers while it coordinates
+	server   *mdns.Server            // answers the mDNS queries of the others
+	client   *http.Client            // asks the coordinator and the peers for their state
+	assigned chan []string           // the share of the patterns for this instance, received by runFind
+	share    []string                // the share last sent on assigned
+	done     chan struct{}           // closed by Close, ends the rounds
+	mu       sync.Mutex              // guards peers and state, the /cluster handler reads them
+	peers    map[string]*clusterPeer // by ID, this instance included
+	state    clusterState            // as of the last round
+	colors   palette
+}
+
+// mdnsLogger sends what the mDNS package logs about every query to -log-level trace, it is chatty
+var mdnsLogger = log.New(traceWriter{}, "", 0)
+
+// traceWriter writes every line through tracef
+type traceWriter struct{}
+
+// Write logs p with tracef
+func (traceWriter) Write(p []byte) (int, error) {
+	tracef("%s", strings.TrimSpace(string(p)))
+	return len(p), nil
+}
+
+// joinCluster advertises the -listen server at listen on the local network and starts looking for the other
+// instances every clusterEvery, searching for patterns until the coordinator assigns a share of its own
+func joinCluster(listen net.Addr, hostname string, pid int, patterns []string, colors palette) (*cluster, error) {
+	tcp, ok := listen.(*net.TCPAddr)
+	if !ok {
+		return nil, fmt.Errorf("can't advertise %s", listen)
+	}
+	id := fmt.Sprintf("%s-%d", strings.ReplaceAll(hostname, ".", "-"), pid) // a dot would split the mDNS instance name
+	self := clusterPeer{ID: id, URL: fmt.Sprintf("http://127.0.0.1:%d", tcp.Port), Started: time.Now().UTC()}
+	txt := []string{"id=" + id, "started=" + self.Started.Format(time.RFC3339Nano)}
+	service, err := mdns.NewMDNSService(id, clusterService, "", "", tcp.Port, localIPs(), txt)
+	if err != nil {
+		return nil, err
+	}
+	server, err := mdns.NewServer(&mdns.Config{Zone: service, Logger: mdnsLogger})
+	if err != nil {
+		return nil, err
+	}
+	c := &cluster{self: self, patterns: patterns, server: server, client: &http.Client{Timeout: 5 * time.Second},
+		assigned: make(chan []string, 1), share: patterns, done: make(chan struct{}), peers: map[string]*clusterPeer{id: &self}, colors: colors}
+	c.state = clusterState{Self: id, Coordinator: id, Patterns: patterns, Peers: []clusterPeer{self}}
+	go c.run()
+	return c, nil
+}
+
+// run plays a round right away and then every clusterEvery until Close
+func (c *cluster) run() {
+	ticker := time.NewTicker(clusterEvery)
+	defer ticker.Stop()
+	for {
+		c.round()
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// round looks for the other instances, elects the coordinator and takes the share of the patterns it assigns
+func (c *cluster) round() {
+	seen := c.discover()
+	c.mu.Lock()
+	for id, peer := range seen {
+		if known, ok := c.peers[id]; ok {
+			known.URL, known.missed = peer.URL, 0
+		} else {
+			c.peers[id] = peer
+			log.Printf("Cluster: %s joined from %s", id, peer.URL)
+		}
+	}
+	for id, peer := range c.peers {
+		if _, ok := seen[id]; !ok && id != c.self.ID {
+			if peer.missed++; peer.missed > clusterMissed {
+				delete(c.peers, id)
+				log.Printf("Cluster: %s left", id)
+			}
+		}
+	}
+	peers := make([]clusterPeer, 0, len(c.peers))
+	for _, peer := range c.peers {
+		peers = append(peers, *peer)
+	}
+	previous := c.state.Coordinator
+	c.mu.Unlock()
+
+	sort.Slice(peers, func(i, j int) bool { // the instance that started first coordinates, the ID breaks ties
+		if !peers[i].Started.Equal(peers[j].Started) {
+			return peers[i].Started.Before(peers[j].Started)
+		}
+		return peers[i].ID < peers[j].ID
+	})
+	state := clusterState{Self: c.self.ID, Coordinator: peers[0].ID}
+	if state.Coordinator == c.self.ID {
+		state.Patterns = c.patterns
+		for i, share := range splitPatterns(c.patterns, len(peers)) {
+			peers[i].Patterns = share
+			if snapshot, err := c.status(peers[i].URL); err != nil {
+				debugf("cluster: no /status of %s: %v", peers[i].ID, err)
+			} else {
+				peers[i].Attempts, peers[i].Rate, peers[i].Matches = snapshot.Attempts, snapshot.Rate, snapshot.Matches
+			}
+			state.Attempts += peers[i].Attempts
+			state.Rate += peers[i].Rate
+			state.Matches += peers[i].Matches
+		}
+		state.Peers = peers
+	} else if err := c.get(peers[0].URL+"/cluster", &state); err != nil {
+		c.colors.warnf("cluster: can't reach the coordinator %s: %v", peers[0].ID, err)
+		return
+	}
+	state.Self = c.self.ID
+	if state.Coordinator != previous {
+		log.Printf("Cluster: %s coordinates", state.Coordinator)
+	}
+	c.mu.Lock()
+	c.state = state
+	c.mu.Unlock()
+
+	for _, peer := range state.Peers {
+		if peer.ID == c.self.ID && len(peer.Patterns) > 0 && !slices.Equal(peer.Patterns, c.share) {
+			c.share = peer.Patterns
+			select { // only the latest share matters, a share runFind hasn't taken yet is replaced
+			case <-c.assigned:
+			default:
+			}
+			c.assigned <- peer.Patterns
+		}
+	}
+}
+
+// discover asks the local network for the other instances, by ID
+func (c *cluster) discover() map[string]*clusterPeer {
+	entries := make(chan *mdns.ServiceEntry, 64)
+	params := mdns.DefaultParams(clusterService)
+	params.Entries, params.Timeout, params.DisableIPv6, params.Logger = entries, 2*time.Second, true, mdnsLogger
+	if err := mdns.Query(params); err != nil {
+		debugf("cluster: mDNS query failed: %v", err)
+	}
+	close(entries)
+	seen := make(map[string]*clusterPeer)
+	for entry := range entries {
+		peer := &clusterPeer{}
+		for _, field := range entry.InfoFields {
+			key, value, _ := strings.Cut(field, "=")
+			switch key {
+			case "id":
+				peer.ID = value
+			case "started":
+				peer.Started, _ = time.Parse(time.RFC3339Nano, value)
+			}
+		}
+		if peer.ID == "" || peer.ID == c.self.ID || entry.AddrV4 == nil {
+			continue // not an instance of this program, or this one
+		}
+		peer.URL = fmt.Sprintf("http://%s", net.JoinHostPort(entry.AddrV4.String(), fmt.Sprint(entry.Port)))
+		seen[peer.ID] = peer
+	}
+	return seen
+}
+
+// status returns the GET /status of the instance at url
+func (c *cluster) status(url string) (statsSnapshot, error) {
+	var snapshot statsSnapshot
+	err := c.get(url+"/status", &snapshot)
+	return snapshot, err
+}
+
+// get decodes the JSON answer of a GET of url into v
+func (c *cluster) get(url string, v any) error {
+	response, err := c.client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = response.Body.Close() }()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, response.Status)
+	}
+	return json.NewDecoder(response.Body).Decode(v)
+}
+
+// Assigned receives the share of the patterns the coordinator assigned this instance, whenever a round changes it
+func (c *cluster) Assigned() <-chan []string {
+	return c.assigned
+}
+
+// State returns the cluster as of the last round
+func (c *cluster) State() clusterState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state
+}
+
+// ServeHTTP answers GET /cluster with the State
+func (c *cluster) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(c.State())
+}
+
+// Close stops looking for the others and answering their mDNS queries
+func (c *cluster) Close() error {
+	close(c.done)
+	return c.server.Shutdown()
+}
+
+// localIPs returns the IPv4 addresses of the network interfaces that are up, the loopback one only when there is no
+// other, rather than trusting the hostname to resolve to them
+func localIPs() []net.IP {
+	var ips, loopback []net.IP
+	addrs, _ := net.InterfaceAddrs() // none found falls back to the loopback address below
+	for _, addr := range addrs {
+		if prefix, ok := addr.(*net.IPNet); ok && prefix.IP.To4() != nil {
+			if prefix.IP.IsLoopback() {
+				loopback = append(loopback, prefix.IP)
+			} else {
+				ips = append(ips, prefix.IP)
+			}
+		}
+	}
+	if len(ips) == 0 && len(loopback) == 0 {
+		return []net.IP{net.IPv4(127, 0, 0, 1)}
+	}
+	if len(ips) == 0 {
+		return loopback
+	}
+	return ips
+}
+
+// splitPatterns deals patterns out to n instances like cards; with fewer patterns than instances the ones left over
+// search a pattern another instance searches too, which doesn't waste anything since every key pair is random
+func splitPatterns(patterns []string, n int) [][]string {
+	shares := make([][]string, n)
+	for i, pattern := range patterns {
+		shares[i%n] = append(shares[i%n], pattern)
+	}
+	for i := range shares {
+		if len(shares[i]) == 0 && len(patterns) > 0 {
+			shares[i] = []string{patterns[i%len(patterns)]}
+		}
+	}
+	return shares
+}
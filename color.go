@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"               // used for formatting the colored messages
+	"golang.org/x/term" // used for detecting whether STDOUT is a terminal
+	"log"               // colored warnings and errors still go through the timestamped logger
+	"os"                // used for reading NO_COLOR and TERM
+	"strings"           // used for locating the matched pattern inside an address
+)
+
+// ANSI escape sequences used by palette
+const (
+	ansiReset  = "\033[0m"
+	ansiBold   = "\033[1m"
+	ansiRed    = "\033[31m"
+	ansiGreen  = "\033[32m"
+	ansiYellow = "\033[33m"
+)
+
+// palette colors console output when -color is set, STDOUT is a terminal and NO_COLOR (https://no-color.org) is unset;
+// when disabled every method returns its input unchanged
+type palette struct {
+	enabled bool
+}
+
+// newPalette decides whether color is used for this run
+func newPalette(requested bool) palette {
+	if !requested || os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return palette{}
+	}
+	return palette{enabled: term.IsTerminal(int(os.Stdout.Fd()))}
+}
+
+// paint wraps s in the given escape sequence
+func (c palette) paint(code, s string) string {
+	if !c.enabled || s == "" {
+		return s
+	}
+	return code + s + ansiReset
+}
+
+// Highlight returns address with the first occurrence of pattern in bold green, so it is easy to spot among 56 chars
+func (c palette) Highlight(address, pattern string) string {
+	i := strings.Index(address, pattern)
+	if !c.enabled || pattern == "" || i < 0 {
+		return address
+	}
+	return address[:i] + c.paint(ansiBold+ansiGreen, pattern) + address[i+len(pattern):]
+}
+
+// warnf logs a warning in yellow, unless -log-level error is set
+func (c palette) warnf(format string, args ...any) {
+	if currentLevel < levelWarn {
+		return
+	}
+	log.Print(c.paint(ansiYellow, fmt.Sprintf(format, args...)))
+}
+
+// fatalf logs an error in red and exits with exitError
+func (c palette) fatalf(format string, args ...any) {
+	c.failf(exitError, format, args...)
+}
+
+// failf logs an error in red and exits with code
+func (c palette) failf(code int, format string, args ...any) {
+	log.Print(c.paint(ansiRed, fmt.Sprintf(format, args...)))
+	os.Exit(code)
+}
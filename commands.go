@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"                                                    // used for ending the bench after -seconds
+	"flag"                                                       // the bench subcommand parses its own arguments
+	"fmt"                                                        // used for printing the usage and the bench report
+	"github.com/andreimerlescu/xlm-vanity-address-finder/vanity" // the keygen being measured
+	"os"                                                         // access the filesystem
+	"runtime"                                                    // used for determining number of default cores to use
+	"strconv"                                                    // used for the default -cores of bench
+	"sync"                                                       // used for waiting on the bench workers
+	"sync/atomic"                                                // used for counting the matches of the bench workers
+	"time"                                                       // used for the duration of the bench and the time to a match
+)
+
+// the commands sharing the flags of the search, a bare `xlm-vanity-address-finder -find X` runs commandFind
+const (
+	commandFind     = "find"     // search for the patterns until -stop
+	commandEstimate = "estimate" // report how hard the patterns are without searching, like find -dry-run
+	commandServe    = "serve"    // search like find while serving the progress over HTTP on -listen
+)
+
+// defaultListen is the -listen of serve, the local machine only
+const defaultListen = "127.0.0.1:8080"
+
+// commandUsage is the top of -help, the flags of find, estimate and serve are printed below it
+const commandUsage = `Usage: %[1]s [--json] [command] [flags] [pattern...]
+
+Commands:
+  find           search for addresses containing the patterns, the default: %[1]s -find CAT
+  estimate       report whether the patterns can match and how hard they are, then exit
+  serve          search like find and serve the progress on -listen (default %[2]s)
+  bench          measure how many addresses per second this machine checks, bench compare diffs two runs
+  selftest       check the keygen, the matcher and the results files against known answers
+  rngcheck       test whether the keys of this machine are as random as they should be
+  verify         re-derive the address of every seed and report mismatches
+  results        check a results file and repair it with -fix
+  runs           list the runs recorded in -runs-db
+  config         write a commented config file with config init, or check one with config validate
+  keys           list and show the seeds stored with -store keyring
+  shamir         combine -shamir-shares files back into a seed
+  split-key      make the partial key of a -split-key search, combine its tweak and sign with the result
+  pool           serve split-key orders to -pool workers, a self-hosted vanity marketplace
+  kms            decrypt the seeds sealed with -kms-key
+  export         back up the seeds of results files into password-encrypted keystores, and open them again
+  service        install the search as a Windows service
+  stop, status   stop or check on a -daemon search
+
+Patterns may follow the flags instead of -find: %[1]s find -cores 4 CAT DOG
+
+Global flags:
+  --json         print one JSON document on STDOUT instead of the text of the command
+
+Flags of find, estimate and serve:
+`
+
+// searchCommand takes find, estimate or serve off the front of args, which is find when none of them is there
+func searchCommand(args []string) (string, []string) {
+	if len(args) > 0 && (args[0] == commandFind || args[0] == commandEstimate || args[0] == commandServe) {
+		return args[0], args[1:]
+	}
+	return commandFind, args
+}
+
+// printUsage is the -help of find, estimate and serve, it lists every command ahead of their flags
+func printUsage() {
+	_, _ = fmt.Fprintf(flag.CommandLine.Output(), commandUsage, os.Args[0], defaultListen)
+	flag.PrintDefaults()
+}
+
+// benchReport is what bench measured, also its --json document
+type benchReport struct {
+	Cores       int       `json:"cores"`              // the go-routines generating addresses
+	Seconds     float64   `json:"seconds"`            // how long they ran
+	Attempts    int64     `json:"attempts"`           // addresses generated and matched
+	Rate        float64   `json:"rate"`               // addresses per second of all cores
+	RatePerCore float64   `json:"rate_per_core"`      // addresses per second of a single core
+	Patterns    []string  `json:"patterns,omitempty"` // the -find patterns matched against, none measures the keygen alone
+	Matches     int64     `json:"matches"`            // addresses that matched -find
+	Estimate    *estimate `json:"estimate,omitempty"` // how hard -find is to match, without -find none
+}
+
+// runBench implements `xlm-vanity-address-finder bench`, generating and matching addresses on -cores for -seconds to
+// report the addresses per second of this machine, and with -find, -prefix or -suffix how long matching takes; the
+// measurements are saved for bench compare; it returns the process exit code
+func runBench(args []string) int {
+	if len(args) > 0 && args[0] == "compare" {
+		return runBenchCompare(args[1:])
+	}
+	flags := flag.NewFlagSet("bench", flag.ExitOnError)
+	flags.Usage = func() {
+		_, _ = fmt.Fprintf(flags.Output(), "Usage: %s bench [-seconds 10] [-cores n[,n...]] [-find patterns] [-find-seed patterns] [-position where] [-prefix G...] [-suffix ...] [-lowercase] [-fast-crypto] [-save path | -no-save] [-json]\n       %[1]s bench compare [before.json after.json]\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	seconds := flags.Int("seconds", 10, "Seconds to measure for")
+	coresList := flags.String("cores", strconv.Itoa(runtime.GOMAXPROCS(0)), "Processors to measure with, several separated by commas are measured one after the other")
+	find := flags.String("find", "", "Patterns to match every address against like a search does, separate several with commas")
+	findSeed := flags.String("find-seed", "", "Patterns to match every seed against like -find-seed does, on top of -find when both are set")
+	lowercase := flags.Bool("lowercase", false, "Match -find like -lowercase does")
+	position := flags.String("position", positionAnywhere, "Where -find must appear: anywhere, start or end")
+	prefix := flags.String("prefix", "", "What every match must start with, G included")
+	suffix := flags.String("suffix", "", "What every match must end with")
+	fastCrypto := flags.Bool("fast-crypto", false, "Derive the public keys in batches like -fast-crypto does")
+	save := flags.String("save", "", "Path to save the measurements to for bench compare, by default a new file in "+defaultBenchDir())
+	noSave := flags.Bool("no-save", false, "Don't save the measurements")
+	asJSON := flags.Bool("json", jsonOutput, "Print a single JSON document instead of the report")
+	_ = flags.Parse(args) // ExitOnError handles the error
+	counts, err := parseCores(*coresList)
+	if flags.NArg() != 0 || *seconds < 1 || err != nil {
+		flags.Usage()
+		return 2
+	}
+
+	var m *matcher // nil without -find, the bench then measures the keygen alone
+	if *find != "" || *findSeed != "" || *prefix != "" || *suffix != "" {
+		options := matchOptions{lowercase: *lowercase, position: *position, prefix: *prefix, suffix: *suffix, findSeed: *findSeed}
+		if *find == "" {
+			*find, options.seed = *findSeed, *findSeed != ""
+		}
+		if m, err = newMatcher(*find, options); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "%v\n", err)
+			return exitInvalidPattern
+		}
+	}
+	run := newBenchRun(args)
+	for _, cores := range counts {
+		if !*asJSON {
+			fmt.Printf("Measuring %d cores for %d seconds...\n", cores, *seconds)
+		}
+		attempts, matches, elapsed := benchmark(cores, time.Duration(*seconds)*time.Second, m, *fastCrypto)
+		report := benchReport{Cores: cores, Seconds: elapsed, Attempts: attempts, Matches: matches}
+		report.Rate = float64(report.Attempts) / elapsed
+		report.RatePerCore = report.Rate / float64(cores)
+		if m != nil {
+			e := newEstimate(m.probability)
+			report.Patterns, report.Estimate = m.patterns, &e
+		}
+		run.Measurements = append(run.Measurements, report)
+		if *asJSON {
+			continue
+		}
+
+		fmt.Printf("%s addresses in %.1f seconds: %s addresses per second, %s per core\n", FormatInt64(report.Attempts),
+			elapsed, FormatInt64(int64(report.Rate)), FormatInt64(int64(report.RatePerCore)))
+		if m != nil && report.Estimate.Attempts50 > 0 {
+			fmt.Printf("%s: %s matches, at this rate 50%% chance of a match after %s and 99%% after %s\n",
+				m.label(), FormatInt64(report.Matches), formatETA(report.Estimate.Attempts50, report.Rate), formatETA(report.Estimate.Attempts99, report.Rate))
+		}
+	}
+
+	if !*noSave {
+		path, err := saveBenchRun(run, *save)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "failed to save the measurements: %v\n", err)
+			return 1
+		}
+		if !*asJSON {
+			fmt.Printf("Saved the measurements to %s for bench compare\n", path)
+		}
+	}
+	if *asJSON {
+		var document any = run.Measurements // one report per -cores count
+		if len(run.Measurements) == 1 {
+			document = run.Measurements[0]
+		}
+		if err := printJSON(document); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "%v\n", err)
+			return 1
+		}
+	}
+	return 0
+}
+
+// benchmark generates addresses on cores go-routines for duration, matching them against m unless it is nil, in
+// batches with fastCrypto, and returns how many it generated and matched and the seconds that actually took
+func benchmark(cores int, duration time.Duration, m *matcher, fastCrypto bool) (attempts, matches int64, elapsed float64) {
+	ctx, cancel := context.WithTimeout(context.Background(), duration)
+	defer cancel()
+	counters := make(workerCounters, cores)
+	var matched atomic.Int64
+	var workers sync.WaitGroup
+	started := time.Now()
+	for i := 0; i < cores; i++ {
+		workers.Add(1)
+		go func(scanned *workerCounter) {
+			defer workers.Done()
+			keys := vanity.NewKeys(nil) // like a worker, which encodes every address into buffers of its own
+			if fastCrypto {
+				keys = vanity.NewBatchKeys(nil)
+			}
+			for attempts := 1; ; attempts++ {
+				_ = keys.Next() // a search encodes every address, so the keygen alone includes that
+				if m != nil {
+					if _, _, found := m.MatchKeys(keys); found {
+						matched.Add(1)
+					}
+				}
+				scanned.Add(1)
+				if attempts%cancelCheck == 0 && ctx.Err() != nil {
+					return
+				}
+			}
+		}(&counters[i])
+	}
+	workers.Wait()
+	return counters.Total(), matched.Load(), time.Since(started).Seconds()
+}
+
+// formatETA formats how long scanning attempts addresses at rate addresses per second takes, in years once that no
+// longer fits a time.Duration
+func formatETA(attempts, rate float64) string {
+	const year = 365.25 * 24 * 60 * 60
+	seconds := attempts / rate
+	if seconds > 100*year {
+		return formatCount(seconds/year) + " years"
+	}
+	eta := time.Duration(seconds * float64(time.Second))
+	if eta < time.Second {
+		return eta.Round(time.Millisecond).String()
+	}
+	return eta.Round(time.Second).String()
+}
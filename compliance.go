@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bufio"         // used for finding the last line of an existing audit log
+	"bytes"         // used for trimming the last line of an existing audit log
+	"encoding/json" // every audit log entry is a JSON line
+	"errors"        // used for describing why a run isn't compliant
+	"io"            // the redactor wraps the writer of the log
+	"os"            // access the filesystem
+	"os/user"       // every audit log entry names who did it
+	"regexp"        // used for finding seeds in log lines
+	"sync"          // used for guarding the audit log from concurrent appends
+	"time"          // used for timestamping the audit log entries
+)
+
+// defaultAuditLog is where -compliance appends its audit log, and where keys, kms and shamir look for one
+const defaultAuditLog = "xlm-vanity-audit.log"
+
+// seedRedacted replaces every seed redacted from a log line or an audit log entry
+const seedRedacted = "S...REDACTED"
+
+// seedPattern matches anything shaped like a Stellar secret seed, S and 55 more base32 characters
+var seedPattern = regexp.MustCompile(`\bS[A-Z2-7]{55}\b`)
+
+// redactSeeds replaces every seed in s with a placeholder
+func redactSeeds(s string) string {
+	return seedPattern.ReplaceAllString(s, seedRedacted)
+}
+
+// seedRedactor is the writer of the log under -compliance, a seed that makes it into a log line anyway, from a
+// -fund-from argument or a bug, is replaced before it reaches the terminal, -log-file or the Windows event log
+type seedRedactor struct {
+	w io.Writer
+}
+
+// Write writes p to the wrapped writer without the seeds in it, reporting all of p as written
+func (r seedRedactor) Write(p []byte) (int, error) {
+	if !seedPattern.Match(p) {
+		return r.w.Write(p)
+	}
+	if _, err := r.w.Write(seedPattern.ReplaceAll(p, []byte(seedRedacted))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// checkCompliance returns why the flags of a -compliance run would let a plaintext seed out: seeds is the backend that
+// encrypts them, nil when they would be saved as they are
+func checkCompliance(seeds seedBackend, mqttSeeds, clipboardSeed, deterministic bool) error {
+	var errs []error
+	if seeds == nil {
+		errs = append(errs, errors.New("the seeds must be encrypted, set -kms-key, -store keyring, op or bw, or an -output secret store"))
+	}
+	if mqttSeeds {
+		errs = append(errs, errors.New("-mqtt-seeds publishes the seeds"))
+	}
+	if clipboardSeed {
+		errs = append(errs, errors.New("-clipboard-seed copies the seeds to the clipboard"))
+	}
+	if deterministic {
+		errs = append(errs, errors.New("-deterministic-seed keys can be regenerated by anyone who knows the seed"))
+	}
+	return errors.Join(errs...)
+}
+
+// auditEntry is one JSON line of the audit log
+type auditEntry struct {
+	Time    time.Time `json:"time"`
+	Event   string    `json:"event"`             // start, store, export or end
+	Address string    `json:"address,omitempty"` // the address whose seed was stored or exported
+	Detail  string    `json:"detail,omitempty"`  // where the seed went, or the command line, without any seed
+	User    string    `json:"user"`
+	Host    string    `json:"host"`
+	PID     int       `json:"pid"`
+	Prev    string    `json:"prev"` // the SHA-256 of the line before, so a line edited or removed breaks the chain
+}
+
+// auditLog appends an entry for every access to seed material to a file that is only ever appended to
+type auditLog struct {
+	mu   sync.Mutex
+	file *os.File
+	prev string // the SHA-256 of the last line
+	user string
+	host string
+	pid  int
+}
+
+// openAuditLog opens the audit log at path for appending, creating it when it doesn't exist yet
+func openAuditLog(path string) (*auditLog, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	a := &auditLog{file: file, pid: os.Getpid()}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := bytes.TrimSpace(scanner.Bytes()); len(line) > 0 {
+			a.prev = checksumOf(line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	if current, err := user.Current(); err == nil {
+		a.user = current.Username
+	}
+	a.host, _ = os.Hostname()
+	return a, nil
+}
+
+// Record appends an entry and syncs it to disk, an access that isn't on disk wasn't audited
+func (a *auditLog) Record(event, address, detail string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	line, err := json.Marshal(auditEntry{Time: time.Now().UTC(), Event: event, Address: address, Detail: redactSeeds(detail),
+		User: a.user, Host: a.host, PID: a.pid, Prev: a.prev})
+	if err != nil {
+		return err
+	}
+	if _, err := a.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	a.prev = checksumOf(line)
+	return a.file.Sync()
+}
+
+// Close closes the audit log
+func (a *auditLog) Close() error {
+	return a.file.Close()
+}
+
+// auditExport records that the seed of address was revealed by command in the audit log at path, when a -compliance
+// run created one there; a log that exists but can't be appended to stops the export
+func auditExport(path, command, address string) error {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	audit, err := openAuditLog(path)
+	if err != nil {
+		return err
+	}
+	return errors.Join(audit.Record("export", address, command), audit.Close())
+}
@@ -0,0 +1,399 @@
+package main
+
+import (
+	"bytes"                                                      // used for building the config file before writing it
+	"encoding/json"                                              // used for writing .json config files
+	"errors"                                                     // used for telling a missing config file apart
+	"flag"                                                       // the config subcommand parses its own arguments and lists the flags of the search
+	"fmt"                                                        // used for writing the config files and the report
+	"github.com/andreimerlescu/xlm-vanity-address-finder/vanity" // used for checking the -position of a config file
+	"os"                                                         // access the filesystem
+	"path/filepath"                                              // used for telling the config file formats apart
+	"slices"                                                     // used for checking the -key-type
+	"sort"                                                       // used for listing the problems in a stable order
+	"strconv"                                                    // used for quoting string values
+	"strings"                                                    // used for the comments of the config files
+)
+
+// configFormats are the formats `config init` writes, the ones -config reads
+var configFormats = []string{"yaml", "json", "ini"}
+
+// configSkippedKeys are the flags a config file can't set: -config names the file and -profile picks inside it
+var configSkippedKeys = map[string]bool{cKeyConfig: true, cKeyProfile: true}
+
+// configMachineKeys are the flags whose default depends on the machine `config init` runs on, written commented out
+// (and left out of .json) so the file works the same on any machine
+var configMachineKeys = map[string]bool{cKeyCores: true, cKeyRunsDB: true, cKeyPidfile: true}
+
+// configChecks validate the values `config validate` can check without starting a search, beyond their type
+var configChecks = map[string]func(string) error{
+	cKeyLogLevel: func(value string) error {
+		_, err := resolveLogLevel(value, false, false)
+		return err
+	},
+	cKeyNetwork: func(value string) error {
+		_, err := lookupNetwork(value)
+		return err
+	},
+	cKeyPosition: func(value string) error {
+		if !vanity.ValidPosition(value) {
+			return fmt.Errorf("expected %s, %s or %s", positionAnywhere, positionStart, positionEnd)
+		}
+		return nil
+	},
+	cKeyPrefix: func(value string) error {
+		value, layout := strings.ToUpper(value), vanity.AccountLayout
+		if value != "" && strings.IndexByte(keyLeads, value[0]) >= 0 { // the -prefix of another -key-type
+			layout = leadLayout(value[0])
+		}
+		return checkAffix(cKeyPrefix, value, 0, true, layout)
+	},
+	cKeyKeyType: func(value string) error {
+		if !slices.Contains(keyTypes, value) {
+			return fmt.Errorf("expected %s", strings.Join(keyTypes, ", "))
+		}
+		return nil
+	},
+	cKeySplitKey: func(value string) error {
+		if value == "" {
+			return nil
+		}
+		_, err := parseSplitKey(value)
+		return err
+	},
+	cKeySuffix: func(value string) error {
+		return checkAffix(cKeySuffix, strings.ToUpper(value), addressLength-len(value), true, vanity.AccountLayout)
+	},
+	cKeyCluster: func(value string) error {
+		if value != "" && value != clusterLAN {
+			return fmt.Errorf("expected %s, or nothing", clusterLAN)
+		}
+		return nil
+	},
+	cKeyEventsRedact: func(value string) error {
+		_, err := parseRedact(value)
+		return err
+	},
+	cKeyS3SSE: func(value string) error {
+		return s3Options{sse: value}.validate()
+	},
+	cKeyStore: func(value string) error {
+		if value != storeFile && value != storeKeyring && value != storeOnePassword && value != storeBitwarden {
+			return fmt.Errorf("expected %s, %s, %s or %s", storeFile, storeKeyring, storeOnePassword, storeBitwarden)
+		}
+		return nil
+	},
+	cKeyOutputTemplate: func(value string) error {
+		if value != "" && !strings.Contains(value, patternPlaceholder) {
+			return fmt.Errorf("it must contain %s", patternPlaceholder)
+		}
+		return nil
+	},
+	cKeyCores: func(value string) error {
+		if value == coresAuto {
+			return nil
+		}
+		if n, err := strconv.Atoi(value); err != nil || n < 1 {
+			return fmt.Errorf("expected at least 1 or %s", coresAuto)
+		}
+		return nil
+	},
+	cKeyThrottle: between(1, 100),
+	cKeyEvery: func(value string) error {
+		_, err := parseEvery(value)
+		return err
+	},
+	cKeyStatsEvery: atLeast(1),
+	cKeyBackups:    atLeast(0),
+	cKeyShards:     atLeast(0),
+	cKeyMnemonicWords: func(value string) error {
+		if value != "12" && value != "24" {
+			return errors.New("expected 12 or 24")
+		}
+		return nil
+	},
+}
+
+// atLeast checks that an integer value is at least low
+func atLeast(low int) func(string) error {
+	return func(value string) error {
+		if n, err := strconv.Atoi(value); err != nil || n < low {
+			return fmt.Errorf("expected at least %d", low)
+		}
+		return nil
+	}
+}
+
+// between checks that an integer value is low to high
+func between(low, high int) func(string) error {
+	return func(value string) error {
+		if n, err := strconv.Atoi(value); err != nil || n < low || n > high {
+			return fmt.Errorf("expected %d to %d", low, high)
+		}
+		return nil
+	}
+}
+
+// runConfig implements `xlm-vanity-address-finder config init` and `config validate`, it returns the process exit code
+func runConfig(args []string) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "init":
+			return runConfigInit(args[1:])
+		case "validate":
+			return runConfigValidate(args[1:])
+		}
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "Usage: %s config init [-format yaml|json|ini] [-force] [file] | config validate [-json] <file>\n", os.Args[0])
+	return 2
+}
+
+// configFlags defines the flags of the search and returns the ones a config file can set, sorted by name
+func configFlags() []*flag.Flag {
+	defineFlags(commandFind)
+	flags := make([]*flag.Flag, 0)
+	flag.VisitAll(func(f *flag.Flag) {
+		if !configSkippedKeys[f.Name] {
+			flags = append(flags, f)
+		}
+	})
+	return flags
+}
+
+// runConfigInit implements `config init [-format yaml] [-force] [file]`, writing a config file with every key the
+// search reads set to its default and explained by its -help text
+func runConfigInit(args []string) int {
+	flags := flag.NewFlagSet("config init", flag.ExitOnError)
+	flags.Usage = func() {
+		_, _ = fmt.Fprintf(flags.Output(), "Usage: %s config init [-format yaml|json|ini] [-force] [file]\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	format := flags.String("format", "yaml", "Format of the config file: yaml, json or ini")
+	force := flags.Bool("force", false, "Overwrite the file when it exists")
+	_ = flags.Parse(args) // ExitOnError handles the error
+	if flags.NArg() > 1 {
+		flags.Usage()
+		return 2
+	}
+	path := "config." + *format
+	if flags.NArg() == 1 {
+		path = flags.Arg(0)
+	}
+
+	var data []byte
+	switch *format {
+	case "yaml":
+		data = configYAML(configFlags())
+	case "ini":
+		data = configINI(configFlags())
+	case "json":
+		data = configJSON(configFlags())
+	default:
+		_, _ = fmt.Fprintf(os.Stderr, "invalid -format %q: expected %s\n", *format, orList(configFormats))
+		return 2
+	}
+	if ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), "."); ext != *format && !(ext == "yml" && *format == "yaml") {
+		_, _ = fmt.Fprintf(os.Stderr, "%s: -config tells the formats apart by extension, name it *.%s\n", path, *format)
+		return 2
+	}
+
+	mode := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if !*force {
+		mode |= os.O_EXCL
+	}
+	file, err := os.OpenFile(path, mode, 0600)
+	if errors.Is(err, os.ErrExist) {
+		_, _ = fmt.Fprintf(os.Stderr, "%s already exists, pass -force to overwrite it\n", path)
+		return 1
+	}
+	if err == nil {
+		_, err = file.Write(data)
+		err = errors.Join(err, file.Close())
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	fmt.Printf("Wrote %s, use it with -config %s\n", path, path)
+	return 0
+}
+
+// configValue is the default of f as the value of a config file: a bool, an int or a string
+func configValue(f *flag.Flag) any {
+	if getter, ok := f.Value.(flag.Getter); ok {
+		return getter.Get()
+	}
+	return f.DefValue
+}
+
+// configHeader explains a config file written by `config init`, every line is prefixed with the comment marker
+var configHeader = []string{
+	"xlm-vanity-address-finder configuration, written by `config init` with every key set to its default.",
+	"Load it with -config <file>; flags given on the command line win over it, and a SIGHUP re-reads find and every.",
+}
+
+// configYAML writes flags as a commented YAML config file
+func configYAML(flags []*flag.Flag) []byte {
+	var b bytes.Buffer
+	for _, line := range configHeader {
+		_, _ = fmt.Fprintf(&b, "# %s\n", line)
+	}
+	for _, f := range flags {
+		_, _ = fmt.Fprintf(&b, "\n# %s\n", f.Usage)
+		value := configValue(f)
+		if s, ok := value.(string); ok {
+			value = strconv.Quote(s) // a double-quoted YAML string
+		}
+		if configMachineKeys[f.Name] {
+			_, _ = fmt.Fprintf(&b, "# %s: %v # the default of this machine\n", f.Name, value)
+			continue
+		}
+		_, _ = fmt.Fprintf(&b, "%s: %v\n", f.Name, value)
+	}
+	b.WriteString("\n# -profile <name> applies the keys of a profile on top of the keys above\n")
+	b.WriteString("# profiles:\n#   quick:\n#     stop: 600\n#   overnight:\n#     stop: 28800\n#     throttle: 60\n")
+	return b.Bytes()
+}
+
+// configINI writes flags as a commented INI config file, the keys go in the default section
+func configINI(flags []*flag.Flag) []byte {
+	var b bytes.Buffer
+	for _, line := range configHeader {
+		_, _ = fmt.Fprintf(&b, "; %s\n", line)
+	}
+	for _, f := range flags {
+		_, _ = fmt.Fprintf(&b, "\n; %s\n", f.Usage)
+		value := fmt.Sprint(configValue(f))
+		if strings.ContainsAny(value, ";#") {
+			value = strconv.Quote(value) // unquoted, these start a comment
+		}
+		if configMachineKeys[f.Name] {
+			_, _ = fmt.Fprintf(&b, "; %s = %s ; the default of this machine\n", f.Name, value)
+			continue
+		}
+		_, _ = fmt.Fprintf(&b, "%s = %s\n", f.Name, value)
+	}
+	b.WriteString("\n; -profile <name> applies the keys of a profile on top of the keys above\n")
+	b.WriteString("; [profiles.quick]\n; stop = 600\n; [profiles.overnight]\n; stop = 28800\n; throttle = 60\n")
+	return b.Bytes()
+}
+
+// configJSON writes flags as a JSON config file, which has no comments: `config init -format yaml` explains the keys
+func configJSON(flags []*flag.Flag) []byte {
+	values := make(map[string]any, len(flags))
+	for _, f := range flags {
+		if !configMachineKeys[f.Name] {
+			values[f.Name] = configValue(f)
+		}
+	}
+	data, _ := json.MarshalIndent(values, "", "  ") // bools, ints and strings always encode
+	return append(data, '\n')
+}
+
+// configReport is the --json document of `config validate`
+type configReport struct {
+	Valid    bool     `json:"valid"`    // whether the file can be loaded with -config as it is
+	Problems []string `json:"problems"` // what is wrong with it
+}
+
+// runConfigValidate implements `config validate [-json] <file>`, checking every key of the file and of its profiles
+// against the flags of the search: unknown keys, values of the wrong type and values the search would refuse
+func runConfigValidate(args []string) int {
+	flags := flag.NewFlagSet("config validate", flag.ExitOnError)
+	flags.Usage = func() {
+		_, _ = fmt.Fprintf(flags.Output(), "Usage: %s config validate [-json] <file>\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	asJSON := flags.Bool("json", jsonOutput, "Print a single JSON document instead of the report")
+	_ = flags.Parse(args) // ExitOnError handles the error
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+	path := flags.Arg(0)
+
+	report := configReport{Problems: validateConfigFile(path)}
+	report.Valid = len(report.Problems) == 0
+	code := 0
+	if !report.Valid {
+		code = 1
+	}
+	if *asJSON {
+		if err := printJSON(report); err != nil {
+			return 1
+		}
+		return code
+	}
+	for _, problem := range report.Problems {
+		fmt.Printf("%s: %s\n", path, problem)
+	}
+	if report.Valid {
+		fmt.Printf("%s: ok\n", path)
+	}
+	return code
+}
+
+// validateConfigFile returns every problem of the config file at path, none when -config can load it
+func validateConfigFile(path string) []string {
+	raw, err := readConfigFile(path)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	known := make(map[string]*flag.Flag)
+	for _, f := range configFlags() {
+		known[f.Name] = f
+	}
+
+	problems := make([]string, 0)
+	check := func(where, key string, value any) {
+		f, ok := known[key]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%sunknown key %q", where, key))
+			return
+		}
+		text := configString(value)
+		if err := f.Value.Set(text); err != nil {
+			problems = append(problems, fmt.Sprintf("%s%s: invalid value %q: expected a %s", where, key, text, configType(f)))
+		} else if validate := configChecks[key]; validate != nil {
+			if err := validate(text); err != nil {
+				problems = append(problems, fmt.Sprintf("%s%s: invalid value %q: %v", where, key, text, err))
+			}
+		}
+		_ = f.Value.Set(f.DefValue) // the next profile starts from the defaults again
+	}
+	for key, value := range raw {
+		if key != profilesKey {
+			check("", key, value)
+		}
+	}
+	if section, found := raw[profilesKey]; found {
+		profiles, ok := section.(map[string]any)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s: expected a map of profile names to their keys", profilesKey))
+		}
+		for name, values := range profiles {
+			keys, ok := values.(map[string]any)
+			if !ok {
+				problems = append(problems, fmt.Sprintf("profile %q: expected a map of keys", name))
+				continue
+			}
+			for key, value := range keys {
+				check(fmt.Sprintf("profile %q: ", name), key, value)
+			}
+		}
+	}
+	sort.Strings(problems)
+	return problems
+}
+
+// configType names the type of value f takes, for reporting a value of the wrong type
+func configType(f *flag.Flag) string {
+	switch configValue(f).(type) {
+	case bool:
+		return "boolean"
+	case int:
+		return "whole number"
+	default:
+		return "string"
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"  // used for printing the cost report
+	"math" // used for the hours of very long searches
+	"time" // used for the length of the rate measurement
+)
+
+// costBench is how long estimate measures the rate of this machine when -price is set without -rate
+const costBench = 5 * time.Second
+
+// costChances are the chances of a match estimate prices
+var costChances = []float64{0.5, 0.9, 0.99}
+
+// pricing is what estimate needs to price a search: -price, and -rate or the -cores to measure it on
+type pricing struct {
+	price float64 // -price, dollars per hour of the machine, 0 to only report the times
+	rate  float64 // -rate, addresses per second, 0 to measure it
+	cores int     // the workers the rate is measured with
+	fast  bool    // -fast-crypto, measured with it
+}
+
+// costEstimate is the cost block of the --json document of estimate
+type costEstimate struct {
+	Price    float64     `json:"price"`    // dollars per hour of the machine
+	Rate     float64     `json:"rate"`     // addresses per second the machine scans
+	Measured bool        `json:"measured"` // whether Rate was measured on this machine rather than given with -rate
+	Chances  []costPoint `json:"chances"`  // the time and cost of every chance of costChances
+}
+
+// costPoint is the time and cost of a chance of a match
+type costPoint struct {
+	Chance  float64 `json:"chance"`  // the chance of a match, 0.5 for 50%
+	Hours   float64 `json:"hours"`   // hours of searching it takes
+	Dollars float64 `json:"dollars"` // what those hours cost at the price
+}
+
+// newCostEstimate prices a search matching with probability p per address, measuring the rate of m on this machine
+// first when no -rate is given; nil when neither -price nor -rate is set or p can't match
+func newCostEstimate(p float64, m *matcher, pricing pricing) *costEstimate {
+	if p <= 0 || (pricing.price <= 0 && pricing.rate <= 0) {
+		return nil
+	}
+	cost := &costEstimate{Price: pricing.price, Rate: pricing.rate}
+	if cost.Rate <= 0 {
+		if !jsonOutput {
+			translator.Printf("measuring the addresses per second of -cores %d for %s, set -rate to skip this\n", pricing.cores, costBench)
+		}
+		attempts, _, elapsed := benchmark(pricing.cores, costBench, m, pricing.fast)
+		cost.Rate, cost.Measured = float64(attempts)/elapsed, true
+	}
+	for _, chance := range costChances {
+		hours := attemptsFor(chance, p) / cost.Rate / 3600
+		cost.Chances = append(cost.Chances, costPoint{Chance: chance, Hours: hours, Dollars: hours * cost.Price})
+	}
+	return cost
+}
+
+// Print writes the cost report of the estimate, in the -lang
+func (c *costEstimate) Print() {
+	if c.Measured {
+		translator.Printf("at %s addresses per second measured on this machine:\n", FormatInt64(int64(c.Rate)))
+	} else {
+		translator.Printf("at %s addresses per second:\n", FormatInt64(int64(c.Rate)))
+	}
+	for _, point := range c.Chances {
+		chance, eta := fmt.Sprintf("%.0f%%", point.Chance*100), formatETA(point.Hours*3600, 1)
+		if c.Price > 0 {
+			translator.Printf("  %s chance of a match after %s, %s at %s an hour\n", chance, eta, formatDollars(point.Dollars), formatDollars(c.Price))
+		} else {
+			translator.Printf("  %s chance of a match after %s\n", chance, eta)
+		}
+	}
+}
+
+// formatDollars formats an amount of dollars with cents, and without them once they don't matter
+func formatDollars(dollars float64) string {
+	if dollars >= 1e6 || math.IsInf(dollars, 1) {
+		return "$" + formatCount(math.Round(dollars))
+	}
+	return fmt.Sprintf("$%.2f", dollars)
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"errors"        // used for telling a missing PID file apart
+	"flag"          // the stop and status subcommands parse their own arguments
+	"fmt"           // used for reporting the state of the daemon
+	"os"            // access the filesystem
+	"os/exec"       // the daemon is this executable started again in the background
+	"path/filepath" // used for the default PID file
+	"strconv"       // used for reading and writing the PID file
+	"strings"       // used for trimming the PID file
+	"time"          // used for waiting on a stopping daemon
+)
+
+// daemonEnv marks the background process started by -daemon, so it runs the search instead of starting another one
+const daemonEnv = "XLM_VANITY_DAEMON"
+
+// daemonStopTimeout is how long `stop` waits for the daemon to compact its results and exit
+const daemonStopTimeout = 30 * time.Second
+
+// defaultPidfile is where -daemon, stop and status keep the PID when -pidfile isn't set
+var defaultPidfile = filepath.Join(os.TempDir(), "xlm-vanity-address-finder.pid")
+
+// isDaemon reports whether this process is the background process of -daemon
+func isDaemon() bool {
+	return os.Getenv(daemonEnv) == "1"
+}
+
+// startDaemon starts this executable again with args in its own session, its output appended to logFile, and records
+// its PID in pidfile; it refuses to start a second daemon on the same PID file
+func startDaemon(pidfile, logFile string, args []string) (int, error) {
+	if pid, err := readPidfile(pidfile); err == nil && processAlive(pid) {
+		return 0, fmt.Errorf("already running as PID %d (%s)", pid, pidfile)
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return 0, err
+	}
+	logs, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open -log-file %s: %w", logFile, err)
+	}
+	defer func() { _ = logs.Close() }() // the daemon has its own copy
+
+	cmd := exec.Command(executable, args...)
+	cmd.Env = append(os.Environ(), daemonEnv+"=1")
+	cmd.Stdout, cmd.Stderr = logs, logs
+	cmd.SysProcAttr = daemonAttr()
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("failed to start the daemon: %w", err)
+	}
+	pid := cmd.Process.Pid
+	if err := os.WriteFile(pidfile, []byte(strconv.Itoa(pid)+"\n"), 0644); err != nil {
+		_ = cmd.Process.Kill()
+		return 0, fmt.Errorf("failed to write -pidfile %s: %w", pidfile, err)
+	}
+	return pid, cmd.Process.Release()
+}
+
+// removePidfile deletes pidfile when the daemon exits, unless it already belongs to another process
+func removePidfile(pidfile string) {
+	if pid, err := readPidfile(pidfile); err == nil && pid == os.Getpid() {
+		_ = os.Remove(pidfile)
+	}
+}
+
+// readPidfile returns the PID recorded in pidfile
+func readPidfile(pidfile string) (int, error) {
+	data, err := os.ReadFile(pidfile)
+	if err != nil {
+		return 0, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, fmt.Errorf("%s doesn't hold a PID", pidfile)
+	}
+	return pid, nil
+}
+
+// runDaemonControl implements `xlm-vanity-address-finder stop` and `status` for a -daemon; status exits 0 while the
+// daemon runs and 3 when it doesn't, like an LSB init script
+func runDaemonControl(command string, args []string) int {
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	pidfile := flags.String("pidfile", defaultPidfile, "PID file of the -daemon")
+	flags.Usage = func() {
+		_, _ = fmt.Fprintf(flags.Output(), "Usage: %s %s [-pidfile path]\n", os.Args[0], command)
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args) // ExitOnError handles the error
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return 2
+	}
+
+	pid, err := readPidfile(*pidfile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		_, _ = fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	if err != nil || !processAlive(pid) {
+		fmt.Println("not running")
+		_ = os.Remove(*pidfile) // stale after a crash
+		if command == "status" {
+			return exitNoMatches
+		}
+		return 0
+	}
+
+	if command == "status" {
+		fmt.Printf("running as PID %d\n", pid)
+		return 0
+	}
+	if err := terminate(pid); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "failed to stop PID %d: %v\n", pid, err)
+		return 1
+	}
+	for deadline := time.Now().Add(daemonStopTimeout); processAlive(pid); time.Sleep(100 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			_, _ = fmt.Fprintf(os.Stderr, "PID %d is still running after %s\n", pid, daemonStopTimeout)
+			return 1
+		}
+	}
+	fmt.Printf("stopped PID %d\n", pid)
+	return 0
+}
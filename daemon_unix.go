@@ -0,0 +1,24 @@
+//go:build unix
+
+package main
+
+import (
+	"errors"  // used for telling a process of another user apart
+	"syscall" // used for setsid(2) and kill(2)
+)
+
+// daemonAttr starts the daemon in a new session, so it outlives the terminal and ignores its SIGHUP and SIGINT
+func daemonAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
+
+// processAlive reports whether a process with pid exists
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// terminate asks pid to stop with SIGTERM, so it compacts its results before exiting
+func terminate(pid int) error {
+	return syscall.Kill(pid, syscall.SIGTERM)
+}
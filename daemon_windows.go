@@ -0,0 +1,38 @@
+//go:build windows
+
+package main
+
+import (
+	"golang.org/x/sys/windows" // used for the process creation flags and querying processes
+	"os"                       // used for killing the daemon
+	"syscall"                  // used for the process attributes of the daemon
+)
+
+// stillActive is the exit code GetExitCodeProcess reports for a running process
+const stillActive = 259
+
+// daemonAttr starts the daemon detached from the console, so it outlives the window and ignores its Ctrl+C
+func daemonAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{CreationFlags: windows.CREATE_NEW_PROCESS_GROUP | windows.DETACHED_PROCESS, HideWindow: true}
+}
+
+// processAlive reports whether a process with pid is running
+func processAlive(pid int) bool {
+	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return false
+	}
+	defer func() { _ = windows.CloseHandle(handle) }()
+	var code uint32
+	return windows.GetExitCodeProcess(handle, &code) == nil && code == stillActive
+}
+
+// terminate kills pid; a detached process has no console to send Ctrl+Break to, its pending results are still in the
+// journal and replayed by the next run
+func terminate(pid int) error {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return process.Kill()
+}
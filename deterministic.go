@@ -0,0 +1,24 @@
+package main
+
+import (
+	"crypto/sha256"                                              // used for deriving each worker's stream from the -deterministic-seed
+	"fmt"                                                        // used for combining the seed with the worker index
+	"github.com/andreimerlescu/xlm-vanity-address-finder/vanity" // used for turning the stream into keys
+	"github.com/stellar/go/keypair"                              // the candidate key pairs
+	"math/rand/v2"                                               // ChaCha8 is the seeded CSPRNG stream
+)
+
+// keySource produces the next candidate key pair of a worker
+type keySource func() (*keypair.Full, error)
+
+// deterministicStream returns the ChaCha8 stream of worker seeded with the SHA-256 of seed and worker, so the same
+// -deterministic-seed and -cores always generate the same keys. Anyone who knows the seed can regenerate these keys:
+// they are for tests and demos and must never be funded.
+func deterministicStream(seed string, worker int) *rand.ChaCha8 {
+	return rand.NewChaCha8(sha256.Sum256([]byte(fmt.Sprintf("%s/%d", seed, worker))))
+}
+
+// deterministicKeys returns the keys of worker drawn from its deterministicStream
+func deterministicKeys(seed string, worker int) *vanity.Keys {
+	return vanity.NewKeys(deterministicStream(seed, worker))
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bufio"                                                      // used for reading word lists one word per line
+	_ "embed"                                                    // the built-in -profanity-filter blocklist is compiled in
+	"fmt"                                                        // used for wrapping errors with the dictionary path
+	"github.com/andreimerlescu/xlm-vanity-address-finder/vanity" // the lowercase lookalikes and the odds of a word
+	"io"                                                         // word lists come from files or the embedded blocklist
+	"os"                                                         // access the filesystem
+	"path/filepath"                                              // used for naming the dictionary in logs and the default output file
+	"slices"                                                     // used for merging the blocklists
+	"strings"                                                    // used for cleaning up the words
+)
+
+// wordAutomaton is an Aho-Corasick automaton over the address alphabet, compiled into a DFA so that finding every word
+// of a dictionary in an address costs one table lookup per character, however many words there are
+type wordAutomaton struct {
+	words   []string                     // the upper-cased words, states refer to them by index
+	next    [][len(base32Alphabet)]int32 // per state, the state after each address character
+	longest []int32                      // per state, the longest word ending in it (directly or through its suffixes), -1 for none
+	symbols [256]int8                    // the alphabet index of every address character, lookalikes folded with -lowercase
+}
+
+// newWordAutomaton compiles words, which must only contain A-Z; with lowercase the digits 2, 5 and 6 of an address
+// read as the Z, S and B of a word (see vanity.Lookalikes)
+func newWordAutomaton(words []string, lowercase bool) *wordAutomaton {
+	a := &wordAutomaton{words: words}
+	for i := range a.symbols {
+		a.symbols[i] = int8(strings.IndexByte(base32Alphabet, byte(i)))
+	}
+	if lowercase {
+		for _, letter := range "ZSB" {
+			for _, digit := range vanity.Lookalikes[letter] {
+				if strings.ContainsRune(base32Alphabet, digit) {
+					a.symbols[digit] = a.symbols[letter]
+				}
+			}
+		}
+	}
+
+	// the trie of the words, 0 is the root and missing edges are 0 until the failure links fill them in
+	a.addState()
+	for w, word := range words {
+		state := int32(0)
+		for i := 0; i < len(word); i++ {
+			symbol := a.symbols[word[i]]
+			if a.next[state][symbol] == 0 {
+				a.next[state][symbol] = a.addState()
+			}
+			state = a.next[state][symbol]
+		}
+		a.longest[state] = int32(w) // the words are unique, so no other word ends in this state
+	}
+
+	// breadth first, every missing edge goes where the failure link of its state goes, and every state inherits the
+	// longest word of its failure link when it doesn't end one itself (its own word is always longer)
+	fail := make([]int32, len(a.next))
+	queue := make([]int32, 0, len(a.next))
+	for symbol := range a.next[0] {
+		if child := a.next[0][symbol]; child != 0 {
+			queue = append(queue, child)
+		}
+	}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		if a.longest[state] < 0 {
+			a.longest[state] = a.longest[fail[state]]
+		}
+		for symbol, child := range a.next[state] {
+			if child == 0 {
+				a.next[state][symbol] = a.next[fail[state]][symbol]
+				continue
+			}
+			fail[child] = a.next[fail[state]][symbol]
+			queue = append(queue, child)
+		}
+	}
+	return a
+}
+
+// addState appends an empty state and returns it
+func (a *wordAutomaton) addState() int32 {
+	a.next = append(a.next, [len(base32Alphabet)]int32{})
+	a.longest = append(a.longest, -1)
+	return int32(len(a.next) - 1)
+}
+
+// Find returns the longest word address contains, the first one of them on a tie, and the offset it starts at
+func (a *wordAutomaton) Find(address string) (word string, at int, found bool) {
+	state, best := int32(0), int32(-1)
+	for i := 0; i < len(address); i++ {
+		state = a.next[state][a.symbols[address[i]]]
+		if w := a.longest[state]; w >= 0 && (best < 0 || len(a.words[w]) > len(a.words[best])) {
+			best, at = w, i+1-len(a.words[w])
+		}
+	}
+	if best < 0 {
+		return "", 0, false
+	}
+	return a.words[best], at, true
+}
+
+// builtinBlocklist is the word list -profanity-filter keeps out of the results, English profanity and slurs
+//
+//go:embed blocklist.txt
+var builtinBlocklist string
+
+// readWords reads a word list, one word per line, upper-cased and without duplicates; words shorter than minWord or
+// with anything but the letters A-Z (apostrophes, accents, digits) are left out, as are words too long for an address
+func readWords(list io.Reader, minWord int) ([]string, error) {
+	words := make([]string, 0)
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(list)
+	for scanner.Scan() {
+		word := strings.ToUpper(strings.TrimSpace(scanner.Text()))
+		if len(word) < minWord || len(word) > addressLength || seen[word] || strings.Trim(word, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+			continue
+		}
+		seen[word] = true
+		words = append(words, word)
+	}
+	return words, scanner.Err()
+}
+
+// loadWords reads the word list at path for the -flag it was given with, see readWords
+func loadWords(flag, path string, minWord int) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+	words, err := readWords(file, minWord)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read -%s %s: %w", flag, path, err)
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("-%s %s has no words of at least %d letters", flag, path, minWord)
+	}
+	return words, nil
+}
+
+// newDictionaryMatcher is the matcher of -dictionary: any address containing one of the words of path with at least
+// minWord letters matches, and the longest word it contains is reported as its pattern; an address containing any of
+// the blocked words anywhere, not only inside the word found, never matches
+func newDictionaryMatcher(path string, minWord int, lowercase bool, blocked []string) (*matcher, error) {
+	if minWord < 1 {
+		return nil, fmt.Errorf("invalid -min-word %d: expected at least 1", minWord)
+	}
+	words, err := loadWords(cKeyDictionary, path, minWord)
+	if err != nil {
+		return nil, err
+	}
+	automaton := newWordAutomaton(words, lowercase)
+	debugf("compiled %d words of -dictionary %s into %d states", len(words), path, len(automaton.next))
+	m := &matcher{
+		dictionary:  fmt.Sprintf("%s (%d words of %d+ letters)", filepath.Base(path), len(words), minWord),
+		words:       automaton,
+		probability: vanity.AnyPatternProbability(words, lowercase, positionAnywhere),
+	}
+	if len(blocked) > 0 {
+		m.blocked = newWordAutomaton(blocked, lowercase)
+		m.probability *= 1 - vanity.AnyPatternProbability(blocked, lowercase, positionAnywhere) // roughly, a blocked word rarely overlaps the word found
+		m.dictionary = fmt.Sprintf("%s (%d words of %d+ letters, %d blocked)", filepath.Base(path), len(words), minWord, len(blocked))
+		debugf("blocking addresses with any of %d words", len(blocked))
+	}
+	return m, nil
+}
+
+// loadBlocklist collects the words no -dictionary match may contain: the built-in list with profanityFilter set, and
+// the words of the -blocklist file at path when it is not empty
+func loadBlocklist(profanityFilter bool, path string) ([]string, error) {
+	blocked := make([]string, 0)
+	if profanityFilter {
+		words, err := readWords(strings.NewReader(builtinBlocklist), 1)
+		if err != nil {
+			return nil, err
+		}
+		blocked = append(blocked, words...)
+	}
+	if path != "" {
+		words, err := loadWords(cKeyBlocklist, path, 1)
+		if err != nil {
+			return nil, err
+		}
+		blocked = append(blocked, words...)
+	}
+	slices.Sort(blocked)
+	return slices.Compact(blocked), nil
+}
+
+// dictionaryOutputPath is the default -output of -dictionary, en.txt writes its matches to en-words.json
+func dictionaryOutputPath(path string) string {
+	base := filepath.Base(path)
+	return filepath.Join(".", strings.TrimSuffix(base, filepath.Ext(base))+"-words.json")
+}
@@ -0,0 +1,332 @@
+package main
+
+import (
+	"fmt"                                                        // used for printing the -dry-run report
+	"github.com/andreimerlescu/xlm-vanity-address-finder/vanity" // the odds of every pattern
+	"math"                                                       // used for turning probabilities into attempt counts
+	"os"                                                         // errors go to STDERR when STDOUT is the --json document
+	"slices"                                                     // used for listing every offending character once
+	"strings"                                                    // used for splitting -find and listing the offending characters
+)
+
+// patternReport is what -dry-run finds out about one -find pattern without generating a single address
+type patternReport struct {
+	Input       string   // the pattern as given on the command line
+	Pattern     string   // the upper-cased pattern the workers search for
+	Field       string   // what Pattern is searched for in, fieldAddress or fieldSeed
+	Lead        byte     // the character Field starts with, G, S or the lead of the -key-type
+	Length      int      // the characters of Field
+	Invalid     []string // characters of Pattern that never appear in an address
+	Offsets     []int    // offsets inside the address Pattern can start at
+	Probability float64  // the chance a single address contains Pattern
+	Suggestions []string // achievable patterns close to an infeasible Pattern
+	Checksum    int      // with -position end, the characters of Pattern falling on the checksum ending Field
+	End         string   // with -position end, why an infeasible Pattern can't end Field
+}
+
+// inspectPattern checks pattern against the address alphabet and the fixed leading characters of an address at the
+// offsets position allows, letting characters stand in for their lowercase lookalikes when lowercase is set
+func inspectPattern(input string, lowercase bool, position string) patternReport {
+	return inspectField(input, lowercase, position, fieldAddress, vanity.AccountLayout)
+}
+
+// inspectField is inspectPattern for a pattern searched for in field, the address or the seed, laid out like layout:
+// the version byte fixes its first two characters, a -muxed-id or -signed-payload the characters before the checksum
+// and the padding of the last character leaves it only some of the alphabet
+func inspectField(input string, lowercase bool, position, field string, layout *vanity.Layout) patternReport {
+	report := patternReport{Input: input, Pattern: strings.ToUpper(input), Field: field, Lead: layout.Lead(), Length: layout.Length()}
+	classes := vanity.PatternClasses(report.Pattern, lowercase)
+	for i, r := range []rune(report.Pattern) {
+		if !strings.ContainsAny(base32Alphabet, classes[i]) && !slices.Contains(report.Invalid, string(r)) {
+			report.Invalid = append(report.Invalid, string(r))
+		}
+	}
+	first, last := layout.PatternOffsets(position, len(classes))
+	for offset := max(first, 0); offset <= last; offset++ {
+		if layout.OffsetProbability(classes, offset) > 0 {
+			report.Offsets = append(report.Offsets, offset)
+		}
+	}
+	report.Probability = layout.ClassesProbability(classes, position)
+	if position == positionEnd && len(classes) <= layout.Length() {
+		report.Checksum = min(len(classes), layout.Length()-layout.Checksum())
+		if len(report.Invalid) == 0 && len(report.Offsets) == 0 {
+			report.End = endReason(layout, len(classes))
+		}
+	}
+	if !report.Feasible() {
+		report.Suggestions = suggestPatterns(report.Pattern)
+	}
+	return report
+}
+
+// endReason explains what the last n characters of a key of layout can be: any character where the key or its
+// checksum falls, the characters of a -muxed-id or -signed-payload where they fall and only some where the padding
+// of the last character does
+func endReason(layout *vanity.Layout, n int) string {
+	var end strings.Builder
+	for i := layout.Length() - n; i < layout.Length(); i++ {
+		switch alphabet := layout.Alphabet(i); {
+		case len(alphabet) == len(vanity.Alphabet):
+			end.WriteByte('?')
+		case len(alphabet) == 1:
+			end.WriteString(alphabet)
+		default:
+			end.WriteString("[" + alphabet + "]")
+		}
+	}
+	return fmt.Sprintf("every %c... key ends with %s, ? being any character and [...] one of those listed", layout.Lead(), end.String())
+}
+
+// Feasible reports whether any address can contain the pattern
+func (r patternReport) Feasible() bool {
+	return len(r.Invalid) == 0 && len(r.Offsets) > 0
+}
+
+// Reason explains why an infeasible pattern can never match and what could be searched for instead
+func (r patternReport) Reason() string {
+	if len(r.Suggestions) > 0 {
+		return r.reason() + ", try " + orList(r.Suggestions)
+	}
+	return r.reason()
+}
+
+// reason explains why an infeasible pattern can never match
+func (r patternReport) reason() string {
+	switch {
+	case len(r.Invalid) == 1:
+		return fmt.Sprintf("%s never appears in an address, only A-Z and 2-7 do", r.Invalid[0])
+	case len(r.Invalid) > 1:
+		return fmt.Sprintf("%s never appear in an address, only A-Z and 2-7 do", strings.Join(r.Invalid, ", "))
+	case len(r.Pattern) > r.Length:
+		return fmt.Sprintf("it is longer than the %d characters of an %s", r.Length, fieldAddress)
+	case r.End != "":
+		return "it does not fit at the end, " + r.End
+	case r.Field == fieldSeed:
+		return "it does not fit anywhere, a seed starts with S followed by one of A, B, C or D"
+	default:
+		return fmt.Sprintf("it does not fit anywhere, an address starts with %c followed by one of A, B, C or D", r.Lead)
+	}
+}
+
+// attemptsFor is the number of addresses to scan for a chance of at least one match with probability p per address
+func attemptsFor(chance, p float64) float64 {
+	if p >= 1 {
+		return 1
+	}
+	return math.Ceil(math.Log1p(-chance) / math.Log1p(-p))
+}
+
+// formatCount formats a count of addresses with thousands separators, falling back to scientific notation once it no
+// longer fits an int64
+func formatCount(n float64) string {
+	if n >= math.MaxInt64 {
+		return fmt.Sprintf("%.3g", n)
+	}
+	return FormatInt64(int64(n))
+}
+
+// difficulty describes how many addresses it takes to find a match with probability p per address
+func difficulty(p float64) string {
+	return translator.Sprintf("about 1 in %s addresses, 50%% chance after %s and 99%% after %s",
+		formatCount(math.Ceil(1/p)), formatCount(attemptsFor(0.5, p)), formatCount(attemptsFor(0.99, p)))
+}
+
+// patternEstimate is what the --json document of -dry-run reports about one -find pattern
+type patternEstimate struct {
+	Input       string   `json:"input"`                 // the pattern as given on the command line
+	Pattern     string   `json:"pattern"`               // the upper-cased pattern the workers search for
+	Field       string   `json:"field"`                 // what the pattern is searched for in, address or seed
+	Feasible    bool     `json:"feasible"`              // whether any address can contain it
+	Reason      string   `json:"reason,omitempty"`      // why an infeasible pattern can never match
+	Suggestions []string `json:"suggestions,omitempty"` // achievable patterns close to an infeasible one
+	Offsets     int      `json:"offsets"`               // how many offsets inside the address it can start at
+	Checksum    int      `json:"checksum,omitempty"`    // with -position end, how many of its characters fall on the checksum
+	estimate
+}
+
+// estimate is how hard a search is, in the --json document of -dry-run
+type estimate struct {
+	Probability float64 `json:"probability"` // the chance a single address matches
+	Attempts50  float64 `json:"attempts_50"` // addresses to scan for a 50% chance of a match, 0 when it can't match
+	Attempts90  float64 `json:"attempts_90"` // addresses to scan for a 90% chance of a match, 0 when it can't match
+	Attempts99  float64 `json:"attempts_99"` // addresses to scan for a 99% chance of a match, 0 when it can't match
+}
+
+// estimateReport is the --json document of -dry-run
+type estimateReport struct {
+	ExitCode   int               `json:"exit_code"`            // the exit code of the process
+	Patterns   []patternEstimate `json:"patterns,omitempty"`   // every -find pattern
+	Dictionary string            `json:"dictionary,omitempty"` // the -dictionary and how many of its words are searched for
+	Affixes    *affixEstimate    `json:"affixes,omitempty"`    // the -prefix and -suffix every match must have too
+	Warning    string            `json:"warning,omitempty"`    // why -find and -find-seed together are unrealistic
+	Cost       *costEstimate     `json:"cost,omitempty"`       // how long and how much a match takes, with -price or -rate
+	estimate                     // of any pattern along with the -prefix and -suffix, or any word of the -dictionary
+}
+
+// affixEstimate is what the --json document of -dry-run reports about -prefix and -suffix
+type affixEstimate struct {
+	Prefix   string `json:"prefix,omitempty"` // the upper-cased -prefix
+	Suffix   string `json:"suffix,omitempty"` // the upper-cased -suffix
+	Feasible bool   `json:"feasible"`         // whether any address can have both
+	Reason   string `json:"reason,omitempty"` // why no address can
+	estimate        // of an address having both, whatever the -find patterns
+}
+
+// label names the -prefix and -suffix the way they were given, like -prefix GABC -suffix MOON
+func (a *affixEstimate) label() string {
+	labels := make([]string, 0, 2)
+	if a.Prefix != "" {
+		labels = append(labels, "-"+cKeyPrefix+" "+a.Prefix)
+	}
+	if a.Suffix != "" {
+		labels = append(labels, "-"+cKeySuffix+" "+a.Suffix)
+	}
+	return strings.Join(labels, " ")
+}
+
+// newEstimate returns the estimate of a search with probability p per address
+func newEstimate(p float64) estimate {
+	if p <= 0 {
+		return estimate{}
+	}
+	return estimate{Probability: p, Attempts50: attemptsFor(0.5, p), Attempts90: attemptsFor(0.9, p), Attempts99: attemptsFor(0.99, p)}
+}
+
+// runDryRun prints whether each pattern of find can match where options want it and how hard it is to find, along
+// with the -prefix, -suffix and -find-seed and with -price or -rate what a match costs, or the --json document with the
+// same, returning exitInvalidPattern when any of them can never match
+func runDryRun(find string, options matchOptions, pricing pricing) int {
+	doc := estimateReport{Patterns: make([]patternEstimate, 0)}
+	feasible, p := dryRunPatterns(&doc, find, options.lowercase, options.position, options.Field(), options.Layout())
+	combined := options.findSeed != "" && !options.seed
+	if combined { // the seed patterns must match as well, and a seed and its address are independent
+		_, seedP := dryRunPatterns(&doc, options.findSeed, false, options.position, fieldSeed, vanity.SeedLayout)
+		p *= seedP
+	}
+	doc.estimate = newEstimate(p)
+	if options.prefix != "" || options.suffix != "" {
+		doc.Affixes = &affixEstimate{Prefix: strings.ToUpper(options.prefix), Suffix: strings.ToUpper(options.suffix), Feasible: true}
+		if m, err := newMatcher("", matchOptions{lowercase: options.lowercase, position: positionAnywhere, prefix: options.prefix, suffix: options.suffix,
+			keys: options.keys}); err != nil {
+			doc.Affixes.Feasible, doc.Affixes.Reason, doc.ExitCode = false, err.Error(), exitInvalidPattern
+			if !jsonOutput {
+				fmt.Printf("%v\n", err)
+			}
+		} else {
+			doc.Affixes.estimate = newEstimate(m.probability)
+			if !jsonOutput {
+				translator.Printf("%s: %s\n", doc.Affixes.label(), difficulty(m.probability))
+			}
+		}
+		doc.estimate = newEstimate(doc.Probability * doc.Affixes.Probability)
+	}
+	warning, unlikely := unrealistic(doc.Probability)
+	if combined && unlikely {
+		doc.Warning = "-find and -find-seed together: " + warning
+	}
+	if doc.ExitCode == 0 {
+		if m, err := newMatcher(find, options); err == nil {
+			doc.Cost = newCostEstimate(doc.Probability, m, pricing)
+		}
+	}
+	switch {
+	case jsonOutput:
+		if err := printJSON(doc); err != nil {
+			return exitError
+		}
+		return doc.ExitCode
+	case doc.ExitCode != 0:
+	case combined || (doc.Affixes != nil && len(feasible) > 0):
+		if m, err := newMatcher(find, options); err == nil {
+			translator.Printf("%s: %s\n", m.label(), difficulty(m.probability))
+		}
+	case doc.Affixes != nil:
+	case len(feasible) == 0:
+		translator.Printf("no -find pattern given, every address matches\n")
+	case len(feasible) > 1:
+		translator.Printf("any of %s: %s\n", strings.Join(feasible, ", "), difficulty(doc.Probability))
+	}
+	if doc.Warning != "" && doc.ExitCode == 0 {
+		fmt.Printf("%s\n", doc.Warning)
+	}
+	if doc.Cost != nil {
+		doc.Cost.Print()
+	}
+	return doc.ExitCode
+}
+
+// dryRunPatterns prints whether each pattern of find can match field at position and how hard it is to find, adding
+// them to doc; it returns the feasible patterns and the chance of a match of any of them, 1 when find has none
+func dryRunPatterns(doc *estimateReport, find string, lowercase bool, position, field string, layout *vanity.Layout) ([]string, float64) {
+	feasible := make([]string, 0)
+	miss := 1.0 // the chance an address, or seed, matches none of the feasible patterns
+	for _, input := range strings.Split(find, ",") {
+		input = strings.TrimSpace(input)
+		if input == "" {
+			continue
+		}
+		report := inspectField(input, lowercase, position, field, layout)
+		pattern := patternEstimate{Input: input, Pattern: report.Pattern, Field: report.Field, Feasible: report.Feasible(), Offsets: len(report.Offsets),
+			Checksum: report.Checksum, estimate: newEstimate(report.Probability)}
+		if !report.Feasible() {
+			pattern.Reason, pattern.Suggestions = report.reason(), report.Suggestions
+		}
+		doc.Patterns = append(doc.Patterns, pattern)
+		if !report.Feasible() {
+			if !jsonOutput {
+				translator.Printf("%s: impossible, %s\n", input, report.Reason())
+			}
+			doc.ExitCode = exitInvalidPattern
+			continue
+		}
+		if !jsonOutput {
+			if report.Pattern != report.Input {
+				translator.Printf("%s: searched for as %s, addresses are upper-case\n", input, report.Pattern)
+			}
+			translator.Printf("%s: can start at %d of %d offsets, %s\n",
+				report.Pattern, len(report.Offsets), report.Length-len([]rune(report.Pattern))+1, difficulty(report.Probability))
+			if report.Checksum > 0 {
+				translator.Printf("%s: its last %d characters fall on the CRC16 checksum, which depends on every byte of the key and is as random as it\n",
+					report.Pattern, report.Checksum)
+			}
+		}
+		feasible = append(feasible, report.Pattern)
+		miss *= 1 - report.Probability
+	}
+	if len(feasible) == 0 && doc.ExitCode == 0 {
+		return feasible, 1 // an empty -find matches every address
+	}
+	return feasible, 1 - miss
+}
+
+// runDictionaryDryRun prints how many words of the -dictionary at path can match and how hard finding any of them is,
+// with -price or -rate what a match costs, or the --json document with the same, returning exitInvalidPattern when
+// none can
+func runDictionaryDryRun(path string, minWord int, lowercase bool, blocked []string, pricing pricing) int {
+	m, err := newDictionaryMatcher(path, minWord, lowercase, blocked)
+	if err != nil {
+		if jsonOutput {
+			_, _ = fmt.Fprintf(os.Stderr, "%v\n", err)
+			_ = printJSON(estimateReport{ExitCode: exitInvalidPattern})
+		} else {
+			fmt.Printf("%v\n", err)
+		}
+		return exitInvalidPattern
+	}
+	doc := estimateReport{Dictionary: m.dictionary, estimate: newEstimate(m.probability)}
+	if !jsonOutput {
+		translator.Printf("any word of %s: %s\n", m.dictionary, difficulty(m.probability))
+	}
+	doc.Cost = newCostEstimate(m.probability, m, pricing)
+	if jsonOutput {
+		if err := printJSON(doc); err != nil {
+			return exitError
+		}
+		return 0
+	}
+	if doc.Cost != nil {
+		doc.Cost.Print()
+	}
+	return 0
+}
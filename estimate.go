@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"                                                        // used for the warning about unrealistic searches
+	"github.com/andreimerlescu/xlm-vanity-address-finder/vanity" // the alphabet and positions of the search core
+	"math"                                                       // used for the chance of a match after n attempts
+	"strings"                                                    // used for swapping the characters of seed classes and drawing the progress bar
+)
+
+// base32Alphabet is the RFC 4648 alphabet strkey encodes addresses with, so 0, 1, 8 and 9 never appear in an address
+const base32Alphabet = vanity.Alphabet
+
+// addressLength is the length of an encoded G... address
+const addressLength = vanity.AddressLength
+
+// the -position values, where in an address the -find patterns must appear
+const (
+	positionAnywhere = vanity.PositionAnywhere // at any offset
+	positionStart    = vanity.PositionStart    // right after the leading G, or after the G and the A, B, C or D following it
+	positionEnd      = vanity.PositionEnd      // at the very end
+)
+
+// the fields of a key pair the patterns are searched for in, the address unless -find-seed is set
+const (
+	fieldAddress = "address" // the public G... address
+	fieldSeed    = "seed"    // the secret S... seed, with -find-seed
+)
+
+// realisticAttempts is how many key pairs a search may need for a 50% chance of a match before it is called
+// unrealistic, about twelve days at a million key pairs per second
+const realisticAttempts = 1e12
+
+// unrealistic returns a warning when a search with probability p per key pair needs more than realisticAttempts for a
+// 50% chance of a match
+func unrealistic(p float64) (string, bool) {
+	attempts := attemptsFor(0.5, p)
+	if p <= 0 || attempts <= realisticAttempts {
+		return "", false
+	}
+	return fmt.Sprintf("this needs about %s key pairs for a 50%% chance of a match, %s at a million per second; drop or shorten a pattern",
+		formatCount(attempts), formatETA(attempts, 1e6)), true
+}
+
+// cumulativeProbability is 1 − (1−p)^n, the chance of at least one match after n attempts
+func cumulativeProbability(p float64, n int64) float64 {
+	if p >= 1 {
+		return 1
+	}
+	return -math.Expm1(float64(n) * math.Log1p(-p))
+}
+
+// progressBar renders fraction as a bar of width cells, e.g. [######----]
+func progressBar(fraction float64, width int) string {
+	filled := int(math.Round(math.Max(0, math.Min(1, fraction)) * float64(width)))
+	return "[" + strings.Repeat("#", filled) + strings.Repeat("-", width-filled) + "]"
+}
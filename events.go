@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"                       // used for the timeout of every Kafka write
+	"encoding/json"                 // the events are published as JSON
+	"fmt"                           // used for naming the client and wrapping errors
+	"github.com/nats-io/nats.go"    // publishes the events of -events nats://
+	"github.com/segmentio/kafka-go" // publishes the events of -events kafka://
+	"net/url"                       // used for parsing -events
+	"reflect"                       // used for listing the fields -events-redact may name
+	"slices"                        // used for validating -events-redact
+	"strings"                       // used for parsing -events and -events-redact
+	"time"                          // used for the timeouts of the brokers
+)
+
+// eventsTimeout is how long connecting to the -events broker, and every publish, may take
+const eventsTimeout = 10 * time.Second
+
+// eventsSubject is what the events are published under when the -events URL has no path
+const eventsSubject = "xlm-vanity"
+
+// eventSink is a broker the events of -events go to, subject being <subject>.match or <subject>.stats
+type eventSink interface {
+	Publish(subject string, payload []byte) error
+	Close() error
+}
+
+// eventStream publishes every match to <subject>.match and the progress of the search to <subject>.stats, with the
+// fields of -events-redact left out, so pipelines like auto-funders, databases and alerting can subscribe instead of
+// polling the output files
+type eventStream struct {
+	sink    eventSink
+	subject string          // the path of the -events URL, eventsSubject without one
+	redact  map[string]bool // the JSON fields left out of every event
+}
+
+// newEventStream connects to the broker of rawURL, nats://host:4222/subject or kafka://host:9092,host:9093/topic, with
+// redact the comma separated JSON fields to leave out of every event
+func newEventStream(rawURL, redact, hostname string, pid int) (*eventStream, error) {
+	fields, err := parseRedact(redact)
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	subject := strings.Trim(u.Path, "/")
+	if subject == "" {
+		subject = eventsSubject
+	}
+	var sink eventSink
+	switch u.Scheme {
+	case "nats", "tls":
+		sink, err = newNATSSink(rawURL, fmt.Sprintf("xlm-vanity-%s-%d", hostname, pid))
+	case "kafka":
+		sink, err = newKafkaSink(strings.Split(u.Host, ","), fmt.Sprintf("xlm-vanity-%s-%d", hostname, pid))
+	default:
+		return nil, fmt.Errorf("unsupported scheme %q: expected nats://host:4222/subject or kafka://host:9092/topic", u.Scheme)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &eventStream{sink: sink, subject: subject, redact: fields}, nil
+}
+
+// parseRedact returns the fields named by -events-redact, failing on a field no event has
+func parseRedact(redact string) (map[string]bool, error) {
+	known := append(jsonFields(matchEvent{}), jsonFields(statsSnapshot{})...)
+	slices.Sort(known)
+	known = slices.Compact(known) // hostname and attempts are in both
+	fields := make(map[string]bool)
+	for _, field := range strings.Split(redact, ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		if !slices.Contains(known, field) {
+			return nil, fmt.Errorf("invalid -events-redact field %q: expected one of %s", field, strings.Join(known, ", "))
+		}
+		fields[field] = true
+	}
+	return fields, nil
+}
+
+// jsonFields returns the names the fields of the struct v are marshaled under
+func jsonFields(v any) []string {
+	var names []string
+	t := reflect.TypeOf(v)
+	for i := 0; i < t.NumField(); i++ {
+		if name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ","); name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Match publishes r to <subject>.match
+func (s *eventStream) Match(r result) error {
+	return s.publish("match", newMatchEvent(r, !s.redact["seed"]))
+}
+
+// Stats publishes snapshot to <subject>.stats
+func (s *eventStream) Stats(snapshot statsSnapshot) error {
+	return s.publish("stats", snapshot)
+}
+
+// publish sends v as JSON to <subject>.<kind> without the fields of -events-redact
+func (s *eventStream) publish(kind string, v any) error {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if len(s.redact) > 0 {
+		var event map[string]json.RawMessage
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return err
+		}
+		for field := range s.redact {
+			delete(event, field)
+		}
+		if payload, err = json.Marshal(event); err != nil {
+			return err
+		}
+	}
+	return s.sink.Publish(s.subject+"."+kind, payload)
+}
+
+// Close flushes what is still being published and disconnects from the broker
+func (s *eventStream) Close() error {
+	return s.sink.Close()
+}
+
+// natsSink publishes to a NATS server
+type natsSink struct {
+	conn *nats.Conn
+}
+
+// newNATSSink connects to the NATS server at rawURL, with the credentials in its user info if it needs any,
+// reconnecting on its own whenever the connection drops
+func newNATSSink(rawURL, name string) (*natsSink, error) {
+	conn, err := nats.Connect(rawURL, nats.Name(name), nats.Timeout(eventsTimeout), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, err
+	}
+	return &natsSink{conn: conn}, nil
+}
+
+// Publish sends payload to subject, waiting for the server to take it
+func (n *natsSink) Publish(subject string, payload []byte) error {
+	if err := n.conn.Publish(subject, payload); err != nil {
+		return err
+	}
+	return n.conn.FlushTimeout(eventsTimeout)
+}
+
+// Close drains the connection, publishing what is still buffered
+func (n *natsSink) Close() error {
+	return n.conn.Drain()
+}
+
+// kafkaSink produces to Kafka, a topic per subject
+type kafkaSink struct {
+	writer *kafka.Writer
+}
+
+// newKafkaSink checks that one of brokers answers and returns a sink producing to them
+func newKafkaSink(brokers []string, name string) (*kafkaSink, error) {
+	dialer := &kafka.Dialer{ClientID: name, Timeout: eventsTimeout}
+	var conn *kafka.Conn
+	var err error
+	for _, broker := range brokers { // the writer only connects on the first write, fail at the start like nats:// does
+		if conn, err = dialer.Dial("tcp", broker); err == nil {
+			_ = conn.Close()
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	writer := &kafka.Writer{Addr: kafka.TCP(brokers...), Balancer: &kafka.LeastBytes{}, RequiredAcks: kafka.RequireOne,
+		AllowAutoTopicCreation: true, WriteTimeout: eventsTimeout, Transport: &kafka.Transport{ClientID: name}}
+	return &kafkaSink{writer: writer}, nil
+}
+
+// Publish produces payload to the topic subject, waiting for its leader to take it
+func (k *kafkaSink) Publish(subject string, payload []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), eventsTimeout)
+	defer cancel()
+	return k.writer.WriteMessages(ctx, kafka.Message{Topic: subject, Value: payload})
+}
+
+// Close flushes the writer and closes its connections
+func (k *kafkaSink) Close() error {
+	return k.writer.Close()
+}
+
+// redactURL returns rawURL with the password of its user info masked, for the logs
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return u.Redacted()
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"     // used for reporting an unknown -explorer
+	"strings" // used for splitting the -explorer list
+)
+
+// the -explorer values, block explorers with a page for every account and contract of both networks
+const (
+	explorerStellarExpert = "stellar.expert" // https://stellar.expert
+	explorerStellarchain  = "stellarchain"   // https://stellarchain.io
+	explorerNone          = "none"           // no links at all
+)
+
+// explorerURLs are where the pages of the explorers start, per -network, followed by the path of an account and of a
+// contract
+var explorerURLs = map[string]map[string][3]string{
+	explorerStellarExpert: {
+		"public":  {"https://stellar.expert/explorer/public", "/account/", "/contract/"},
+		"testnet": {"https://stellar.expert/explorer/testnet", "/account/", "/contract/"},
+	},
+	explorerStellarchain: {
+		"public":  {"https://stellarchain.io", "/accounts/", "/contracts/"},
+		"testnet": {"https://testnet.stellarchain.io", "/accounts/", "/contracts/"},
+	},
+}
+
+// parseExplorers parses the -explorer list into the explorers to link every match to, in its order; none, or an empty
+// list, links to none
+func parseExplorers(value string) ([]string, error) {
+	var explorers []string
+	for _, name := range strings.Split(value, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch {
+		case name == "" || name == explorerNone:
+		case explorerURLs[name] == nil:
+			return nil, fmt.Errorf("unknown -explorer %q, expected %s, %s or %s", name, explorerStellarExpert, explorerStellarchain, explorerNone)
+		default:
+			explorers = append(explorers, name)
+		}
+	}
+	return explorers, nil
+}
+
+// explorerLinks returns the page of the key of r on every one of explorers for the network n, keyed by explorer: that
+// of the account, which is the address of r unless a -split-key combined it into the strkey, or that of the contract of
+// -key-type contract; a hash-x key is no account and has no page
+func explorerLinks(n stellarNetwork, explorers []string, r result) map[string]string {
+	path, key := 1, r.Address
+	switch r.KeyType {
+	case keyTypeHashX:
+		return nil
+	case keyTypeSplit:
+		key = r.Strkey
+	case keyTypeContract:
+		path, key = 2, r.Strkey
+	}
+	links := make(map[string]string, len(explorers))
+	for _, explorer := range explorers {
+		if urls, ok := explorerURLs[explorer][n.Name]; ok {
+			links[explorer] = urls[0] + urls[path] + key
+		}
+	}
+	if len(links) == 0 {
+		return nil
+	}
+	return links
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"strings"     // used for spelling the near miss
+	"sync/atomic" // the workers offer their near misses concurrently
+	"time"        // used for the elapsed time and the rate
+)
+
+// spinnerFrames are the frames of the -fancy spinner, ASCII so that every terminal and font draws them
+const spinnerFrames = `|/-\`
+
+// fancyEvery is how often -fancy redraws the spinner and its status line
+const fancyEvery = 100 * time.Millisecond
+
+// nearMiss is the closest any worker came to a match: the pattern and how many of its leading characters an address
+// contained at the position
+type nearMiss struct {
+	Pattern string
+	Length  int
+}
+
+// String spells the near miss as the characters found followed by a dot for every one missing, STELL.. for STELLAR
+func (n *nearMiss) String() string {
+	return n.Pattern[:n.Length] + strings.Repeat(".", len(n.Pattern)-n.Length)
+}
+
+// nearMisses keeps the closest near miss of the workers; a worker only offers one closer than any it found before, so
+// it is hardly ever written to
+type nearMisses struct {
+	best atomic.Pointer[nearMiss]
+}
+
+// Offer records that an address contained length characters of pattern, unless another one came at least as close
+func (n *nearMisses) Offer(pattern string, length int) {
+	for {
+		current := n.best.Load()
+		if length == 0 || (current != nil && current.Length >= length) {
+			return
+		}
+		if n.best.CompareAndSwap(current, &nearMiss{Pattern: pattern, Length: length}) {
+			return
+		}
+	}
+}
+
+// Best returns the closest near miss so far, nil before any
+func (n *nearMisses) Best() *nearMiss {
+	return n.best.Load()
+}
+
+// spinner draws the -fancy spinner along with the rate, the elapsed time and the closest near miss, over and over on
+// the status line the -every updates are written to
+type spinner struct {
+	line      *statusLine // the status line shared with the -every updates
+	frame     int         // the spinner frame drawn last
+	started   time.Time   // when the search started
+	last      time.Time   // when the line was drawn last
+	lastTotal int64       // the addresses scanned when the line was drawn last
+	rate      float64     // the smoothed rate drawn last
+}
+
+// newSpinner returns the spinner of a search starting now
+func newSpinner(line *statusLine) *spinner {
+	now := time.Now()
+	return &spinner{line: line, started: now, last: now}
+}
+
+// Draw redraws the line with the next frame, for total addresses scanned and closest, when there is one
+func (s *spinner) Draw(total int64, closest *nearMiss) error {
+	now := time.Now()
+	if interval := now.Sub(s.last); interval > 0 {
+		s.rate = smoothRate(s.rate, float64(total-s.lastTotal)/interval.Seconds(), interval)
+	}
+	s.last, s.lastTotal, s.frame = now, total, (s.frame+1)%len(spinnerFrames)
+	frame, rate, elapsed := spinnerFrames[s.frame:s.frame+1], FormatInt64(int64(s.rate)), now.Sub(s.started).Round(time.Second).String()
+	line := translator.Sprintf("%s %s per second for %s", frame, rate, elapsed)
+	if closest != nil {
+		line = translator.Sprintf("%s %s per second for %s, closest %s", frame, rate, elapsed, closest.String())
+	}
+	return s.line.Write(line, "")
+}
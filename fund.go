@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"                                         // used for wrapping funding errors with the step that failed
+	"github.com/stellar/go/clients/horizonclient" // used for loading the source account and submitting the transaction
+	"github.com/stellar/go/keypair"               // the -fund-from signer
+	"github.com/stellar/go/txnbuild"              // used for building the CreateAccount transaction
+)
+
+// fundTimeout is how many seconds a -fund-from transaction stays valid for submission
+const fundTimeout = 300
+
+// funding records how a found address was activated on the network, stored alongside the result
+type funding struct {
+	Network string `json:"network"`          // the -network the account was funded on
+	Source  string `json:"source"`           // who paid for the account, friendbot or the -fund-from address
+	Amount  string `json:"amount,omitempty"` // the XLM starting balance sent with -fund-from
+	Ledger  int32  `json:"ledger,omitempty"` // the ledger the funding transaction closed in
+	Hash    string `json:"hash,omitempty"`   // the funding transaction hash
+	Error   string `json:"error,omitempty"`  // why funding failed, the result is saved regardless
+}
+
+// fundWithFriendbot asks the Friendbot of n to create and fund address, only test networks have one
+func fundWithFriendbot(n stellarNetwork, address string) *funding {
+	funded := &funding{Network: n.Name, Source: "friendbot"}
+	tx, err := n.Horizon.Fund(address)
+	if err != nil {
+		funded.Error = err.Error()
+		return funded
+	}
+	funded.Ledger = tx.Ledger
+	funded.Hash = tx.Hash
+	return funded
+}
+
+// fundFromSource builds, signs and submits a CreateAccount operation from source that activates address on n with a
+// starting balance of amount XLM
+func fundFromSource(n stellarNetwork, source *keypair.Full, address, amount string) *funding {
+	funded := &funding{Network: n.Name, Source: source.Address(), Amount: amount}
+
+	account, err := n.Horizon.AccountDetail(horizonclient.AccountRequest{AccountID: source.Address()})
+	if err != nil { // the sequence number is loaded for every match, so consecutive fundings never collide
+		funded.Error = fmt.Sprintf("failed to load source account: %v", err)
+		return funded
+	}
+
+	tx, err := txnbuild.NewTransaction(txnbuild.TransactionParams{
+		SourceAccount:        &account,
+		IncrementSequenceNum: true,
+		BaseFee:              txnbuild.MinBaseFee,
+		Preconditions:        txnbuild.Preconditions{TimeBounds: txnbuild.NewTimeout(fundTimeout)},
+		Operations:           []txnbuild.Operation{&txnbuild.CreateAccount{Destination: address, Amount: amount}},
+	})
+	if err != nil {
+		funded.Error = fmt.Sprintf("failed to build transaction: %v", err)
+		return funded
+	}
+	if tx, err = tx.Sign(n.Passphrase, source); err != nil {
+		funded.Error = fmt.Sprintf("failed to sign transaction: %v", err)
+		return funded
+	}
+
+	submitted, err := n.Horizon.SubmitTransaction(tx)
+	if err != nil {
+		funded.Error = fmt.Sprintf("failed to submit transaction: %v", err)
+		return funded
+	}
+	funded.Ledger = submitted.Ledger
+	funded.Hash = submitted.Hash
+	return funded
+}
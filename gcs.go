@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"                       // used for uploading the appended lines
+	"cloud.google.com/go/storage" // the gs:// -output
+	"context"                     // every Cloud Storage call runs with a timeout
+	"errors"                      // used for detecting a missing object on first run
+	"fmt"                         // used for naming the temporary object of an append
+	"io"                          // used for reading the object
+	"time"                        // used for the Cloud Storage call timeout
+)
+
+// gcsScheme prefixes an -output (or -output-template) that appends every result to a Google Cloud Storage object
+const gcsScheme = "gs://"
+
+// gcsTimeout bounds every call to Cloud Storage
+const gcsTimeout = 30 * time.Second
+
+// gcsObject is the object of a gs:// -output. Cloud Storage can't append either, but it composes objects: the lines
+// are uploaded to a temporary object next to it, composed onto its end while its generation is unchanged and deleted.
+type gcsObject struct {
+	bucket     *storage.BucketHandle
+	key        string // the name of the object in bucket
+	generation int64  // generation of the object, 0 until it exists
+}
+
+// openGCSObject returns the object key of bucket with the Application Default Credentials: GOOGLE_APPLICATION_CREDENTIALS,
+// the gcloud login or the service account of the machine; STORAGE_EMULATOR_HOST points it at an emulator
+func openGCSObject(bucket, key string) (*gcsObject, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), gcsTimeout)
+	defer cancel()
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("no Google Cloud credentials: %w", err)
+	}
+	return &gcsObject{bucket: client.Bucket(bucket), key: key}, nil
+}
+
+// Read returns the object, nothing when it doesn't exist yet, and remembers its generation
+func (o *gcsObject) Read() ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), gcsTimeout)
+	defer cancel()
+	object := o.bucket.Object(o.key)
+	if o.generation != 0 {
+		object = object.If(storage.Conditions{GenerationMatch: o.generation})
+	}
+	reader, err := object.NewReader(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) && o.generation == 0 {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = reader.Close() }()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		zeroize(data)
+		return nil, err
+	}
+	o.generation = reader.Attrs.Generation
+	return data, nil
+}
+
+// Append adds lines to the end of the object, creating it when it doesn't exist yet
+func (o *gcsObject) Append(lines []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), gcsTimeout)
+	defer cancel()
+	if o.generation == 0 {
+		attrs, err := o.write(ctx, o.bucket.Object(o.key).If(storage.Conditions{DoesNotExist: true}), lines)
+		if err != nil {
+			return err
+		}
+		o.generation = attrs.Generation
+		return nil
+	}
+
+	part := o.bucket.Object(fmt.Sprintf("%s.append-%d", o.key, time.Now().UnixNano()))
+	if _, err := o.write(ctx, part.If(storage.Conditions{DoesNotExist: true}), lines); err != nil {
+		return err
+	}
+	defer func() { _ = part.Delete(context.Background()) }() // best effort, a lifecycle rule cleans up the rest
+	composer := o.bucket.Object(o.key).If(storage.Conditions{GenerationMatch: o.generation}).ComposerFrom(o.bucket.Object(o.key), part)
+	composer.ContentType = "application/x-ndjson"
+	attrs, err := composer.Run(ctx)
+	if err != nil {
+		return err
+	}
+	o.generation = attrs.Generation
+	return nil
+}
+
+// write uploads data to object
+func (o *gcsObject) write(ctx context.Context, object *storage.ObjectHandle, data []byte) (*storage.ObjectAttrs, error) {
+	writer := object.NewWriter(ctx)
+	writer.ContentType = "application/x-ndjson"
+	if _, err := io.Copy(writer, bytes.NewReader(data)); err != nil {
+		_ = writer.Close()
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return writer.Attrs(), nil
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"                               // used for reporting an unsupported -lang
+	"golang.org/x/text/language"        // used for matching -lang and LANG against the translated languages
+	"golang.org/x/text/message"         // prints the console messages in the -lang
+	"golang.org/x/text/message/catalog" // holds the translations of the console messages
+)
+
+// messageLanguages are the languages the console messages are available in, English is the key of every message
+var messageLanguages = []language.Tag{language.English, language.Spanish, language.German, language.Portuguese}
+
+// messageCatalog translates the console messages: the status line, the found pair banner and the -dry-run summaries;
+// numbers are passed in already formatted by printer, so they follow the -locale whatever the -lang
+var messageCatalog = map[string]map[language.Tag]string{
+	"... scanned %s addresses at %s per second! %s %s chance of a match by now": {
+		language.Spanish:    "... ¡%s direcciones escaneadas a %s por segundo! %s %s de probabilidad de una coincidencia hasta ahora",
+		language.German:     "... %s Adressen durchsucht, %s pro Sekunde! %s %s Wahrscheinlichkeit für einen Treffer bis jetzt",
+		language.Portuguese: "... %s endereços verificados a %s por segundo! %s %s de chance de uma correspondência até agora",
+	},
+	"\n\rExplorer: %s": {
+		language.Spanish:    "\n\rExplorador: %s",
+		language.German:     "\n\rExplorer: %s",
+		language.Portuguese: "\n\rExplorador: %s",
+	},
+	"%s %s per second for %s": {
+		language.Spanish:    "%s %s por segundo durante %s",
+		language.German:     "%s %s pro Sekunde seit %s",
+		language.Portuguese: "%s %s por segundo há %s",
+	},
+	"%s %s per second for %s, closest %s": {
+		language.Spanish:    "%s %s por segundo durante %s, lo más cercano %s",
+		language.German:     "%s %s pro Sekunde seit %s, am nächsten %s",
+		language.Portuguese: "%s %s por segundo há %s, o mais próximo %s",
+	},
+	"\n\rHey, you! A pair was found after %s addresses!!\n\rXLM Wallet: %s\n\rSecret Seed: %s\n\r\n\r": {
+		language.Spanish:    "\n\r¡Oye, tú! ¡¡Se encontró un par después de %s direcciones!!\n\rCartera XLM: %s\n\rSemilla secreta: %s\n\r\n\r",
+		language.German:     "\n\rHey, du! Nach %s Adressen wurde ein Paar gefunden!!\n\rXLM-Wallet: %s\n\rGeheimer Seed: %s\n\r\n\r",
+		language.Portuguese: "\n\rEi, você! Um par foi encontrado depois de %s endereços!!\n\rCarteira XLM: %s\n\rSemente secreta: %s\n\r\n\r",
+	},
+	"\n\rHey, you! A pair was found!!\n\rXLM Wallet: %s\n\rSecret Seed: %s\n\r\n\r": {
+		language.Spanish:    "\n\r¡Oye, tú! ¡¡Se encontró un par!!\n\rCartera XLM: %s\n\rSemilla secreta: %s\n\r\n\r",
+		language.German:     "\n\rHey, du! Ein Paar wurde gefunden!!\n\rXLM-Wallet: %s\n\rGeheimer Seed: %s\n\r\n\r",
+		language.Portuguese: "\n\rEi, você! Um par foi encontrado!!\n\rCarteira XLM: %s\n\rSemente secreta: %s\n\r\n\r",
+	},
+	"(hidden, see the output file)": {
+		language.Spanish:    "(oculta, consulta el archivo de salida)",
+		language.German:     "(verborgen, siehe die Ausgabedatei)",
+		language.Portuguese: "(oculta, veja o arquivo de saída)",
+	},
+	"(held by the owner of the -split-key, who combines it with the tweak in the output file)": {
+		language.Spanish:    "(en manos del dueño de la -split-key, que la combina con el tweak del archivo de salida)",
+		language.German:     "(beim Inhaber des -split-key, der ihn mit dem Tweak aus der Ausgabedatei kombiniert)",
+		language.Portuguese: "(com o dono da -split-key, que a combina com o tweak do arquivo de saída)",
+	},
+	"Saved %s addresses to %s\n": {
+		language.Spanish:    "Se guardaron %s direcciones en %s\n",
+		language.German:     "%s Adressen in %s gespeichert\n",
+		language.Portuguese: "%s endereços salvos em %s\n",
+	},
+	"Finished running!": {
+		language.Spanish:    "¡Búsqueda terminada!",
+		language.German:     "Suche beendet!",
+		language.Portuguese: "Busca concluída!",
+	},
+	"Timer reached limit.": {
+		language.Spanish:    "El temporizador alcanzó el límite.",
+		language.German:     "Der Timer hat das Limit erreicht.",
+		language.Portuguese: "O temporizador atingiu o limite.",
+	},
+	"Watchdog received termination request. Exiting...": {
+		language.Spanish:    "El vigilante recibió una solicitud de terminación. Saliendo...",
+		language.German:     "Der Watchdog hat eine Aufforderung zum Beenden erhalten. Wird beendet...",
+		language.Portuguese: "O watchdog recebeu um pedido de encerramento. Saindo...",
+	},
+	"Service stop requested. Exiting...": {
+		language.Spanish:    "Se solicitó detener el servicio. Saliendo...",
+		language.German:     "Der Dienst soll beendet werden. Wird beendet...",
+		language.Portuguese: "Parada do serviço solicitada. Saindo...",
+	},
+	"about 1 in %s addresses, 50%% chance after %s and 99%% after %s": {
+		language.Spanish:    "aproximadamente 1 de cada %s direcciones, 50%% de probabilidad tras %s y 99%% tras %s",
+		language.German:     "etwa 1 von %s Adressen, 50%% Wahrscheinlichkeit nach %s und 99%% nach %s",
+		language.Portuguese: "cerca de 1 em cada %s endereços, 50%% de chance após %s e 99%% após %s",
+	},
+	"%s: can start at %d of %d offsets, %s\n": {
+		language.Spanish:    "%s: puede empezar en %d de %d posiciones, %s\n",
+		language.German:     "%s: kann an %d von %d Positionen beginnen, %s\n",
+		language.Portuguese: "%s: pode começar em %d de %d posições, %s\n",
+	},
+	"%s: its last %d characters fall on the CRC16 checksum, which depends on every byte of the key and is as random as it\n": {
+		language.Spanish:    "%s: sus últimos %d caracteres caen en la suma de verificación CRC16, que depende de cada byte de la clave y es tan aleatoria como ella\n",
+		language.German:     "%s: seine letzten %d Zeichen fallen auf die CRC16-Prüfsumme, die von jedem Byte des Schlüssels abhängt und so zufällig ist wie er\n",
+		language.Portuguese: "%s: seus últimos %d caracteres caem na soma de verificação CRC16, que depende de cada byte da chave e é tão aleatória quanto ela\n",
+	},
+	"%s: searched for as %s, addresses are upper-case\n": {
+		language.Spanish:    "%s: se busca como %s, las direcciones están en mayúsculas\n",
+		language.German:     "%s: wird als %s gesucht, Adressen bestehen aus Großbuchstaben\n",
+		language.Portuguese: "%s: buscado como %s, os endereços são maiúsculos\n",
+	},
+	"%s: impossible, %s\n": {
+		language.Spanish:    "%s: imposible, %s\n",
+		language.German:     "%s: unmöglich, %s\n",
+		language.Portuguese: "%s: impossível, %s\n",
+	},
+	"no -find pattern given, every address matches\n": {
+		language.Spanish:    "no se indicó ningún patrón -find, todas las direcciones coinciden\n",
+		language.German:     "kein -find-Muster angegeben, jede Adresse passt\n",
+		language.Portuguese: "nenhum padrão -find informado, todos os endereços correspondem\n",
+	},
+	"any of %s: %s\n": {
+		language.Spanish:    "cualquiera de %s: %s\n",
+		language.German:     "eines von %s: %s\n",
+		language.Portuguese: "qualquer um de %s: %s\n",
+	},
+	"any word of %s: %s\n": {
+		language.Spanish:    "cualquier palabra de %s: %s\n",
+		language.German:     "ein beliebiges Wort aus %s: %s\n",
+		language.Portuguese: "qualquer palavra de %s: %s\n",
+	},
+	"measuring the addresses per second of -cores %d for %s, set -rate to skip this\n": {
+		language.Spanish:    "midiendo las direcciones por segundo de -cores %d durante %s, indica -rate para omitirlo\n",
+		language.German:     "messe die Adressen pro Sekunde von -cores %d für %s, -rate überspringt das\n",
+		language.Portuguese: "medindo os endereços por segundo de -cores %d por %s, informe -rate para pular isso\n",
+	},
+	"at %s addresses per second measured on this machine:\n": {
+		language.Spanish:    "a %s direcciones por segundo medidas en esta máquina:\n",
+		language.German:     "bei %s Adressen pro Sekunde, gemessen auf dieser Maschine:\n",
+		language.Portuguese: "a %s endereços por segundo medidos nesta máquina:\n",
+	},
+	"at %s addresses per second:\n": {
+		language.Spanish:    "a %s direcciones por segundo:\n",
+		language.German:     "bei %s Adressen pro Sekunde:\n",
+		language.Portuguese: "a %s endereços por segundo:\n",
+	},
+	"  %s chance of a match after %s\n": {
+		language.Spanish:    "  %s de probabilidad de una coincidencia tras %s\n",
+		language.German:     "  %s Wahrscheinlichkeit für einen Treffer nach %s\n",
+		language.Portuguese: "  %s de chance de uma correspondência após %s\n",
+	},
+	"  %s chance of a match after %s, %s at %s an hour\n": {
+		language.Spanish:    "  %s de probabilidad de una coincidencia tras %s, %s a %s por hora\n",
+		language.German:     "  %s Wahrscheinlichkeit für einen Treffer nach %s, %s bei %s pro Stunde\n",
+		language.Portuguese: "  %s de chance de uma correspondência após %s, %s a %s por hora\n",
+	},
+}
+
+// translations is messageCatalog compiled for the translator, messages missing in a language are printed in English
+var translations = func() *catalog.Builder {
+	builder := catalog.NewBuilder(catalog.Fallback(language.English))
+	for key, translated := range messageCatalog {
+		for tag, text := range translated {
+			_ = builder.SetString(tag, key, text) // plain strings always compile
+		}
+	}
+	return builder
+}()
+
+// translator prints the console messages in the language of the environment (LANGUAGE, LC_ALL, LC_MESSAGES and then
+// LANG) until -lang says otherwise; languages without a translation get English
+var translator = message.NewPrinter(environmentLocale("LANGUAGE", "LC_ALL", "LC_MESSAGES", "LANG"), message.Catalog(translations))
+
+// setLang makes translator print the console messages in the -lang value, the environment decides when it is empty
+func setLang(lang string) error {
+	if lang == "" {
+		return nil
+	}
+	tag, err := parseLocale(lang)
+	if _, _, confidence := language.NewMatcher(messageLanguages).Match(tag); err != nil || confidence == language.No {
+		return fmt.Errorf("unsupported -lang %q: expected en, es, de or pt", lang)
+	}
+	translator = message.NewPrinter(tag, message.Catalog(translations))
+	return nil
+}
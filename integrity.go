@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"         // used for finding the records of a damaged output file
+	"crypto/sha256" // used for the checksum of the -output file
+	"encoding/hex"  // the checksum is written as hex, like sha256sum does
+	"encoding/json" // used for decoding the records of a damaged output file
+	"errors"        // used for telling a missing checksum from an unreadable one
+	"fmt"           // used for wrapping errors with the path involved
+	"os"            // access the filesystem
+	"path/filepath" // the checksum names the file it covers without its directory
+	"strings"       // used for reading the checksum file
+)
+
+// errCorruptOutput is wrapped by readOutput when the -output file doesn't decode or doesn't match its checksum
+var errCorruptOutput = errors.New("corrupt results file")
+
+// checksumPath is where the SHA-256 of the -output file is kept, in the format of sha256sum so `sha256sum -c` checks it
+func checksumPath(path string) string {
+	return path + ".sha256"
+}
+
+// checksumOf returns the hex SHA-256 of data
+func checksumOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// writeChecksum replaces the checksum of path with the one of data through a temp file and rename
+func writeChecksum(path string, data []byte) error {
+	sumPath := checksumPath(path)
+	tmpPath := sumPath + ".tmp"
+	line := checksumOf(data) + "  " + filepath.Base(path) + "\n"
+	if err := os.WriteFile(tmpPath, []byte(line), 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", tmpPath, err)
+	}
+	if err := os.Rename(tmpPath, sumPath); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", sumPath, err)
+	}
+	return nil
+}
+
+// checkChecksum compares data, the contents of path, with the checksum written by the last compaction; a file without
+// a checksum, from an older version or never compacted, passes
+func checkChecksum(path string, data []byte) error {
+	line, err := os.ReadFile(checksumPath(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", checksumPath(path), err)
+	}
+	fields := strings.Fields(string(line))
+	if len(fields) == 0 {
+		return fmt.Errorf("%s is empty", checksumPath(path))
+	}
+	if want, got := fields[0], checksumOf(data); !strings.EqualFold(want, got) {
+		return fmt.Errorf("its SHA-256 is %s, %s expects %s", got, checksumPath(path), want)
+	}
+	return nil
+}
+
+// salvageResults returns every result of a damaged output file that can still be decoded and still derives from its
+// seed, without duplicates, and how many of its records were lost; after a malformed record decoding picks up again at
+// the next object, so damage in the middle of the file only costs the records it touches
+func salvageResults(data []byte) ([]result, int) {
+	results := make([]result, 0)
+	seen := make(map[string]struct{})
+	for offset := 0; offset < len(data); {
+		start := bytes.IndexByte(data[offset:], '{')
+		if start < 0 {
+			break
+		}
+		start += offset
+		decoder := json.NewDecoder(bytes.NewReader(data[start:]))
+		var r result
+		if err := decoder.Decode(&r); err != nil || r.Address == "" {
+			offset = start + 1 // not a result, or a damaged one: try the next object, which may be nested in it
+			continue
+		}
+		offset = start + int(decoder.InputOffset())
+		if _, duplicate := seen[r.Address]; duplicate {
+			continue
+		}
+		if entry := verifyResult("", r); entry.Err != nil {
+			debugf("dropped %s from the damaged results: %v", r.Address, entry.Err)
+			continue
+		}
+		seen[r.Address] = struct{}{}
+		results = append(results, r)
+	}
+	lost := max(0, bytes.Count(data, []byte(`{"address":`))-len(results)) // every record starts so, damaged or not
+	return results, lost
+}
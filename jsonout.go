@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json" // every --json document is JSON
+	"os"            // the documents go to STDOUT
+	"time"          // used for the elapsed time of a search
+)
+
+// jsonOutput is set by the global --json flag, in front of any subcommand, or by -json: every command then prints a
+// single JSON document on STDOUT instead of its text, while logs and errors stay on STDERR
+var jsonOutput bool
+
+// globalJSONFlags are the spellings of the global --json flag stripped from the front of the arguments
+var globalJSONFlags = []string{"--json", "-json", "--json=true", "-json=true"}
+
+// stripGlobalFlags removes the global --json flag from the front of args, so `--json verify results.json` reaches
+// verify, and reports whether it was there
+func stripGlobalFlags(args []string) ([]string, bool) {
+	found := false
+	for len(args) > 0 {
+		matched := false
+		for _, spelling := range globalJSONFlags {
+			if args[0] == spelling {
+				matched = true
+			}
+		}
+		if !matched {
+			break
+		}
+		args, found = args[1:], true
+	}
+	return args, found
+}
+
+// printJSON writes v to STDOUT as one indented JSON document
+func printJSON(v any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// findReport is the --json document of a search, printed once it ended
+type findReport struct {
+	ExitCode       int         `json:"exit_code"`              // the exit code of the process
+	Attempts       int64       `json:"attempts"`               // addresses scanned, 0 when nothing counted
+	ElapsedSeconds float64     `json:"elapsed_seconds"`        // how long the search ran
+	Matches        []result    `json:"matches"`                // the matches saved by this run, seeds left out with -no-seed-stdout
+	TimeToFind     *timeToFind `json:"time_to_find,omitempty"` // the addresses between two matches, with enough of them
+
+	started  time.Time    // when the search started
+	attempts func() int64 // the counters of the workers, once they exist
+}
+
+// newFindReport returns the report of a search starting now
+func newFindReport() *findReport {
+	return &findReport{Matches: make([]result, 0), started: time.Now()}
+}
+
+// Add records a saved match, without its seed and mnemonic when hideSeeds is set
+func (f *findReport) Add(r result, hideSeeds bool) {
+	if hideSeeds {
+		r.Seed, r.Mnemonic = "", ""
+	}
+	f.Matches = append(f.Matches, r)
+}
+
+// Print writes the report of a search that ended with code
+func (f *findReport) Print(code int) error {
+	f.ExitCode, f.ElapsedSeconds = code, time.Since(f.started).Seconds()
+	if f.attempts != nil {
+		f.Attempts = f.attempts()
+	}
+	return printJSON(f)
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"                 // used for the index of stored addresses
+	"errors"                        // used for telling a missing index or key apart
+	"flag"                          // the keys subcommand parses its own arguments
+	"fmt"                           // used for printing keys and wrapping errors
+	"github.com/zalando/go-keyring" // macOS Keychain, Secret Service (GNOME Keyring, KWallet) and Windows Credential Manager
+	"os"                            // access the filesystem
+	"path/filepath"                 // used for locating the index in the user config directory
+	"slices"                        // used for keeping the index sorted and unique
+)
+
+// keyringService is the service every seed is stored under in the OS keyring, with the address as the user
+const keyringService = "xlm-vanity-address-finder"
+
+// keyringScheme prefixes the seed_ref of a result whose seed is in the OS keyring
+const keyringScheme = "keyring://"
+
+// keyringBackend stores every seed in the OS keyring for -store keyring; keyrings can't be enumerated portably, so the
+// addresses (never the seeds) are also listed in keyring.json in the user config directory for `keys list`
+type keyringBackend struct{}
+
+// openKeyring checks that the OS keyring is reachable, a missing Secret Service must fail now and not at the first match
+func openKeyring() (*keyringBackend, error) {
+	if _, err := keyring.Get(keyringService, "probe"); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return nil, fmt.Errorf("-store keyring: the OS keyring is not available: %w", err)
+	}
+	return &keyringBackend{}, nil
+}
+
+// Store puts the seed of r in the OS keyring under its address and leaves the keyring:// reference instead
+func (k *keyringBackend) Store(r *result) error {
+	if err := keyring.Set(keyringService, r.Address, r.Seed); err != nil {
+		return fmt.Errorf("failed to store %s in the OS keyring: %w", r.Address, err)
+	}
+	addresses, err := keyringAddresses()
+	if err != nil {
+		return err
+	}
+	if _, found := slices.BinarySearch(addresses, r.Address); !found {
+		if err := writeKeyringIndex(append(addresses, r.Address)); err != nil {
+			return err
+		}
+	}
+	r.SeedRef, r.Seed = keyringScheme+keyringService+"/"+r.Address, ""
+	return nil
+}
+
+// keyringIndexPath is where the addresses stored in the OS keyring are listed
+func keyringIndexPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, keyringService, "keyring.json"), nil
+}
+
+// keyringAddresses returns the sorted addresses stored in the OS keyring
+func keyringAddresses() ([]string, error) {
+	path, err := keyringIndexPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	addresses := make([]string, 0)
+	if err := json.Unmarshal(data, &addresses); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	slices.Sort(addresses)
+	return slices.Compact(addresses), nil
+}
+
+// writeKeyringIndex replaces the list of addresses stored in the OS keyring
+func writeKeyringIndex(addresses []string) error {
+	path, err := keyringIndexPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	slices.Sort(addresses)
+	data, err := json.MarshalIndent(slices.Compact(addresses), "", "  ")
+	if err != nil {
+		return err
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, append(data, '\n'), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// runKeys implements `xlm-vanity-address-finder keys list` and `keys show <address>` for seeds stored with
+// -store keyring; it returns the process exit code
+func runKeys(args []string) int {
+	flags := flag.NewFlagSet("keys", flag.ExitOnError)
+	auditPath := flags.String("audit-log", defaultAuditLog, "Audit log of a -compliance run every exported seed is recorded in, when it exists")
+	flags.Usage = func() {
+		_, _ = fmt.Fprintf(flags.Output(), "Usage: %s keys list | keys show <address>\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args) // ExitOnError handles the error
+
+	switch {
+	case flags.NArg() == 1 && flags.Arg(0) == "list":
+		addresses, err := keyringAddresses()
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "%v\n", err)
+			return 1
+		}
+		for _, address := range addresses {
+			fmt.Println(address)
+		}
+		return 0
+	case flags.NArg() == 2 && flags.Arg(0) == "show":
+		address := flags.Arg(1)
+		seed, err := keyring.Get(keyringService, address)
+		if err == nil {
+			err = verifySeed("keyring", address, seed).Err
+		}
+		if err == nil {
+			err = auditExport(*auditPath, "keys show", address)
+		}
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "%s: %v\n", address, err)
+			return 1
+		}
+		fmt.Println(seed)
+		return 0
+	default:
+		flags.Usage()
+		return 2
+	}
+}
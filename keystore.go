@@ -0,0 +1,354 @@
+package main
+
+import (
+	"bufio"                              // used for reading the -password-file
+	"crypto/rand"                        // the salt and the nonce of every keystore
+	"encoding/base64"                    // the keystore fields are base64
+	"encoding/json"                      // a keystore file is JSON
+	"errors"                             // used for reporting a wrong password
+	"flag"                               // the export subcommand parses its own arguments
+	"fmt"                                // used for writing the report
+	"github.com/stellar/go/keypair"      // used for checking the seed of a keystore against its address
+	"golang.org/x/crypto/nacl/secretbox" // the seed is sealed with XSalsa20-Poly1305
+	"golang.org/x/crypto/scrypt"         // the key is derived from the password with scrypt
+	"golang.org/x/term"                  // used for asking for the password without echoing it
+	"os"                                 // access the filesystem
+	"path/filepath"                      // used for naming the keystore files
+	"strings"                            // used for trimming the -password-file
+)
+
+// the parameters of every keystore, scrypt at N=2^15 costs about 32 MiB and a tenth of a second per guess
+const (
+	keystoreVersion = 1
+	keystoreCipher  = "xsalsa20-poly1305"
+	keystoreKDF     = "scrypt"
+	keystoreN       = 1 << 15
+	keystoreR       = 8
+	keystoreP       = 1
+	keystoreKeyLen  = 32
+	keystoreSaltLen = 32
+)
+
+// minKeystorePassword is the shortest password a keystore is encrypted with
+const minKeystorePassword = 8
+
+// keystore is an encrypted backup of the seed of one address, sealed with a key scrypt derives from a password. It is
+// a format of this tool and no wallet imports it: Stellar has no standard encrypted keystore, and Freighter, Albedo and
+// xBull only import a seed or a recovery phrase, so `export open` is what opens it again. The file still names its
+// cipher and its key derivation along with their parameters, so any NaCl and scrypt library can open it.
+type keystore struct {
+	Version int            `json:"version"`
+	Address string         `json:"address"` // the public key, readable without the password
+	Crypto  keystoreCrypto `json:"crypto"`
+}
+
+// keystoreCrypto is how the seed of a keystore is sealed
+type keystoreCrypto struct {
+	Cipher       string `json:"cipher"`     // always xsalsa20-poly1305, NaCl secretbox
+	Ciphertext   string `json:"ciphertext"` // base64 of the sealed S... seed
+	CipherParams struct {
+		Nonce string `json:"nonce"` // base64 of the 24 byte nonce
+	} `json:"cipherparams"`
+	KDF       string `json:"kdf"` // always scrypt
+	KDFParams struct {
+		N     int    `json:"n"`
+		R     int    `json:"r"`
+		P     int    `json:"p"`
+		DKLen int    `json:"dklen"`
+		Salt  string `json:"salt"` // base64 of the 32 byte salt
+	} `json:"kdfparams"`
+}
+
+// sealKeystore returns the keystore of seed, sealed with a key derived from password with a random salt and nonce
+func sealKeystore(seed, password string) (*keystore, error) {
+	salt := make([]byte, keystoreSaltLen)
+	var nonce [24]byte
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return nil, err
+	}
+	return sealKeystoreWith(seed, password, salt, &nonce)
+}
+
+// sealKeystoreWith is sealKeystore with the salt and the nonce given, which must never be used twice
+func sealKeystoreWith(seed, password string, salt []byte, nonce *[24]byte) (*keystore, error) {
+	pair, err := keypair.ParseFull(seed)
+	if err != nil {
+		return nil, fmt.Errorf("invalid seed: %w", err)
+	}
+	k := &keystore{Version: keystoreVersion, Address: pair.Address()}
+	key, err := keystoreKey(password, salt, keystoreN, keystoreR, keystoreP)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroize(key[:])
+	plaintext := []byte(seed)
+	defer zeroize(plaintext)
+	c := &k.Crypto
+	c.Cipher, c.KDF = keystoreCipher, keystoreKDF
+	c.Ciphertext = base64.StdEncoding.EncodeToString(secretbox.Seal(nil, plaintext, nonce, key))
+	c.CipherParams.Nonce = base64.StdEncoding.EncodeToString(nonce[:])
+	c.KDFParams.N, c.KDFParams.R, c.KDFParams.P, c.KDFParams.DKLen = keystoreN, keystoreR, keystoreP, keystoreKeyLen
+	c.KDFParams.Salt = base64.StdEncoding.EncodeToString(salt)
+	return k, nil
+}
+
+// Open returns the seed k holds, checked against its address; a wrong password fails to open the seal
+func (k *keystore) Open(password string) (string, error) {
+	c := k.Crypto
+	if k.Version != keystoreVersion || c.Cipher != keystoreCipher || c.KDF != keystoreKDF || c.KDFParams.DKLen != keystoreKeyLen {
+		return "", fmt.Errorf("unsupported keystore: version %d, %s and %s", k.Version, c.Cipher, c.KDF)
+	}
+	salt, saltErr := base64.StdEncoding.DecodeString(c.KDFParams.Salt)
+	nonce, nonceErr := base64.StdEncoding.DecodeString(c.CipherParams.Nonce)
+	sealed, sealedErr := base64.StdEncoding.DecodeString(c.Ciphertext)
+	if err := errors.Join(saltErr, nonceErr, sealedErr); err != nil || len(nonce) != 24 {
+		return "", fmt.Errorf("corrupt keystore of %s", k.Address)
+	}
+	key, err := keystoreKey(password, salt, c.KDFParams.N, c.KDFParams.R, c.KDFParams.P)
+	if err != nil {
+		return "", err
+	}
+	defer zeroize(key[:])
+	plaintext, ok := secretbox.Open(nil, sealed, (*[24]byte)(nonce), key)
+	if !ok {
+		return "", fmt.Errorf("wrong password for the keystore of %s", k.Address)
+	}
+	seed := string(plaintext)
+	zeroize(plaintext)
+	if err := verifySeed("keystore", k.Address, seed).Err; err != nil {
+		return "", err
+	}
+	return seed, nil
+}
+
+// keystoreKey derives the secretbox key of a keystore from password
+func keystoreKey(password string, salt []byte, n, r, p int) (*[keystoreKeyLen]byte, error) {
+	derived, err := scrypt.Key([]byte(password), salt, n, r, p, keystoreKeyLen)
+	if err != nil {
+		return nil, err
+	}
+	return (*[keystoreKeyLen]byte)(derived), nil
+}
+
+// keystorePassword returns the password of the keystores: the first line of passwordFile, or what is typed on the
+// terminal without being echoed when it is empty, twice when confirm is set
+func keystorePassword(passwordFile string, confirm bool) (string, error) {
+	var password string
+	if passwordFile != "" {
+		file, err := os.Open(passwordFile)
+		if err != nil {
+			return "", err
+		}
+		defer func() { _ = file.Close() }()
+		line, err := bufio.NewReader(file).ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("%s: no password on its first line", passwordFile)
+		}
+		password = strings.TrimRight(line, "\r\n")
+	} else {
+		fd := int(os.Stdin.Fd())
+		if !term.IsTerminal(fd) {
+			return "", errors.New("STDIN is not a terminal to ask for the password on, set -password-file")
+		}
+		prompts := []string{"Keystore password: ", "Repeat the password: "}
+		if !confirm {
+			prompts = prompts[:1]
+		}
+		typed := make([][]byte, len(prompts))
+		for i, prompt := range prompts {
+			_, _ = fmt.Fprint(os.Stderr, prompt)
+			var err error
+			typed[i], err = term.ReadPassword(fd)
+			_, _ = fmt.Fprintln(os.Stderr)
+			if err != nil {
+				return "", err
+			}
+		}
+		if confirm && string(typed[0]) != string(typed[1]) {
+			return "", errors.New("the passwords don't match")
+		}
+		password = string(typed[0])
+		for _, t := range typed {
+			zeroize(t)
+		}
+	}
+	if confirm && len([]rune(password)) < minKeystorePassword { // opening only needs the password it was sealed with
+		return "", fmt.Errorf("the password must be at least %d characters", minKeystorePassword)
+	}
+	return password, nil
+}
+
+// runExport implements `xlm-vanity-address-finder export keystore <results.json>...`, writing every seed of the
+// results files into an encrypted <address>.keystore.json, and `export open` reading them back; it returns the process
+// exit code
+func runExport(args []string) int {
+	if len(args) > 0 && args[0] == "open" {
+		return runExportOpen(args[1:])
+	}
+	flags := flag.NewFlagSet("export keystore", flag.ExitOnError)
+	dir := flags.String("dir", ".", "Directory to write the <address>.keystore.json files to")
+	only := flags.String("address", "", "Only export the result of this address")
+	passwordFile := flags.String("password-file", "", "File whose first line is the password, instead of asking for it on the terminal")
+	auditPath := flags.String("audit-log", defaultAuditLog, "Audit log of a -compliance run every exported seed is recorded in, when it exists")
+	flags.Usage = func() {
+		_, _ = fmt.Fprintf(flags.Output(), "Usage: %s export keystore [flags] <results.json>...\n       %[1]s export open [flags] <address.keystore.json>...\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	if len(args) == 0 || args[0] != "keystore" {
+		flags.Usage()
+		return 2
+	}
+	_ = flags.Parse(args[1:]) // ExitOnError handles the error
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	password, err := keystorePassword(*passwordFile, true)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	if err := os.MkdirAll(*dir, 0700); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	code, exported := 0, 0
+	for _, path := range flags.Args() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "%v\n", err)
+			return 1
+		}
+		doc, err := decodeDocument(data)
+		zeroize(data)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "%s: failed to decode: %v\n", path, err)
+			return 1
+		}
+		for _, r := range doc.Results {
+			if *only != "" && r.Address != *only {
+				continue
+			}
+			if r.Seed == "" { // sealed, split or stored elsewhere, its own command gets it back first
+				_, _ = fmt.Fprintf(os.Stderr, "%s %s: the seed isn't in the results file, recover it with kms, keys or shamir first\n", path, r.Address)
+				code = 1
+				continue
+			}
+			target, err := exportKeystore(*dir, r, password)
+			if err == nil {
+				err = auditExport(*auditPath, "export keystore "+path, r.Address)
+			}
+			if err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "%s %s: %v\n", path, r.Address, err)
+				code = 1
+				continue
+			}
+			fmt.Printf("%s\t%s\n", r.Address, target)
+			exported++
+		}
+	}
+	if exported == 0 && code == 0 {
+		_, _ = fmt.Fprintf(os.Stderr, "no results to export\n")
+		return 1
+	}
+	return code
+}
+
+// exportKeystore writes the keystore of the seed of r to dir, refusing to overwrite one, and returns its path; the file
+// is opened again with password before it is reported, so a keystore that wouldn't import is never left behind
+func exportKeystore(dir string, r result, password string) (string, error) {
+	if err := verifySeed("results", r.Address, r.Seed).Err; err != nil {
+		return "", err
+	}
+	k, err := sealKeystore(r.Seed, password)
+	if err != nil {
+		return "", err
+	}
+	if _, err := k.Open(password); err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(k, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, r.Address+".keystore.json")
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return "", err
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		_ = file.Close()
+		return "", err
+	}
+	return path, file.Close()
+}
+
+// runExportOpen implements `xlm-vanity-address-finder export open <address.keystore.json>...`, printing address<TAB>seed
+// for every keystore once its seed is checked against its address, or copying the seed of a single keystore to the
+// clipboard with -clipboard; it returns the process exit code
+func runExportOpen(args []string) int {
+	flags := flag.NewFlagSet("export open", flag.ExitOnError)
+	passwordFile := flags.String("password-file", "", "File whose first line is the password, instead of asking for it on the terminal")
+	toClipboard := flags.Bool("clipboard", false, "Copy the seed to the clipboard instead of printing it, with a single keystore")
+	auditPath := flags.String("audit-log", defaultAuditLog, "Audit log of a -compliance run every exported seed is recorded in, when it exists")
+	flags.Usage = func() {
+		_, _ = fmt.Fprintf(flags.Output(), "Usage: %s export open [flags] <address.keystore.json>...\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args) // ExitOnError handles the error
+	if flags.NArg() == 0 || (*toClipboard && flags.NArg() != 1) {
+		flags.Usage()
+		return 2
+	}
+	var board *clipboard
+	if *toClipboard {
+		var err error
+		if board, err = newClipboard(true); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "-clipboard: %v\n", err)
+			return 1
+		}
+	}
+
+	password, err := keystorePassword(*passwordFile, false)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	code := 0
+	for _, path := range flags.Args() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "%v\n", err)
+			code = 1
+			continue
+		}
+		var k keystore
+		if err := json.Unmarshal(data, &k); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "%s: not a keystore: %v\n", path, err)
+			code = 1
+			continue
+		}
+		seed, err := k.Open(password)
+		if err == nil {
+			err = auditExport(*auditPath, "export open "+path, k.Address)
+		}
+		if err == nil && board != nil {
+			err = board.Copy(result{Address: k.Address, Seed: seed})
+		}
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			code = 1
+			continue
+		}
+		if board != nil {
+			fmt.Printf("%s\tcopied to the clipboard\n", k.Address)
+			continue
+		}
+		fmt.Printf("%s\t%s\n", k.Address, seed)
+	}
+	return code
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/hex"  // the expected key of the known vector
+	"encoding/json" // a keystore file is JSON
+	"os"            // used for writing and reading the keystore files
+	"path/filepath" // used for placing them in the test directory
+	"testing"       // the test harness
+	"time"          // the found_at of the exported result
+)
+
+// the seed, address and password of keystoreVector
+const (
+	keystoreVectorSeed     = "SDJHRQF4GCMIIKAAAQ6IHY42X73FQFLHUULAPSKKD4DFDM7UXWWCRHBE"
+	keystoreVectorAddress  = "GCZHXL5HXQX5ABDM26LHYRCQZ5OJFHLOPLZX47WEBP3V2PF5AVFK2A5D"
+	keystoreVectorPassword = "correct horse battery staple"
+)
+
+// keystoreVector is the keystore of keystoreVectorSeed sealed with the salt 0 to 31 and the nonce 100 to 123; every
+// version must keep opening it, and sealing with the same salt and nonce must keep writing it
+const keystoreVector = `{
+  "version": 1,
+  "address": "GCZHXL5HXQX5ABDM26LHYRCQZ5OJFHLOPLZX47WEBP3V2PF5AVFK2A5D",
+  "crypto": {
+    "cipher": "xsalsa20-poly1305",
+    "ciphertext": "TiOFnLCvGQRqC9jZEMFgRDjWg1lyqL6pm44Z9p/m/L3Cv76g4myrVDurtLiE86oIsoJQR+cLrKv0Nyw66Y0aSADqYpMcN91i",
+    "cipherparams": {
+      "nonce": "ZGVmZ2hpamtsbW5vcHFyc3R1dnd4eXp7"
+    },
+    "kdf": "scrypt",
+    "kdfparams": {
+      "n": 32768,
+      "r": 8,
+      "p": 1,
+      "dklen": 32,
+      "salt": "AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8="
+    }
+  }
+}`
+
+// keystoreVectorKey is the scrypt key of keystoreVector, as Python's hashlib.scrypt derives it
+const keystoreVectorKey = "450fa69545f7a2062c718965069c38be27c1789f5e8cf9b00acb95fdcc54c43d"
+
+// keystoreVectorSaltNonce returns the salt and the nonce keystoreVector was sealed with
+func keystoreVectorSaltNonce() ([]byte, *[24]byte) {
+	salt := make([]byte, keystoreSaltLen)
+	for i := range salt {
+		salt[i] = byte(i)
+	}
+	var nonce [24]byte
+	for i := range nonce {
+		nonce[i] = byte(100 + i)
+	}
+	return salt, &nonce
+}
+
+// TestKeystoreKnownVector opens keystoreVector, checks its key against one derived outside Go, and seals the seed
+// again into the very same file
+func TestKeystoreKnownVector(t *testing.T) {
+	var k keystore
+	if err := json.Unmarshal([]byte(keystoreVector), &k); err != nil {
+		t.Fatal(err)
+	}
+	seed, err := k.Open(keystoreVectorPassword)
+	if err != nil || seed != keystoreVectorSeed {
+		t.Fatalf("keystoreVector opened to %q, %v, want its seed", seed, err)
+	}
+
+	salt, nonce := keystoreVectorSaltNonce()
+	key, err := keystoreKey(keystoreVectorPassword, salt, keystoreN, keystoreR, keystoreP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hex.EncodeToString(key[:]); got != keystoreVectorKey {
+		t.Errorf("the scrypt key is %s, want %s", got, keystoreVectorKey)
+	}
+
+	sealed, err := sealKeystoreWith(keystoreVectorSeed, keystoreVectorPassword, salt, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.MarshalIndent(sealed, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != keystoreVector {
+		t.Errorf("sealing the seed again wrote\n%s\nwant\n%s", data, keystoreVector)
+	}
+}
+
+// TestKeystoreRoundTrip exports a result the way export keystore does, and expects the file to open with its password
+// only, never overwriting it
+func TestKeystoreRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	r := result{Address: keystoreVectorAddress, Seed: keystoreVectorSeed, Pattern: "CZ", FoundAt: time.Now()}
+	path, err := exportKeystore(dir, r, keystoreVectorPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != filepath.Join(dir, keystoreVectorAddress+".keystore.json") {
+		t.Errorf("exported to %s", path)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm()&0077 != 0 {
+		t.Errorf("the keystore file is %v, %v, want it private", info.Mode(), err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var k keystore
+	if err := json.Unmarshal(data, &k); err != nil {
+		t.Fatal(err)
+	}
+	if k.Address != keystoreVectorAddress {
+		t.Errorf("the keystore is addressed to %s", k.Address)
+	}
+	if seed, err := k.Open(keystoreVectorPassword); err != nil || seed != keystoreVectorSeed {
+		t.Errorf("the keystore opened to %q, %v, want its seed", seed, err)
+	}
+	if _, err := k.Open("wrong password"); err == nil {
+		t.Error("the keystore opened with a wrong password")
+	}
+	if _, err := exportKeystore(dir, r, keystoreVectorPassword); err == nil {
+		t.Error("exporting the result again overwrote its keystore")
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"crypto/sha256"                                              // hash-x keys and contract ids are SHA-256 hashes
+	"encoding/binary"                                            // used for appending the -muxed-id to a muxed account
+	"encoding/hex"                                               // the preimages and salts are written as hex, like the Stellar CLI takes them
+	"filippo.io/edwards25519"                                    // the partial key of -split-key
+	"fmt"                                                        // used for describing an invalid -key-type
+	"github.com/andreimerlescu/xlm-vanity-address-finder/vanity" // the layouts of the key types
+	"github.com/stellar/go/keypair"                              // the key pairs the workers generate
+	"github.com/stellar/go/strkey"                               // used for encoding every kind of key
+	"github.com/stellar/go/xdr"                                  // used for building the preimage of a contract id
+	"strconv"                                                    // used for parsing the -muxed-id
+	"strings"                                                    // used for listing the key types
+)
+
+// the -key-type values, which strkey the workers encode every key pair as before matching the patterns against it
+const (
+	keyTypeAccount       = "account"        // G..., the account of the key pair
+	keyTypeMuxed         = "muxed"          // M..., the account of the key pair with the -muxed-id
+	keyTypeSignedPayload = "signed-payload" // P..., the key pair as a SEP-23 signer of the -signed-payload
+	keyTypeHashX         = "hash-x"         // X..., the SHA-256 of a random preimage, a hash(x) signer
+	keyTypeContract      = "contract"       // C..., the contract the -contract-deployer deploys with a random salt
+	keyTypePreAuthTx     = "pre-auth-tx"    // T..., the hash of a transaction, which can't be searched for
+)
+
+// keyTypes lists the -key-type values in the order of the help text
+var keyTypes = []string{keyTypeAccount, keyTypeMuxed, keyTypeSignedPayload, keyTypeHashX, keyTypeContract, keyTypePreAuthTx}
+
+// keyLeads are the characters the key types that can be searched for start with, G first
+const keyLeads = "GMPXC"
+
+// keyVersions are the version bytes of the key types starting with the characters of keyLeads
+var keyVersions = map[byte]strkey.VersionByte{'G': strkey.VersionByteAccountID, 'M': strkey.VersionByteMuxedAccount,
+	'P': strkey.VersionByteSignedPayload, 'X': strkey.VersionByteHashX, 'C': strkey.VersionByteContract}
+
+// leadLayout returns the layout of the keys starting with lead, before any -muxed-id or -signed-payload is known; it is
+// all the start of a key depends on
+func leadLayout(lead byte) *vanity.Layout {
+	return vanity.NewLayout(byte(keyVersions[lead]), nil)
+}
+
+// maxSignedPayload is the longest payload a SEP-23 signed payload signer carries
+const maxSignedPayload = 64
+
+// keyEncoder encodes the key pairs of the workers as the strkey of a -key-type other than account; hash-x and contract
+// keys only take the 32 random bytes of the seed of the key pair, as the preimage and the salt
+type keyEncoder struct {
+	Type     string
+	Layout   *vanity.Layout // where the random, fixed and checksum bits of a key of Type land in its characters
+	Deployer string         // with contract, the -contract-deployer
+	SplitKey string         // with split, the -split-key
+
+	version  strkey.VersionByte  // the version byte of Type
+	tail     []byte              // with muxed, the -muxed-id, with signed-payload, the length of the -signed-payload, itself and its padding
+	preimage []byte              // with contract, the XDR of the preimage of the contract id, the salt being its last 32 bytes
+	partial  *edwards25519.Point // with split, the point of the -split-key
+}
+
+// newKeyEncoder returns the encoder of the -key-type kind, nil for account, which the workers match as they always
+// have; muxedID, payload and deployer are the -muxed-id, the hex -signed-payload and the -contract-deployer, passphrase
+// the -network the contract is deployed on
+func newKeyEncoder(kind, muxedID, payload, deployer, passphrase string) (*keyEncoder, error) {
+	k := &keyEncoder{Type: kind}
+	switch kind {
+	case keyTypeAccount, "":
+		return nil, nil
+	case keyTypeMuxed:
+		id, err := strconv.ParseUint(muxedID, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -muxed-id %q: expected 0 to %d", muxedID, uint64(1<<64-1))
+		}
+		k.version, k.tail = strkey.VersionByteMuxedAccount, binary.BigEndian.AppendUint64(nil, id)
+	case keyTypeSignedPayload:
+		raw, err := hex.DecodeString(payload)
+		if err != nil || len(raw) == 0 || len(raw) > maxSignedPayload {
+			return nil, fmt.Errorf("invalid -signed-payload %q: expected 1 to %d bytes of hex", payload, maxSignedPayload)
+		}
+		k.version, k.tail = strkey.VersionByteSignedPayload, binary.BigEndian.AppendUint32(nil, uint32(len(raw)))
+		k.tail = append(append(k.tail, raw...), make([]byte, (4-len(raw)%4)%4)...) // the payload is padded to 4 bytes like XDR
+	case keyTypeHashX:
+		k.version = strkey.VersionByteHashX
+	case keyTypeContract:
+		preimage, err := contractPreimage(deployer, passphrase)
+		if err != nil {
+			return nil, err
+		}
+		k.version, k.Deployer, k.preimage = strkey.VersionByteContract, deployer, preimage
+	case keyTypePreAuthTx:
+		return nil, fmt.Errorf("-key-type %s: a pre-authorized transaction key is the hash of its transaction, there is nothing random to search", kind)
+	default:
+		return nil, fmt.Errorf("invalid -key-type %q: expected %s", kind, strings.Join(keyTypes, ", "))
+	}
+	k.Layout = vanity.NewLayout(byte(k.version), k.tail)
+	return k, nil
+}
+
+// contractPreimage returns the XDR of the preimage of the id of a contract deployer deploys on the network of
+// passphrase, with a salt of zeros for the workers to replace
+func contractPreimage(deployer, passphrase string) ([]byte, error) {
+	if !strkey.IsValidEd25519PublicKey(deployer) {
+		return nil, fmt.Errorf("invalid -contract-deployer %q: -key-type %s needs the G... public key deploying the contract", deployer, keyTypeContract)
+	}
+	account := xdr.MustAddress(deployer)
+	preimage := xdr.HashIdPreimage{
+		Type: xdr.EnvelopeTypeEnvelopeTypeContractId,
+		ContractId: &xdr.HashIdPreimageContractId{
+			NetworkId: sha256.Sum256([]byte(passphrase)),
+			ContractIdPreimage: xdr.ContractIdPreimage{
+				Type: xdr.ContractIdPreimageTypeContractIdPreimageFromAddress,
+				FromAddress: &xdr.ContractIdPreimageFromAddress{
+					Address: xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeAccount, AccountId: &account},
+				},
+			},
+		},
+	}
+	return preimage.MarshalBinary()
+}
+
+// Encode returns the strkey of the -key-type of pair
+func (k *keyEncoder) Encode(pair *keypair.Full) string {
+	var raw []byte
+	switch k.Type {
+	case keyTypeMuxed, keyTypeSignedPayload:
+		raw = append(publicKey(pair), k.tail...)
+	case keyTypeHashX:
+		hash := sha256.Sum256(rawSeed(pair))
+		raw = hash[:]
+	case keyTypeContract:
+		preimage := append([]byte(nil), k.preimage...)
+		copy(preimage[len(preimage)-32:], rawSeed(pair))
+		id := sha256.Sum256(preimage)
+		raw = id[:]
+	case keyTypeSplit:
+		return splitAddress(k.partial, splitTweak(pair))
+	}
+	return strkey.MustEncode(k.version, raw)
+}
+
+// Describe records the key of pair in r along with what it derives from besides the seed of pair: the preimage of a
+// hash-x key, the salt and the deployer of a contract, or the tweak and the partial key of a split key
+func (k *keyEncoder) Describe(r *result, pair *keypair.Full) {
+	r.KeyType, r.Strkey = k.Type, k.Encode(pair)
+	switch k.Type {
+	case keyTypeHashX:
+		r.Preimage = hex.EncodeToString(rawSeed(pair))
+	case keyTypeContract:
+		r.Salt, r.Deployer = hex.EncodeToString(rawSeed(pair)), k.Deployer
+	case keyTypeSplit:
+		r.Tweak, r.SplitKey = hex.EncodeToString(splitTweak(pair).Bytes()), k.SplitKey
+	}
+}
+
+// verifyStrkey checks the -key-type key of r against its address or preimage; a contract id also depends on the
+// -network it is deployed on, which r doesn't record, so only its salt is checked against the seed
+func verifyStrkey(r result) error {
+	switch r.KeyType {
+	case keyTypeMuxed:
+		muxed, err := strkey.DecodeMuxedAccount(r.Strkey)
+		if err != nil {
+			return fmt.Errorf("invalid %s key: %w", r.KeyType, err)
+		}
+		if account, _ := muxed.AccountID(); account != r.Address {
+			return fmt.Errorf("%s is the muxed account of %s", r.Strkey, account)
+		}
+	case keyTypeSignedPayload:
+		payload, err := strkey.DecodeSignedPayload(r.Strkey)
+		if err != nil {
+			return fmt.Errorf("invalid %s key: %w", r.KeyType, err)
+		}
+		if payload.Signer() != r.Address {
+			return fmt.Errorf("%s is signed by %s", r.Strkey, payload.Signer())
+		}
+	case keyTypeHashX:
+		preimage, err := hex.DecodeString(r.Preimage)
+		hash := sha256.Sum256(preimage)
+		if err != nil || strkey.MustEncode(strkey.VersionByteHashX, hash[:]) != r.Strkey {
+			return fmt.Errorf("the preimage %q doesn't hash to %s", r.Preimage, r.Strkey)
+		}
+	case keyTypeContract:
+		if pair, err := keypair.ParseFull(r.Seed); err != nil || hex.EncodeToString(rawSeed(pair)) != r.Salt {
+			return fmt.Errorf("the salt %q isn't the one of the seed", r.Salt)
+		}
+	case keyTypeSplit:
+		return verifySplit(r)
+	}
+	return nil
+}
+
+// Account reports whether the key pair is the account or signer behind the key, so funding it or signing for it
+// means something; hash-x and contract keys only take its random bytes
+func (k *keyEncoder) Account() bool {
+	return k.Type == keyTypeMuxed || k.Type == keyTypeSignedPayload
+}
+
+// publicKey returns the 32 bytes of the public key of pair
+func publicKey(pair *keypair.Full) []byte {
+	return strkey.MustDecode(strkey.VersionByteAccountID, pair.Address())
+}
+
+// rawSeed returns the 32 bytes of the seed of pair
+func rawSeed(pair *keypair.Full) []byte {
+	return strkey.MustDecode(strkey.VersionByteSeed, pair.Seed())
+}
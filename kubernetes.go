@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"     // used for writing the metrics
+	"io"      // the metrics are written to the response
+	"os"      // access the environment
+	"sort"    // used for writing the metrics of the patterns in a stable order
+	"strconv" // used for parsing the index of the Job
+)
+
+// shardIndexEnv is the index Kubernetes gives every pod of an Indexed Job, 0 to completions-1, which picks the share of
+// -shards the pod searches
+const shardIndexEnv = "JOB_COMPLETION_INDEX"
+
+// shardIndex returns the share of -shards this instance searches, from JOB_COMPLETION_INDEX
+func shardIndex(shards int) (int, error) {
+	value, ok := os.LookupEnv(shardIndexEnv)
+	if !ok {
+		return 0, fmt.Errorf("-shards %d needs %s, which Kubernetes sets in every pod of an Indexed Job", shards, shardIndexEnv)
+	}
+	index, err := strconv.Atoi(value)
+	if err != nil || index < 0 || index >= shards {
+		return 0, fmt.Errorf("%s=%q: expected 0 to %d for -shards %d", shardIndexEnv, value, shards-1, shards)
+	}
+	return index, nil
+}
+
+// shardPatterns returns the share of patterns searched by the instance at index of shards, the way -cluster deals them
+// out, or all of them without -shards
+func shardPatterns(patterns []string, shards, index int) []string {
+	if shards <= 1 {
+		return patterns
+	}
+	return splitPatterns(patterns, shards)[index]
+}
+
+// writeMetrics writes snapshot in the Prometheus text format for GET /metrics
+func writeMetrics(w io.Writer, snapshot statsSnapshot) {
+	metric := func(name, kind, help string) {
+		_, _ = fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	}
+	metric("xlm_vanity_attempts_total", "counter", "Addresses scanned since the start.")
+	_, _ = fmt.Fprintf(w, "xlm_vanity_attempts_total %d\n", snapshot.Attempts)
+	metric("xlm_vanity_matches_total", "counter", "Matches saved since the start, per pattern.")
+	names := make([]string, 0, len(snapshot.ByPattern))
+	for name := range snapshot.ByPattern {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		_, _ = fmt.Fprintf(w, "xlm_vanity_matches_total{pattern=%q} %d\n", name, snapshot.ByPattern[name])
+	}
+	metric("xlm_vanity_rate", "gauge", "Addresses per second since the start.")
+	_, _ = fmt.Fprintf(w, "xlm_vanity_rate %g\n", snapshot.AverageRate)
+	metric("xlm_vanity_patterns", "gauge", "Patterns searched for.")
+	_, _ = fmt.Fprintf(w, "xlm_vanity_patterns %d\n", len(snapshot.Patterns))
+	metric("xlm_vanity_uptime_seconds", "gauge", "Seconds since the search started.")
+	_, _ = fmt.Fprintf(w, "xlm_vanity_uptime_seconds %g\n", snapshot.UptimeSeconds)
+}
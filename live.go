@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"                // every frame is a JSON document
+	"github.com/gorilla/websocket" // GET /ws upgrades to a WebSocket
+	"net/http"                     // used for upgrading the request
+	"sync"                         // used for guarding the subscribers
+	"time"                         // used for the progress ticks, the pings and the write timeouts
+)
+
+// liveEvery is how often GET /ws subscribers get a progress frame
+const liveEvery = time.Second
+
+// liveBuffer is how many frames a subscriber may fall behind before it misses some
+const liveBuffer = 64
+
+// liveHistory is how many of the latest match frames a new subscriber gets first, so a dashboard opened late or reloaded
+// isn't empty; it leaves room in liveBuffer for the frames that follow
+const liveHistory = 48
+
+// liveTimeout is how long writing a frame, or a ping, to a subscriber may take before it is dropped
+const liveTimeout = 10 * time.Second
+
+// livePing is how often subscribers are pinged, so proxies keep the connection open and dead ones are noticed
+const livePing = 30 * time.Second
+
+// liveFrame is a JSON frame of GET /ws: Type is progress with a statsSnapshot, or match with a matchEvent
+type liveFrame struct {
+	Type string `json:"type"` // progress or match
+	Data any    `json:"data"` // the statsSnapshot or the matchEvent
+}
+
+// liveFeed streams the progress every liveEvery and every match, without its seed, to the subscribers of GET /ws;
+// a subscriber too slow to keep up misses frames rather than holding the search up
+type liveFeed struct {
+	mu          sync.Mutex
+	subscribers map[chan []byte]struct{}
+	history     [][]byte           // the latest liveHistory match frames, oldest first
+	upgrader    websocket.Upgrader // only same origin browsers, like a dashboard served by -listen, or clients without one
+	closed      bool               // set by Close, no more subscribers are taken
+}
+
+// newLiveFeed returns a feed without subscribers
+func newLiveFeed() *liveFeed {
+	return &liveFeed{subscribers: make(map[chan []byte]struct{})}
+}
+
+// ServeHTTP upgrades GET /ws to a WebSocket and writes the latest matches, then the frames of the feed, to it until
+// either side closes it
+func (f *liveFeed) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := f.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		debugf("GET /ws from %s: %v", r.RemoteAddr, err) // the upgrader already answered
+		return
+	}
+	defer func() { _ = conn.Close() }()
+	frames := make(chan []byte, liveBuffer)
+	f.mu.Lock()
+	if f.closed {
+		f.mu.Unlock()
+		return
+	}
+	for _, frame := range f.history {
+		frames <- frame
+	}
+	f.subscribers[frames] = struct{}{}
+	f.mu.Unlock()
+	defer f.unsubscribe(frames)
+	debugf("GET /ws: %s subscribed", r.RemoteAddr)
+
+	gone := make(chan struct{})
+	go func() { // the subscriber sends nothing, but reading is how a close or a pong is noticed
+		defer close(gone)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+	ping := time.NewTicker(livePing)
+	defer ping.Stop()
+	for {
+		select {
+		case frame, ok := <-frames:
+			if !ok { // the search ended
+				_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, "the search has ended"),
+					time.Now().Add(liveTimeout))
+				return
+			}
+			_ = conn.SetWriteDeadline(time.Now().Add(liveTimeout))
+			if err := conn.WriteMessage(websocket.TextMessage, frame); err != nil {
+				return
+			}
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(liveTimeout)); err != nil {
+				return
+			}
+		case <-gone:
+			debugf("GET /ws: %s unsubscribed", r.RemoteAddr)
+			return
+		}
+	}
+}
+
+// unsubscribe forgets frames, unless Close already did
+func (f *liveFeed) unsubscribe(frames chan []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.subscribers, frames)
+}
+
+// Subscribers returns how many clients are subscribed, the progress isn't measured for nobody
+func (f *liveFeed) Subscribers() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.subscribers)
+}
+
+// Progress sends snapshot to every subscriber
+func (f *liveFeed) Progress(snapshot statsSnapshot) {
+	f.broadcast(liveFrame{Type: "progress", Data: snapshot})
+}
+
+// Match sends r, without its seed, to every subscriber
+func (f *liveFeed) Match(r result) {
+	f.broadcast(liveFrame{Type: "match", Data: newMatchEvent(r, false)})
+}
+
+// broadcast encodes frame once and queues it for every subscriber with room for it
+func (f *liveFeed) broadcast(frame liveFrame) {
+	encoded, err := json.Marshal(frame)
+	if err != nil {
+		debugf("GET /ws: failed to encode a %s frame: %v", frame.Type, err)
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if frame.Type == "match" {
+		f.history = append(f.history, encoded)
+		if len(f.history) > liveHistory {
+			f.history = f.history[len(f.history)-liveHistory:]
+		}
+	}
+	for frames := range f.subscribers {
+		select {
+		case frames <- encoded:
+		default:
+			tracef("GET /ws: dropped a %s frame for a subscriber falling behind", frame.Type)
+		}
+	}
+}
+
+// Close ends every subscription with a close frame and takes no more
+func (f *liveFeed) Close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	for frames := range f.subscribers {
+		close(frames)
+		delete(f.subscribers, frames)
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"                        // used for reporting an invalid -locale
+	"golang.org/x/text/language" // used for parsing -locale and LANG into a language tag
+	"golang.org/x/text/message"  // formats numbers the way the locale writes them
+	"os"                         // used for reading LC_ALL, LC_NUMERIC and LANG
+	"strings"                    // used for turning a POSIX locale into a language tag
+)
+
+// printer formats every number shown to the user, 1,234,567 in English, 1.234.567 in German and 12,34,567 in Indian
+// English; it follows the environment until -locale says otherwise
+var printer = message.NewPrinter(environmentLocale("LC_ALL", "LC_NUMERIC", "LANG"))
+
+// environmentLocale is the locale named by the first of keys set in the environment, the order the C library looks
+// them up in, and English when none of them is set or they name the C or POSIX locale; a list of locales separated by
+// colons, as in LANGUAGE, is represented by its first one
+func environmentLocale(keys ...string) language.Tag {
+	for _, key := range keys {
+		value, _, _ := strings.Cut(os.Getenv(key), ":")
+		if value == "" {
+			continue
+		}
+		if tag, err := parseLocale(value); err == nil {
+			return tag
+		}
+		break // the variable that would be used is unusable, don't fall through to a less specific one
+	}
+	return language.English
+}
+
+// parseLocale accepts BCP 47 tags such as de-DE as well as POSIX locales such as de_DE.UTF-8 or de_DE@euro
+func parseLocale(locale string) (language.Tag, error) {
+	if i := strings.IndexAny(locale, ".@"); i >= 0 {
+		locale = locale[:i] // the codeset and modifier don't change how numbers are written
+	}
+	if locale == "C" || locale == "POSIX" {
+		return language.English, nil
+	}
+	return language.Parse(strings.ReplaceAll(locale, "_", "-"))
+}
+
+// setLocale makes printer format numbers for the -locale value, the environment decides when it is empty
+func setLocale(locale string) error {
+	if locale == "" {
+		return nil
+	}
+	tag, err := parseLocale(locale)
+	if err != nil {
+		return fmt.Errorf("invalid -locale %q: expected a locale like en-US, de-DE or pt_BR.UTF-8", locale)
+	}
+	printer = message.NewPrinter(tag)
+	return nil
+}
+
+// FormatInt64 takes an int64 and humanizes the output with the digit grouping of the locale
+func FormatInt64(n int64) string {
+	return printer.Sprintf("%d", n)
+}
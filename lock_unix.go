@@ -0,0 +1,28 @@
+//go:build unix
+
+package main
+
+import (
+	"errors"  // used for detecting when another process already holds the lock
+	"fmt"     // used for wrapping errors with the lock path
+	"os"      // access the filesystem
+	"syscall" // used for flock(2) on the lock file
+)
+
+// lockOutput takes an exclusive, non-blocking flock(2) on path + ".lock" so a second instance pointed at the same
+// -output refuses to start instead of interleaving its writes with ours; the lock is released when the process exits
+func lockOutput(path string) (*os.File, error) {
+	lockPath := path + ".lock"
+	lockFile, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file %s: %w", lockPath, err)
+	}
+	if err := syscall.Flock(int(lockFile.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		_ = lockFile.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, fmt.Errorf("another xlm-vanity-address-finder is already writing to %s (lock held on %s)", path, lockPath)
+		}
+		return nil, fmt.Errorf("failed to lock %s: %w", lockPath, err)
+	}
+	return lockFile, nil
+}
@@ -0,0 +1,29 @@
+//go:build windows
+
+package main
+
+import (
+	"errors"                   // used for detecting when another process already holds the lock
+	"fmt"                      // used for wrapping errors with the lock path
+	"golang.org/x/sys/windows" // used for LockFileEx on the lock file
+	"os"                       // access the filesystem
+)
+
+// lockOutput takes an exclusive, non-blocking LockFileEx on path + ".lock" so a second instance pointed at the same
+// -output refuses to start instead of interleaving its writes with ours; the lock is released when the process exits
+func lockOutput(path string) (*os.File, error) {
+	lockPath := path + ".lock"
+	lockFile, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file %s: %w", lockPath, err)
+	}
+	flags := uint32(windows.LOCKFILE_EXCLUSIVE_LOCK | windows.LOCKFILE_FAIL_IMMEDIATELY)
+	if err := windows.LockFileEx(windows.Handle(lockFile.Fd()), flags, 0, 1, 0, &windows.Overlapped{}); err != nil {
+		_ = lockFile.Close()
+		if errors.Is(err, windows.ERROR_LOCK_VIOLATION) {
+			return nil, fmt.Errorf("another xlm-vanity-address-finder is already writing to %s (lock held on %s)", path, lockPath)
+		}
+		return nil, fmt.Errorf("failed to lock %s: %w", lockPath, err)
+	}
+	return lockFile, nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"     // used for reporting an unknown -log-level
+	"log"     // every level still goes through the timestamped logger
+	"strings" // used for matching -log-level case-insensitively
+)
+
+// logLevel orders how much is written to the log, each level includes everything below it
+type logLevel int
+
+const (
+	levelError logLevel = iota // only fatal errors
+	levelWarn                  // plus recoverable failures such as a failed funding
+	levelInfo                  // plus the regular feedback, the default
+	levelDebug                 // plus config resolution, worker startup, matcher compilation and persistence, -v
+	levelTrace                 // plus per-result persistence details, -vv
+)
+
+// logLevelNames are the values accepted by -log-level
+var logLevelNames = map[string]logLevel{
+	"error": levelError,
+	"warn":  levelWarn,
+	"info":  levelInfo,
+	"debug": levelDebug,
+	"trace": levelTrace,
+}
+
+// currentLevel is set once from -log-level, -v and -vv before any worker starts
+var currentLevel = levelInfo
+
+// resolveLogLevel combines -log-level with -v and -vv, which only ever raise the verbosity
+func resolveLogLevel(name string, v, vv bool) (logLevel, error) {
+	level, ok := logLevelNames[strings.ToLower(name)]
+	if !ok {
+		return levelInfo, fmt.Errorf("unknown -log-level %q, expected error, warn, info, debug or trace", name)
+	}
+	if v && level < levelDebug {
+		level = levelDebug
+	}
+	if vv && level < levelTrace {
+		level = levelTrace
+	}
+	return level, nil
+}
+
+// debugf logs when -v or -log-level debug is set
+func debugf(format string, args ...any) {
+	if currentLevel >= levelDebug {
+		log.Printf("DEBUG "+format, args...)
+	}
+}
+
+// tracef logs when -vv or -log-level trace is set
+func tracef(format string, args ...any) {
+	if currentLevel >= levelTrace {
+		log.Printf("TRACE "+format, args...)
+	}
+}
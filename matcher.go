@@ -0,0 +1,286 @@
+package main
+
+import (
+	"fmt"                                                        // used for reporting an invalid pattern
+	"github.com/andreimerlescu/xlm-vanity-address-finder/vanity" // the search core finding the patterns in an address
+	"github.com/stellar/go/keypair"                              // the key pairs whose address or seed is matched
+	"strings"                                                    // used for interacting with the substrings of the -find request
+)
+
+// matcher holds the -find patterns every worker compares addresses against; workers load it through an
+// atomic.Pointer on every attempt so a SIGHUP reload can swap in new patterns without stopping them
+type matcher struct {
+	patterns    []string        // upper-cased, since XLM addresses are upper-case
+	core        *vanity.Matcher // finds patterns, -prefix and -suffix in an address, nil with -dictionary
+	position    string          // where in the address the patterns must appear, positionAnywhere, positionStart or positionEnd
+	field       string          // what the patterns are searched for in, fieldAddress or fieldSeed with -find-seed
+	prefix      string          // upper-cased -prefix every match must start with, checked before the patterns
+	suffix      string          // upper-cased -suffix every match must end with, checked before the patterns
+	probability float64         // the chance a single address matches any of patterns along with prefix and suffix
+	dictionary  string          // with -dictionary, what is searched for instead of patterns, for logs and -stats
+	words       *wordAutomaton  // with -dictionary, the words searched for instead of patterns
+	blocked     *wordAutomaton  // with -dictionary, the -blocklist words no matching address may contain
+	seed        *matcher        // with -find and -find-seed together, the patterns the seed must contain as well
+	plugin      matcherPlugin   // with -matcher-plugin, what decides instead of patterns, whose only one is its name
+	keys        *keyEncoder     // with a -key-type other than account, what the address of a key pair is encoded as
+}
+
+// matchOptions are the flags deciding how newMatcher matches the -find patterns
+type matchOptions struct {
+	lowercase bool        // -lowercase, characters that read the same in a lowercase address match too
+	position  string      // -position, where in the address the patterns must appear
+	prefix    string      // -prefix, what every match must start with on top of the patterns
+	suffix    string      // -suffix, what every match must end with on top of the patterns
+	seed      bool        // -find-seed alone, the patterns are searched for in the seed instead of the address
+	findSeed  string      // -find-seed along with -find, the patterns the seed must contain on top of the address ones
+	keys      *keyEncoder // -key-type, the strkey the patterns are searched for in instead of the G... address, nil for account
+}
+
+// Field is what the patterns are searched for in, fieldAddress or fieldSeed
+func (o matchOptions) Field() string {
+	if o.seed {
+		return fieldSeed
+	}
+	return fieldAddress
+}
+
+// Layout is the layout of everything the patterns are searched for in: that of a seed, of an address or of the keys of
+// the -key-type
+func (o matchOptions) Layout() *vanity.Layout {
+	switch {
+	case o.seed:
+		return vanity.SeedLayout
+	case o.keys != nil:
+		return o.keys.Layout
+	}
+	return vanity.AccountLayout
+}
+
+// newMatcher parses the -find value into a matcher, rejecting any pattern, -prefix or -suffix that can never appear in
+// an address along with the closest patterns that can; with options.lowercase set a pattern also matches the characters
+// that read the same once the address is shown in lowercase (see vanity.Lookalikes)
+func newMatcher(find string, options matchOptions) (*matcher, error) {
+	lowercase, position, layout := options.lowercase, options.position, options.Layout()
+	if !vanity.ValidPosition(position) {
+		return nil, fmt.Errorf("invalid -position %q: expected %s, %s or %s", position, positionAnywhere, positionStart, positionEnd)
+	}
+	prefix, suffix := strings.ToUpper(strings.TrimSpace(options.prefix)), strings.ToUpper(strings.TrimSpace(options.suffix))
+	if options.seed && (prefix != "" || suffix != "") {
+		return nil, fmt.Errorf("-prefix and -suffix apply to the address, -find-seed patterns are searched for in the seed")
+	}
+	if err := checkAffix(cKeyPrefix, prefix, 0, lowercase, layout); err != nil {
+		return nil, err
+	}
+	if err := checkAffix(cKeySuffix, suffix, layout.Length()-len(suffix), lowercase, layout); err != nil {
+		return nil, err
+	}
+	if len(prefix)+len(suffix) > layout.Length() {
+		return nil, fmt.Errorf("-prefix %s and -suffix %s are longer than the %d characters of an address together", prefix, suffix, layout.Length())
+	}
+	patterns := parsePatterns(find)
+	m := &matcher{patterns: patterns, position: position, field: options.Field(), prefix: prefix, suffix: suffix, keys: options.keys}
+	for _, pattern := range patterns {
+		report := inspectField(pattern, lowercase, position, m.field, layout)
+		if !report.Feasible() && m.field == fieldSeed {
+			return nil, fmt.Errorf("invalid format of -find-seed value: %v (%s)", pattern, report.Reason())
+		} else if !report.Feasible() {
+			return nil, fmt.Errorf("invalid format of -find value: %v (%s)", pattern, report.Reason())
+		}
+	}
+	core, err := vanity.NewMatcher(patterns, vanity.Options{Position: position, Lowercase: lowercase, Prefix: prefix, Suffix: suffix, Layout: layout})
+	if err != nil {
+		return nil, err
+	}
+	m.core = core
+	m.probability = core.Probability()
+	if options.findSeed != "" && !options.seed {
+		seed, err := newMatcher(options.findSeed, matchOptions{position: position, seed: true})
+		if err != nil {
+			return nil, err
+		}
+		m.seed, m.probability = seed, m.probability*seed.probability // a seed and its address are independent
+	}
+	return m, nil
+}
+
+// newPluginMatcher returns the matcher of a -matcher-plugin, which checks the -prefix and -suffix of options before
+// calling the plugin and names its matches after it
+func newPluginMatcher(plugin matcherPlugin, options matchOptions) (*matcher, error) {
+	m, err := newMatcher("", options)
+	if err != nil {
+		return nil, err
+	}
+	m.patterns, m.plugin = []string{plugin.Name()}, plugin
+	m.probability *= plugin.Probability()
+	return m, nil
+}
+
+// checkAffix rejects a -prefix or -suffix named flag that can never start at offset of a key of layout, an address
+// unless -key-type says otherwise
+func checkAffix(flag, affix string, offset int, lowercase bool, layout *vanity.Layout) error {
+	if affix == "" || layout.OffsetProbability(vanity.PatternClasses(affix, lowercase), offset) > 0 {
+		return nil
+	}
+	lead := layout.Lead()
+	report := inspectField(affix, lowercase, positionAnywhere, fieldAddress, layout)
+	switch {
+	case len(report.Invalid) > 0 || len(affix) > layout.Length():
+		return fmt.Errorf("invalid -%s %s: %s", flag, affix, report.reason())
+	case offset > 0:
+		return fmt.Errorf("invalid -%s %s: %s", flag, affix, endReason(layout, len(affix)))
+	case affix[0] != lead:
+		return fmt.Errorf("invalid -%s %s: every address starts with %c, try -%s %c%s", flag, affix, lead, flag, lead, affix)
+	default:
+		return fmt.Errorf("invalid -%s %s: an address starts with %c followed by one of A, B, C or D", flag, affix, lead)
+	}
+}
+
+// MatchPair returns the pattern the address of pair contains, or with -find-seed alone its seed does, and with -find and
+// -find-seed together the pattern its seed contains as well; the seed is only encoded once the address matched
+func (m *matcher) MatchPair(pair *keypair.Full) (pattern, seedPattern string, found bool) {
+	if pattern, found = m.Match(m.Subject(pair)); !found || m.seed == nil {
+		return pattern, "", found
+	}
+	seedPattern, found = m.seed.Match(pair.Seed())
+	return pattern, seedPattern, found
+}
+
+// MatchKeys is MatchPair for the last key of keys, matched on the buffers of keys so that a key that doesn't match
+// allocates nothing; only a -key-type other than account needs its key pair
+func (m *matcher) MatchKeys(keys *vanity.Keys) (pattern, seedPattern string, found bool) {
+	subject := keys.Address()
+	if m.field == fieldSeed {
+		subject = keys.Seed()
+	} else if m.keys != nil {
+		pair, err := keys.Pair()
+		if err != nil {
+			return "", "", false
+		}
+		subject = m.keys.Encode(pair)
+	}
+	if pattern, found = m.Match(subject); !found || m.seed == nil {
+		return pattern, "", found
+	}
+	seedPattern, found = m.seed.Match(keys.Seed())
+	return pattern, seedPattern, found
+}
+
+// Closest is vanity.Matcher.Closest for the last key of keys, the near miss -fancy shows; a -dictionary, a
+// -matcher-plugin or a -key-type other than account has no patterns to come close to
+func (m *matcher) Closest(keys *vanity.Keys) (string, int) {
+	switch {
+	case m.core == nil || m.plugin != nil || m.keys != nil:
+		return "", 0
+	case m.field == fieldSeed:
+		return m.core.Closest(keys.Seed())
+	}
+	return m.core.Closest(keys.Address())
+}
+
+// Subject returns what m matches of pair, its address, with -find-seed its seed or with -key-type the strkey it encodes as
+func (m *matcher) Subject(pair *keypair.Full) string {
+	if m.field == fieldSeed {
+		return pair.Seed()
+	}
+	if m.keys != nil {
+		return m.keys.Encode(pair)
+	}
+	return pair.Address()
+}
+
+// label describes what m matches in messages, like CAT or DOG with -prefix GABC and 7777 in the seed
+func (m *matcher) label() string {
+	parts := make([]string, 0, 3)
+	if patterns := orList(m.patterns); patterns != "" && m.field == fieldSeed {
+		parts = append(parts, patterns+" in the seed")
+	} else if patterns != "" {
+		parts = append(parts, patterns)
+	}
+	if affixes := (&affixEstimate{Prefix: m.prefix, Suffix: m.suffix}).label(); affixes != "" {
+		parts = append(parts, affixes)
+	}
+	if m.seed != nil {
+		parts = append(parts, m.seed.label())
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	return strings.Join(parts[:len(parts)-1], ", ") + " and " + parts[len(parts)-1]
+}
+
+// fileName is the name of the default -output of m without its extension: the prefix, patterns and suffix, with a
+// seed- in front of the patterns searched for in the seed, or the -key-type of another kind of key
+func (m *matcher) fileName() string {
+	parts := make([]string, 0, len(m.patterns)+3)
+	if m.field == fieldSeed {
+		parts = append(parts, fieldSeed)
+	} else if m.keys != nil {
+		parts = append(parts, m.keys.Type)
+	}
+	for _, part := range append(append([]string{m.prefix}, m.patterns...), m.suffix) {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if m.seed != nil {
+		parts = append(parts, m.seed.fileName())
+	}
+	return strings.Join(parts, "-")
+}
+
+// Match returns the first pattern that address contains once it has the -prefix and -suffix, or the longest word of the -dictionary unless the address
+// contains a blocked word
+func (m *matcher) Match(address string) (string, bool) {
+	if m.words != nil {
+		word, _, found := m.words.Find(address)
+		if found && m.blocked != nil {
+			if _, _, blocked := m.blocked.Find(address); blocked {
+				return "", false
+			}
+		}
+		return word, found
+	}
+	if m.plugin != nil {
+		if m.core.Affixed(address) && m.plugin.Match(address) {
+			return m.patterns[0], true
+		}
+		return "", false
+	}
+	return m.core.Match(address)
+}
+
+// Spelling returns the characters of address that matched pattern, which differ from pattern with -lowercase
+func (m *matcher) Spelling(address, pattern string) string {
+	if m.words != nil {
+		if word, at, found := m.words.Find(address); found && word == pattern {
+			return address[at : at+len(word)]
+		}
+		return pattern
+	}
+	return m.core.Spelling(address, pattern)
+}
+
+// Names lists what is searched for, the patterns or the -dictionary
+func (m *matcher) Names() []string {
+	if m.words != nil {
+		return []string{m.dictionary}
+	}
+	return m.patterns
+}
+
+// parsePatterns splits the -find value on commas into upper-cased patterns, since XLM addresses are upper-case
+func parsePatterns(find string) []string {
+	patterns := make([]string, 0)
+	for _, pattern := range strings.Split(find, ",") {
+		if pattern = strings.ToUpper(strings.TrimSpace(pattern)); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	if len(patterns) == 0 {
+		patterns = append(patterns, "") // an empty -find matches every address, as it always has
+	}
+	return patterns
+}
@@ -0,0 +1,7 @@
+package main
+
+// zeroize overwrites b once the seeds it held are no longer needed, so they don't linger in freed memory; seeds held in
+// Go strings can't be overwritten and are only dropped as soon as possible
+func zeroize(b []byte) {
+	clear(b)
+}
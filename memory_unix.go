@@ -0,0 +1,19 @@
+//go:build unix
+
+package main
+
+import (
+	"golang.org/x/sys/unix" // used for setrlimit(2) and mlockall(2)
+)
+
+// disableCoreDumps sets the core file size limit of the process to zero, so a crash can't write every seed found so far
+// to disk
+func disableCoreDumps() error {
+	return unix.Setrlimit(unix.RLIMIT_CORE, &unix.Rlimit{})
+}
+
+// lockMemory mlockall(2)s the current and future pages of the process for -mlock, so seeds are never swapped to disk;
+// it needs a large enough `ulimit -l` or CAP_IPC_LOCK
+func lockMemory() error {
+	return unix.Mlockall(unix.MCL_CURRENT | unix.MCL_FUTURE)
+}
@@ -0,0 +1,17 @@
+//go:build windows
+
+package main
+
+import (
+	"errors" // used for reporting that -mlock is unavailable
+)
+
+// disableCoreDumps does nothing on Windows, where crash dumps are configured through Windows Error Reporting
+func disableCoreDumps() error {
+	return nil
+}
+
+// lockMemory is not available on Windows, VirtualLock only pins individual regions and not the Go heap
+func lockMemory() error {
+	return errors.New("-mlock is not supported on windows")
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"                                      // used for reporting an invalid mnemonic
+	"fmt"                                         // used for the derivation paths and wrapping errors
+	"github.com/stellar/go/exp/crypto/derivation" // SEP-0005 key derivation, the same as a Ledger
+	"github.com/stellar/go/keypair"               // used for turning derived seeds into key pairs
+	"github.com/tyler-smith/go-bip39"             // used for the BIP-39 mnemonics and their seeds
+	"io"                                          // the entropy comes from crypto/rand or a -deterministic-seed stream
+)
+
+// mnemonicSource searches the addresses of BIP-39 mnemonics at the SEP-0005 paths m/44'/148'/i' for i below accounts,
+// the accounts a Ledger (or any SEP-0005 wallet) shows for a mnemonic, so a match can be restored on the device. Turning
+// a mnemonic into its seed takes 2048 rounds of PBKDF2, which is why every mnemonic is searched for several accounts.
+type mnemonicSource struct {
+	entropy  io.Reader       // crypto/rand.Reader, or the -deterministic-seed stream of the worker
+	bits     int             // the entropy of every mnemonic, 128 bits for 12 words and 256 bits for 24 words
+	accounts int             // how many accounts of every mnemonic are searched
+	root     *derivation.Key // the m/44'/148' key of Mnemonic
+	Mnemonic string          // the mnemonic of the key pair Next returned last
+	Index    int             // the account index of the key pair Next returned last
+}
+
+// mnemonicBits maps the supported -mnemonic-words to their entropy
+var mnemonicBits = map[int]int{12: 128, 24: 256}
+
+// newMnemonicSource returns the mnemonic key source of a worker drawing its entropy from entropy
+func newMnemonicSource(entropy io.Reader, words, accounts int) (*mnemonicSource, error) {
+	bits, ok := mnemonicBits[words]
+	if !ok {
+		return nil, fmt.Errorf("invalid -mnemonic-words %d: expected 12 or 24", words)
+	}
+	if accounts < 1 {
+		return nil, fmt.Errorf("invalid -mnemonic-accounts %d: expected at least 1", accounts)
+	}
+	return &mnemonicSource{entropy: entropy, bits: bits, accounts: accounts, Index: accounts - 1}, nil
+}
+
+// Next returns the key pair of the next account, moving on to a new mnemonic after the last account of the current one
+func (m *mnemonicSource) Next() (*keypair.Full, error) {
+	if m.Index++; m.Index >= m.accounts {
+		if err := m.rotate(); err != nil {
+			return nil, err
+		}
+	}
+	key, err := m.root.Derive(derivation.FirstHardenedIndex + uint32(m.Index))
+	if err != nil {
+		return nil, err
+	}
+	return keypair.FromRawSeed(key.RawSeed())
+}
+
+// Path returns the derivation path of the key pair Next returned last
+func (m *mnemonicSource) Path() string {
+	return fmt.Sprintf(derivation.StellarAccountPathFormat, m.Index)
+}
+
+// rotate draws a new mnemonic and restarts at its first account
+func (m *mnemonicSource) rotate() error {
+	entropy := make([]byte, m.bits/8)
+	defer zeroize(entropy)
+	if _, err := io.ReadFull(m.entropy, entropy); err != nil {
+		return err
+	}
+	mnemonic, err := bip39.NewMnemonic(entropy)
+	if err != nil {
+		return err
+	}
+	root, err := mnemonicRoot(mnemonic)
+	if err != nil {
+		return err
+	}
+	m.Mnemonic, m.root, m.Index = mnemonic, root, 0
+	return nil
+}
+
+// mnemonicRoot derives the m/44'/148' key of mnemonic, without a BIP-39 passphrase
+func mnemonicRoot(mnemonic string) (*derivation.Key, error) {
+	seed := bip39.NewSeed(mnemonic, "")
+	defer zeroize(seed)
+	return derivation.DeriveForPath(derivation.StellarAccountPrefix, seed)
+}
+
+// verifyMnemonic derives the address at path from mnemonic and compares it with address
+func verifyMnemonic(source, address, mnemonic, path string) verifyEntry {
+	entry := verifyEntry{Source: source, Address: address}
+	if !bip39.IsMnemonicValid(mnemonic) {
+		entry.Err = errors.New("invalid mnemonic")
+		return entry
+	}
+	seed := bip39.NewSeed(mnemonic, "")
+	defer zeroize(seed)
+	key, err := derivation.DeriveForPath(path, seed)
+	if err != nil {
+		entry.Err = fmt.Errorf("invalid path %s: %w", path, err)
+		return entry
+	}
+	pair, err := keypair.FromRawSeed(key.RawSeed())
+	if err != nil {
+		entry.Err = err
+		return entry
+	}
+	entry.Derived = pair.Address()
+	if address != entry.Derived {
+		entry.Err = fmt.Errorf("mnemonic derives %s at %s", entry.Derived, path)
+	}
+	return entry
+}
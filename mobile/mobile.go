@@ -0,0 +1,208 @@
+// Package mobile binds the search of xlm-vanity-address-finder for iOS and Android apps with gomobile bind; it only
+// uses the types gomobile can bind, so a wallet calls NewSearch, Start and Stop and gets the matches and the progress
+// through a Handler of its own
+package mobile
+
+import (
+	"errors"                                                     // used for refusing a search without a handler
+	"github.com/andreimerlescu/xlm-vanity-address-finder/vanity" // the search core
+	"math"                                                       // used for the chance of a match so far
+	"runtime"                                                    // used for the default number of workers
+	"strings"                                                    // used for splitting the patterns
+	"sync"                                                       // used for guarding the running search
+	"time"                                                       // used for reporting the progress
+)
+
+// Options decide what a Search looks for and how; NewOptions returns the defaults
+type Options struct {
+	Position       string // where in the address the patterns must appear: anywhere, start or end
+	Lowercase      bool   // characters that read the same once the address is shown in lowercase match too
+	Prefix         string // what every match must start with on top of the patterns, G included
+	Suffix         string // what every match must end with on top of the patterns
+	Workers        int    // go-routines generating key pairs, the number of CPUs by default
+	Limit          int    // the search stops itself after this many matches, and those of the batches in hand, 0 never
+	ProgressMillis int    // milliseconds between two OnProgress, 1000 by default
+}
+
+// NewOptions returns the options of a search for patterns anywhere in the address on every CPU
+func NewOptions() *Options {
+	return &Options{Position: vanity.PositionAnywhere, Workers: runtime.NumCPU(), ProgressMillis: 1000}
+}
+
+// Match is a key pair whose address matched a pattern
+type Match struct {
+	Address  string // the public G... address
+	Seed     string // the secret S... seed, whoever holds it owns the account, so keep it in the keychain or keystore
+	Pattern  string // the pattern the address contains
+	Attempts int64  // key pairs generated when it was found
+	FoundAt  int64  // when it was found, in milliseconds since the Unix epoch
+}
+
+// Progress is how far a running search got
+type Progress struct {
+	Attempts    int64   // key pairs generated since the search was made
+	Matches     int64   // matches since the search was made
+	Rate        float64 // key pairs per second since it was last started
+	Seconds     float64 // seconds since it was last started
+	Chance      float64 // the chance of a match in Attempts key pairs, 0 to 1
+	Probability float64 // the chance a single key pair matches
+}
+
+// Handler is implemented by the app; its methods are called from go-routines of the search, never two at once, so an
+// app hands them to its main thread before touching its views
+type Handler interface {
+	OnMatch(match *Match)          // called for every match
+	OnProgress(progress *Progress) // called every Options.ProgressMillis and once more when the search stops
+	OnStop(reason string)          // called once the workers stopped, with "" after Stop or the Limit, the error otherwise
+}
+
+// Search looks for the patterns until it is stopped; it can be started again after OnStop
+type Search struct {
+	matcher  *vanity.Matcher
+	search   *vanity.Search
+	limit    int64
+	every    time.Duration
+	mu       sync.Mutex
+	stop     chan string // asks the running search to stop, nil while stopped
+	matches  int64       // guarded by mu
+	attempts int64       // the key pairs generated when the search was last started
+}
+
+// NewSearch returns a stopped search for patterns, separated by commas, rejecting a pattern that can never appear in an
+// address; options may be nil for the defaults
+func NewSearch(patterns string, options *Options) (*Search, error) {
+	if options == nil {
+		options = NewOptions()
+	}
+	var list []string
+	for _, pattern := range strings.Split(patterns, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			list = append(list, pattern)
+		}
+	}
+	if len(list) == 0 {
+		return nil, errors.New("expected at least one pattern")
+	}
+	matcher, err := vanity.NewMatcher(list, vanity.Options{Position: options.Position, Lowercase: options.Lowercase,
+		Prefix: options.Prefix, Suffix: options.Suffix})
+	if err != nil {
+		return nil, err
+	}
+	every := time.Duration(options.ProgressMillis) * time.Millisecond
+	if every <= 0 {
+		every = time.Second
+	}
+	return &Search{matcher: matcher, search: vanity.NewSearch(matcher, options.Workers),
+		limit: int64(max(options.Limit, 0)), every: every}, nil
+}
+
+// Probability returns the chance a single key pair matches, for showing how long a search takes before starting it
+func (s *Search) Probability() float64 {
+	return s.matcher.Probability()
+}
+
+// Start starts the workers, which call handler until the search stops; it returns at once
+func (s *Search) Start(handler Handler) error {
+	if handler == nil {
+		return errors.New("expected a handler")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil {
+		return errors.New("the search is already running")
+	}
+	stop := make(chan string, 1) // the first reason to stop wins
+	request := func(reason string) {
+		select {
+		case stop <- reason:
+		default:
+		}
+	}
+	var callbacks sync.Mutex // OnProgress and OnStop are called from the go-routine below, OnMatch from the workers
+	var found int64          // matches since this start, the workers call back one at a time
+	err := s.search.Start(func(match vanity.Match) {
+		callbacks.Lock()
+		handler.OnMatch(&Match{Address: match.Address, Seed: match.Seed, Pattern: match.Pattern, Attempts: match.Attempts,
+			FoundAt: match.FoundAt.UnixMilli()})
+		callbacks.Unlock()
+		s.mu.Lock()
+		s.matches++
+		s.mu.Unlock()
+		if found++; s.limit > 0 && found >= s.limit {
+			request("")
+		}
+	}, func(err error) {
+		request(err.Error())
+	})
+	if err != nil {
+		return err
+	}
+	s.stop = stop
+	s.attempts = s.search.Attempts()
+	started := time.Now()
+	go func() {
+		ticker := time.NewTicker(s.every)
+		defer ticker.Stop()
+		progress := func() {
+			callbacks.Lock()
+			handler.OnProgress(s.progress(started))
+			callbacks.Unlock()
+		}
+		for {
+			select {
+			case <-ticker.C:
+				progress()
+			case reason := <-stop:
+				s.search.Stop()
+				s.mu.Lock()
+				s.stop = nil
+				s.mu.Unlock()
+				progress()
+				callbacks.Lock()
+				handler.OnStop(reason)
+				callbacks.Unlock()
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop asks the running search to stop and returns at once, without waiting for OnStop, so it can be called from the
+// main thread and from OnMatch alike
+func (s *Search) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil {
+		select {
+		case s.stop <- "":
+		default:
+		}
+	}
+}
+
+// Running reports whether the search runs, until OnStop is about to be called
+func (s *Search) Running() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stop != nil
+}
+
+// progress returns the progress of the search started at started
+func (s *Search) progress(started time.Time) *Progress {
+	s.mu.Lock()
+	matches, from := s.matches, s.attempts
+	s.mu.Unlock()
+	attempts := s.search.Attempts()
+	seconds := time.Since(started).Seconds()
+	p := &Progress{Attempts: attempts, Matches: matches, Seconds: seconds, Probability: s.matcher.Probability()}
+	if seconds > 0 {
+		p.Rate = float64(attempts-from) / seconds
+	}
+	if p.Probability >= 1 {
+		p.Chance = 1
+	} else {
+		p.Chance = -math.Expm1(float64(attempts) * math.Log1p(-p.Probability))
+	}
+	return p
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"                            // the events are published as JSON
+	"fmt"                                      // used for naming the client and wrapping errors
+	mqtt "github.com/eclipse/paho.mqtt.golang" // the MQTT client publishing to -mqtt
+	"strings"                                  // used for cleaning up -mqtt-topic
+	"time"                                     // used for the timeouts of the broker
+)
+
+// mqttTimeout is how long connecting to the -mqtt broker, and every publish, may take
+const mqttTimeout = 10 * time.Second
+
+// matchEvent is what a match is published as, the address only unless the seed was asked for
+type matchEvent struct {
+	Address     string    `json:"address"`                // the matching address
+	Seed        string    `json:"seed,omitempty"`         // its seed, only with -mqtt-seeds
+	Pattern     string    `json:"pattern,omitempty"`      // the pattern it matched
+	Field       string    `json:"field,omitempty"`        // where Pattern was found, address or seed
+	SeedPattern string    `json:"seed_pattern,omitempty"` // with -find and -find-seed together, the pattern the seed matched
+	Attempts    int64     `json:"attempts,omitempty"`     // addresses scanned by the run when it was found
+	FoundAt     time.Time `json:"found_at"`               // when it was found
+	Hostname    string    `json:"hostname,omitempty"`     // the machine that found it
+}
+
+// newMatchEvent returns the event of r, with its seed only when withSeed is set
+func newMatchEvent(r result, withSeed bool) matchEvent {
+	event := matchEvent{Address: r.Address, Pattern: r.Pattern, Field: r.Field, SeedPattern: r.SeedPattern,
+		Attempts: r.Attempts, FoundAt: r.FoundAt, Hostname: r.Hostname}
+	if withSeed {
+		event.Seed = r.Seed
+	}
+	return event
+}
+
+// mqttPublisher publishes every match to <topic>/match and the progress of the search to <topic>/stats on the -mqtt
+// broker, so a home automation dashboard can follow the hunt
+type mqttPublisher struct {
+	client   mqtt.Client
+	topic    string // -mqtt-topic without the /# a subscriber would add
+	withSeed bool   // -mqtt-seeds, the match events carry the seed too
+}
+
+// newMQTTPublisher connects to the broker at url, tcp://host:1883 or ssl://host:8883 with the credentials in its user
+// info if it needs any, reconnecting on its own whenever the connection drops
+func newMQTTPublisher(url, topic, hostname string, pid int, withSeed bool) (*mqttPublisher, error) {
+	topic = strings.TrimSuffix(strings.TrimSuffix(topic, "#"), "/")
+	if topic == "" || strings.ContainsAny(topic, "#+") {
+		return nil, fmt.Errorf("invalid -mqtt-topic %q: expected a topic like vanity, the events go to vanity/match and vanity/stats", topic)
+	}
+	options := mqtt.NewClientOptions().AddBroker(url).SetClientID(fmt.Sprintf("xlm-vanity-%s-%d", hostname, pid)).
+		SetAutoReconnect(true).SetConnectTimeout(mqttTimeout)
+	client := mqtt.NewClient(options)
+	if token := client.Connect(); !token.WaitTimeout(mqttTimeout) {
+		client.Disconnect(0)
+		return nil, fmt.Errorf("no answer from %s within %s", url, mqttTimeout)
+	} else if token.Error() != nil {
+		return nil, token.Error()
+	}
+	return &mqttPublisher{client: client, topic: topic, withSeed: withSeed}, nil
+}
+
+// Match publishes r to <topic>/match, the seed left out unless -mqtt-seeds is set
+func (p *mqttPublisher) Match(r result) error {
+	return p.publish(p.topic+"/match", false, newMatchEvent(r, p.withSeed))
+}
+
+// Stats publishes snapshot to <topic>/stats, retained so a dashboard that subscribes later gets the latest right away
+func (p *mqttPublisher) Stats(snapshot statsSnapshot) error {
+	return p.publish(p.topic+"/stats", true, snapshot)
+}
+
+// publish sends v as JSON to topic with QoS 1, waiting at most mqttTimeout for the broker to take it
+func (p *mqttPublisher) publish(topic string, retained bool, v any) error {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	token := p.client.Publish(topic, 1, retained, payload)
+	if !token.WaitTimeout(mqttTimeout) {
+		return fmt.Errorf("publishing to %s timed out", topic)
+	}
+	return token.Error()
+}
+
+// Close disconnects from the broker, giving what is still being published a moment to go out
+func (p *mqttPublisher) Close() {
+	p.client.Disconnect(250)
+}
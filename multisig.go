@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"                                         // used for wrapping errors with the step that failed
+	"github.com/stellar/go/clients/horizonclient" // used for loading the sequence number of an existing account
+	"github.com/stellar/go/txnbuild"              // used for building the SetOptions transaction
+)
+
+// multisigSetupXDR builds the unsigned SetOptions transaction for address that adds signer with signerWeight and lowers
+// the vanity key's master weight to masterWeight, bringing the account under an existing signing setup; the base64
+// envelope still has to be signed with the found seed before it is submitted, after the follow-up of r when it has one,
+// since the master key may not be able to sign that one anymore
+func multisigSetupXDR(n stellarNetwork, r result, signer string, signerWeight, masterWeight int) (string, error) {
+	sequence, err := accountSequence(n, r)
+	if err != nil {
+		return "", err
+	}
+	if r.FollowUpXDR != "" {
+		sequence++ // the follow-up takes the next sequence number
+	}
+	tx, err := txnbuild.NewTransaction(txnbuild.TransactionParams{
+		SourceAccount:        &txnbuild.SimpleAccount{AccountID: r.Address, Sequence: sequence},
+		IncrementSequenceNum: true,
+		BaseFee:              txnbuild.MinBaseFee,
+		Preconditions:        txnbuild.Preconditions{TimeBounds: txnbuild.NewInfiniteTimeout()}, // signed at leisure
+		Operations: []txnbuild.Operation{&txnbuild.SetOptions{
+			Signer:       &txnbuild.Signer{Address: signer, Weight: txnbuild.Threshold(signerWeight)},
+			MasterWeight: txnbuild.NewThreshold(txnbuild.Threshold(masterWeight)),
+		}},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to build transaction: %w", err)
+	}
+	return tx.Base64()
+}
+
+// accountSequence returns the current sequence number of r's account: an account funded by this run starts at the
+// funding ledger shifted left 32 bits, anything else is loaded from Horizon
+func accountSequence(n stellarNetwork, r result) (int64, error) {
+	if r.Funding != nil && r.Funding.Error == "" && r.Funding.Ledger > 0 {
+		return int64(r.Funding.Ledger) << 32, nil
+	}
+	account, err := n.Horizon.AccountDetail(horizonclient.AccountRequest{AccountID: r.Address})
+	if err != nil {
+		return 0, fmt.Errorf("failed to load account (fund it first): %w", err)
+	}
+	return account.Sequence, nil
+}
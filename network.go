@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"                                         // used for reporting an unknown -network
+	"github.com/stellar/go/clients/horizonclient" // used for talking to Horizon on the selected -network
+	"github.com/stellar/go/network"               // the network passphrases used when signing transactions
+	"sort"                                        // used for listing the known networks in a stable order
+	"strings"                                     // used for matching -network case-insensitively
+)
+
+// stellarNetwork is a Stellar network the finder can talk to, selected by -network
+type stellarNetwork struct {
+	Name       string                // the -network value
+	Passphrase string                // signs transactions for this network
+	Horizon    *horizonclient.Client // the public Horizon instance of this network
+}
+
+// networks are the values accepted by -network
+var networks = map[string]stellarNetwork{
+	"public":  {Name: "public", Passphrase: network.PublicNetworkPassphrase, Horizon: horizonclient.DefaultPublicNetClient},
+	"testnet": {Name: "testnet", Passphrase: network.TestNetworkPassphrase, Horizon: horizonclient.DefaultTestNetClient},
+}
+
+// lookupNetwork returns the stellarNetwork named by -network
+func lookupNetwork(name string) (stellarNetwork, error) {
+	if n, ok := networks[strings.ToLower(name)]; ok {
+		return n, nil
+	}
+	names := make([]string, 0, len(networks))
+	for known := range networks {
+		names = append(names, known)
+	}
+	sort.Strings(names)
+	return stellarNetwork{}, fmt.Errorf("unknown -network %q, expected one of %s", name, strings.Join(names, ", "))
+}
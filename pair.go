@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"                           // used for describing an invalid -pair
+	"github.com/stellar/go/keypair" // the halves of a pair
+	"log"                           // used for reporting the first half of a -pair
+	"sync"                          // the workers offer their keys at once
+)
+
+// maxPairSuffix is the longest -pair-suffix, whose pair takes about 1.3 million addresses the search must remember
+const maxPairSuffix = 8
+
+// pairSearch finds two addresses that belong together, one for each of the two -pair patterns, or two sharing the last
+// -pair-suffix characters, or both; the workers offer it their keys and it holds the first halves until a second one
+// completes a pair
+type pairSearch struct {
+	patterns []string // the two -pair patterns, upper-cased like those of the matcher, none with -pair-suffix alone
+	suffix   int      // the -pair-suffix, 0 when any two -pair matches make a pair
+
+	mu   sync.Mutex
+	held map[string]map[string]*keypair.Full // the first halves, by the pattern they matched and their suffix
+	done bool                                // a pair was completed, the search is over
+}
+
+// newPairSearch returns the search of the -pair patterns, two separated by a comma or none, and -pair-suffix
+func newPairSearch(patterns string, suffix int) (*pairSearch, error) {
+	p := &pairSearch{suffix: suffix, held: make(map[string]map[string]*keypair.Full)}
+	if patterns != "" {
+		p.patterns = parsePatterns(patterns)
+		if len(p.patterns) != 2 || p.patterns[0] == p.patterns[1] {
+			return nil, fmt.Errorf("invalid -pair %q: expected two different patterns separated by a comma", patterns)
+		}
+	}
+	if suffix < 0 || suffix > maxPairSuffix {
+		return nil, fmt.Errorf("invalid -pair-suffix %d: expected 1 to %d characters", suffix, maxPairSuffix)
+	}
+	if len(p.patterns) == 0 && suffix == 0 {
+		return nil, fmt.Errorf("a pair needs -pair patterns, a -pair-suffix or both")
+	}
+	return p, nil
+}
+
+// Offer matches pair against m like MatchPair does and completes a pair with it when it can: it returns what pair
+// matched, the first half and what that one matched, and whether the pair is complete; a pair that doesn't complete one
+// is held as a first half when no other holds its place
+func (p *pairSearch) Offer(m *matcher, pair *keypair.Full) (matched string, partner *keypair.Full, partnerMatched string, complete bool) {
+	role, other := "", ""
+	if len(p.patterns) > 0 {
+		pattern, _, found := m.MatchPair(pair)
+		if !found {
+			return "", nil, "", false
+		}
+		role, other = pattern, p.patterns[0]
+		if role == other {
+			other = p.patterns[1]
+		}
+	}
+	address := pair.Address()
+	key := address[len(address)-p.suffix:] // the shared suffix, empty without -pair-suffix
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.done {
+		return "", nil, "", false
+	}
+	if partner, complete = p.held[other][key]; complete {
+		p.done, p.held = true, nil // the other first halves are no use anymore
+		return p.name(role, key), partner, p.name(other, key), true
+	}
+	if p.held[role] == nil {
+		p.held[role] = make(map[string]*keypair.Full)
+	}
+	if _, ok := p.held[role][key]; !ok {
+		if len(p.held[role]) == 0 && role != "" {
+			log.Printf("Found the %s half of the -pair, still searching for %s", role, other)
+		}
+		p.held[role][key] = pair
+	}
+	return "", nil, "", false
+}
+
+// name is what a half matched, its -pair pattern, or with -pair-suffix alone the suffix it shares with the other half
+func (p *pairSearch) name(pattern, key string) string {
+	if pattern != "" {
+		return pattern
+	}
+	return key
+}
+
+// pairHalf is one of the two addresses of a pair, saved as a result of its own that names the other one
+type pairHalf struct {
+	pair    *keypair.Full
+	matched string
+	partner string // the address of the other half, empty outside of a pair
+}
+
+// pairHalves returns the halves of the pair that pair completed with partner, the first half first, or pair alone when
+// partner is nil
+func pairHalves(partner *keypair.Full, partnerMatched string, pair *keypair.Full, matched string) []pairHalf {
+	if partner == nil {
+		return []pairHalf{{pair: pair, matched: matched}}
+	}
+	return []pairHalf{{pair: partner, matched: partnerMatched, partner: pair.Address()},
+		{pair: pair, matched: matched, partner: partner.Address()}}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"           // used for feeding items to the CLIs on STDIN
+	"encoding/base64" // bw expects its items base64 encoded
+	"encoding/json"   // used for the item templates and the created items
+	"errors"          // used for reporting a locked vault
+	"fmt"             // used for naming the items and wrapping errors
+	"os/exec"         // the op and bw CLIs do the talking to 1Password and Bitwarden
+	"strings"         // used for reporting the output of a failed CLI
+	"time"            // used for the date tag of every item
+)
+
+// the -store values of the password manager CLIs
+const (
+	storeOnePassword = "op" // seeds become 1Password login items through the op CLI
+	storeBitwarden   = "bw" // seeds become Bitwarden login items through the bw CLI
+)
+
+// passwordManagerBackend creates a login item per found seed with the op or bw CLI, already signed in (op) or unlocked
+// with BW_SESSION set (bw); items are piped in on STDIN so a seed never shows up in the process list
+type passwordManagerBackend struct {
+	cli   string // storeOnePassword or storeBitwarden, also the name of the executable
+	vault string // the 1Password vault (-store-vault), the default vault of the account when empty
+}
+
+// openPasswordManager checks that cli is installed and ready, a locked vault must fail now and not at the first match
+func openPasswordManager(cli, vault string) (*passwordManagerBackend, error) {
+	if _, err := exec.LookPath(cli); err != nil {
+		return nil, fmt.Errorf("-store %s: %w", cli, err)
+	}
+	p := &passwordManagerBackend{cli: cli, vault: vault}
+	switch cli {
+	case storeOnePassword:
+		if _, err := p.run(nil, "whoami"); err != nil {
+			return nil, fmt.Errorf("-store op: not signed in, run `op signin` first: %w", err)
+		}
+	case storeBitwarden:
+		out, err := p.run(nil, "status")
+		if err != nil {
+			return nil, fmt.Errorf("-store bw: %w", err)
+		}
+		var status struct {
+			Status string `json:"status"`
+		}
+		if err := json.Unmarshal(out, &status); err != nil || status.Status != "unlocked" {
+			return nil, errors.New("-store bw: the vault is not unlocked, run `export BW_SESSION=$(bw unlock --raw)` first")
+		}
+	}
+	return p, nil
+}
+
+// Store creates a login item named after the address of r, with the seed as its password and tagged with the pattern
+// and date, and leaves a reference to the item instead of the seed
+func (p *passwordManagerBackend) Store(r *result) error {
+	title := "Stellar " + r.Address
+	notes := fmt.Sprintf("Vanity address matching %s, found %s on %s", r.Pattern, r.FoundAt.Format(time.RFC3339), r.Hostname)
+
+	var (
+		item []byte
+		args []string
+		err  error
+	)
+	switch p.cli {
+	case storeOnePassword:
+		item, err = json.Marshal(map[string]any{
+			"title":    title,
+			"category": "LOGIN",
+			"tags":     []string{"xlm-vanity", r.Pattern, r.FoundAt.Format(time.DateOnly)},
+			"fields": []map[string]string{
+				{"id": "username", "type": "STRING", "purpose": "USERNAME", "label": "username", "value": r.Address},
+				{"id": "password", "type": "CONCEALED", "purpose": "PASSWORD", "label": "password", "value": r.Seed},
+				{"id": "notesPlain", "type": "STRING", "purpose": "NOTES", "label": "notesPlain", "value": notes},
+			},
+		})
+		args = []string{"item", "create", "--format", "json"}
+		if p.vault != "" {
+			args = append(args, "--vault", p.vault)
+		}
+	case storeBitwarden: // bw has no tags, the pattern and date go into the name and notes
+		var encoded []byte
+		encoded, err = json.Marshal(map[string]any{
+			"type":  1, // login
+			"name":  fmt.Sprintf("%s (%s, %s)", title, r.Pattern, r.FoundAt.Format(time.DateOnly)),
+			"notes": notes,
+			"login": map[string]string{"username": r.Address, "password": r.Seed},
+		})
+		item = []byte(base64.StdEncoding.EncodeToString(encoded))
+		zeroize(encoded)
+		args = []string{"create", "item"}
+	}
+	if err != nil {
+		return err
+	}
+	defer zeroize(item)
+
+	out, err := p.run(item, args...)
+	if err != nil {
+		return fmt.Errorf("failed to create the %s item of %s: %w", p.cli, r.Address, err)
+	}
+	var created struct {
+		ID    string `json:"id"`
+		Vault struct {
+			ID string `json:"id"`
+		} `json:"vault"` // only op reports the vault the item landed in
+	}
+	if err := json.Unmarshal(out, &created); err != nil || created.ID == "" {
+		return fmt.Errorf("unexpected output from %s after creating the item of %s", p.cli, r.Address)
+	}
+
+	if p.cli == storeOnePassword {
+		r.SeedRef = "op://" + created.Vault.ID + "/" + created.ID + "/password" // a secret reference `op read` understands
+	} else {
+		r.SeedRef = "bw://" + created.ID
+	}
+	r.Seed = ""
+	return nil
+}
+
+// run executes the CLI with args and stdin, returning its STDOUT
+func (p *passwordManagerBackend) run(stdin []byte, args ...string) ([]byte, error) {
+	cmd := exec.Command(p.cli, args...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("%w: %s", err, message)
+		}
+		return nil, err
+	}
+	return out, nil
+}
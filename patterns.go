@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bufio"   // used for reading the patterns one per line
+	"bytes"   // used for reading the -patterns file from memory
+	"fmt"     // used for wrapping errors with the -patterns path
+	"io"      // the patterns come from a file or STDIN
+	"os"      // access the filesystem
+	"strings" // used for cleaning up the patterns
+	"time"    // used for polling the -patterns file
+)
+
+// patternsStdin is the -patterns that reads the patterns from STDIN instead of a file, as they come
+const patternsStdin = "-"
+
+// patternsEvery is how often the -patterns file is read again, a ConfigMap mounted as a volume is updated in place
+// within a minute or so of being edited
+const patternsEvery = 10 * time.Second
+
+// patternLine returns the pattern of a line of -patterns, empty for a blank line or a comment starting with #
+func patternLine(line string) string {
+	line, _, _ = strings.Cut(line, "#")
+	return strings.TrimSpace(line)
+}
+
+// readPatternsFile returns the patterns of the -patterns file at path, one per line, along with its content to tell
+// later whether it changed
+func readPatternsFile(path string) ([]string, []byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	var patterns []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if pattern := patternLine(scanner.Text()); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(patterns) == 0 {
+		return nil, nil, fmt.Errorf("%s has no patterns", path)
+	}
+	return patterns, data, nil
+}
+
+// streamPatterns sends the patterns of r, one per line, as they are written to it, and closes the channel at its end;
+// a pipeline generating candidate words can keep a search busy for as long as it writes
+func streamPatterns(r io.Reader, colors palette) <-chan string {
+	patterns := make(chan string)
+	go func() {
+		defer close(patterns)
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			if pattern := patternLine(scanner.Text()); pattern != "" {
+				patterns <- pattern
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			colors.warnf("-patterns %s stopped reading: %v", patternsStdin, err)
+		}
+	}()
+	return patterns
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"log"         // used for announcing pauses and resumes
+	"sync/atomic" // the workers read the pause reasons while the watchers write them
+)
+
+// the reasons the workers are paused, bits of the pause flag every throttle reads; they resume once none is left
+const (
+	pauseBattery uint32 = 1 << iota // -pause-on-battery, while the machine runs on battery
+	pauseRequest                    // SIGUSR1 or POST /pause, until SIGUSR2 or POST /resume
+)
+
+// pauseWorkers adds reason to paused and reports whether the workers were running before
+func pauseWorkers(paused *atomic.Uint32, reason uint32) bool {
+	return paused.Or(reason) == 0
+}
+
+// resumeWorkers takes reason out of paused and reports whether the workers run again, they don't while another
+// reason is left or when reason wasn't set
+func resumeWorkers(paused *atomic.Uint32, reason uint32) bool {
+	was := paused.And(^reason)
+	return was&reason != 0 && was&^reason == 0
+}
+
+// requestPause pauses the workers for SIGUSR1 or POST /pause, by says which, and reports whether they were running
+func requestPause(paused *atomic.Uint32, by string) bool {
+	if paused.Load()&pauseRequest != 0 {
+		debugf("%s: the workers are already paused", by)
+		return false
+	}
+	running := pauseWorkers(paused, pauseRequest)
+	log.Printf("Paused by %s, the workers hold on to their counters until SIGUSR2 or POST /resume", by)
+	return running
+}
+
+// requestResume resumes the workers paused by requestPause for SIGUSR2 or POST /resume, by says which, and reports
+// whether they run again
+func requestResume(paused *atomic.Uint32, by string) bool {
+	if paused.Load()&pauseRequest == 0 {
+		debugf("%s: the workers weren't paused", by)
+		return false
+	}
+	if resumeWorkers(paused, pauseRequest) {
+		log.Printf("Resumed by %s", by)
+		return true
+	}
+	log.Printf("Resumed by %s, but the workers stay paused while running on battery", by)
+	return false
+}
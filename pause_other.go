@@ -0,0 +1,10 @@
+//go:build !unix
+
+package main
+
+import "os" // the signals are delivered as os.Signal
+
+// notifyPause returns no channels, there is no SIGUSR1 or SIGUSR2 here; POST /pause and POST /resume still work
+func notifyPause() (<-chan os.Signal, <-chan os.Signal) {
+	return nil, nil
+}
@@ -0,0 +1,17 @@
+//go:build unix
+
+package main
+
+import (
+	"os"        // the signals are delivered as os.Signal
+	"os/signal" // used for catching SIGUSR1 and SIGUSR2
+	"syscall"   // used for naming SIGUSR1 and SIGUSR2
+)
+
+// notifyPause returns the channels SIGUSR1, pausing the workers, and SIGUSR2, resuming them, are delivered on
+func notifyPause() (<-chan os.Signal, <-chan os.Signal) {
+	pause, resume := make(chan os.Signal, 1), make(chan os.Signal, 1)
+	signal.Notify(pause, syscall.SIGUSR1)
+	signal.Notify(resume, syscall.SIGUSR2)
+	return pause, resume
+}
@@ -0,0 +1,17 @@
+package main
+
+import "errors" // used for reporting platforms without CPU affinity
+
+// errPinUnsupported is returned by planCPUs on platforms that can't pin a thread to a CPU
+var errPinUnsupported = errors.New("pinning workers to CPUs is only supported on Linux")
+
+// cpuPlan is the CPU every worker is pinned to with -pin-cpus, worker i runs on cpus[i%len(cpus)]
+type cpuPlan struct {
+	cpus  []int // the CPUs the process may run on, spread across the NUMA nodes and cores
+	nodes int   // the NUMA nodes they belong to, 1 on most machines
+}
+
+// CPU returns the CPU worker is pinned to
+func (p *cpuPlan) CPU(worker int) int {
+	return p.cpus[worker%len(p.cpus)]
+}
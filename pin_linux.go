@@ -0,0 +1,117 @@
+//go:build linux
+
+package main
+
+import (
+	"fmt"                   // used for naming the sysfs files of a CPU
+	"golang.org/x/sys/unix" // used for reading and setting the CPU affinity
+	"os"                    // access the filesystem
+	"path/filepath"         // used for listing the NUMA nodes
+	"runtime"               // used for locking a worker to its thread
+	"slices"                // used for ordering the CPUs of a node
+	"strconv"               // used for parsing the CPU lists of sysfs
+	"strings"               // used for splitting the CPU lists of sysfs
+)
+
+// numaNodes is where the kernel lists the NUMA nodes and their CPUs
+const numaNodes = "/sys/devices/system/node"
+
+// planCPUs returns the CPUs of -pin-cpus: those the process may run on (a taskset or a cgroup cpuset narrows them
+// down), taking one CPU of every NUMA node in turn, so the workers and the memory they first touch spread over every
+// socket, and the first hyper-thread of every core before its siblings
+func planCPUs() (*cpuPlan, error) {
+	var allowed unix.CPUSet
+	if err := unix.SchedGetaffinity(0, &allowed); err != nil {
+		return nil, fmt.Errorf("failed to read the CPU affinity: %w", err)
+	}
+	nodes := cpusByNode(&allowed)
+	plan := &cpuPlan{nodes: len(nodes)}
+	for round := 0; ; round++ {
+		taken := false
+		for _, cpus := range nodes {
+			if round < len(cpus) {
+				plan.cpus, taken = append(plan.cpus, cpus[round]), true
+			}
+		}
+		if !taken {
+			break
+		}
+	}
+	if len(plan.cpus) == 0 {
+		return nil, fmt.Errorf("no CPU to pin to")
+	}
+	return plan, nil
+}
+
+// cpusByNode returns the allowed CPUs of every NUMA node, first hyper-threads first; one node of every allowed CPU when
+// the kernel doesn't list the nodes
+func cpusByNode(allowed *unix.CPUSet) [][]int {
+	var nodes [][]int
+	lists, _ := filepath.Glob(filepath.Join(numaNodes, "node[0-9]*", "cpulist"))
+	for _, list := range lists {
+		if cpus := allowedCPUs(readCPUList(list), allowed); len(cpus) > 0 {
+			nodes = append(nodes, cpus)
+		}
+	}
+	if len(nodes) == 0 {
+		var all []int
+		for cpu := 0; len(all) < allowed.Count(); cpu++ {
+			if allowed.IsSet(cpu) {
+				all = append(all, cpu)
+			}
+		}
+		nodes = append(nodes, all)
+	}
+	for _, cpus := range nodes {
+		slices.SortStableFunc(cpus, func(a, b int) int { // a core's first hyper-thread before the second one of any core
+			return siblingRank(a) - siblingRank(b)
+		})
+	}
+	return nodes
+}
+
+// allowedCPUs returns the cpus in allowed, in order
+func allowedCPUs(cpus []int, allowed *unix.CPUSet) []int {
+	return slices.DeleteFunc(cpus, func(cpu int) bool { return !allowed.IsSet(cpu) })
+}
+
+// siblingRank returns which hyper-thread of its core cpu is, 0 for the first or when the kernel doesn't say
+func siblingRank(cpu int) int {
+	siblings := readCPUList(fmt.Sprintf("/sys/devices/system/cpu/cpu%d/topology/thread_siblings_list", cpu))
+	return max(0, slices.Index(siblings, cpu))
+}
+
+// readCPUList parses a sysfs CPU list like 0-3,8-11, nothing when it can't be read
+func readCPUList(path string) []int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var cpus []int
+	for _, part := range strings.Split(strings.TrimSpace(string(data)), ",") {
+		first, last, isRange := strings.Cut(part, "-")
+		low, err := strconv.Atoi(first)
+		if err != nil {
+			continue
+		}
+		high := low
+		if isRange {
+			if high, err = strconv.Atoi(last); err != nil {
+				continue
+			}
+		}
+		for cpu := low; cpu <= high; cpu++ {
+			cpus = append(cpus, cpu)
+		}
+	}
+	return cpus
+}
+
+// Pin locks the calling worker to its thread and the thread to the CPU of worker; the thread ends with the worker
+// rather than going back to the Go scheduler still pinned
+func (p *cpuPlan) Pin(worker int) error {
+	runtime.LockOSThread()
+	var set unix.CPUSet
+	set.Set(p.CPU(worker))
+	return unix.SchedSetaffinity(0, &set)
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package main
+
+// planCPUs can't pin threads to CPUs on this platform
+func planCPUs() (*cpuPlan, error) {
+	return nil, errPinUnsupported
+}
+
+// Pin is never called, planCPUs returns no plan here
+func (p *cpuPlan) Pin(int) error {
+	return errPinUnsupported
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"                                                      // wazero runs every call under a context
+	"errors"                                                       // used for describing a plugin that doesn't fit
+	"fmt"                                                          // used for wrapping errors with the plugin path
+	"github.com/tetratelabs/wazero"                                // the WebAssembly runtime of -matcher-plugin
+	"github.com/tetratelabs/wazero/api"                            // the exports of a plugin
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1" // plugins built for wasip1 import WASI
+	"log"                                                          // used for reporting a plugin that failed once
+	"math"                                                         // the probability of a plugin is returned as the bits of an f64
+	"os"                                                           // access the filesystem
+	"path/filepath"                                                // used for naming the plugin after its file
+	"strings"                                                      // used for naming the plugin after its file
+	"sync"                                                         // used for reporting a failed plugin once
+)
+
+// matcherPlugin is a criterion an address must meet that -find can't express, like a checksum trick or a score of how
+// random the address looks, loaded with -matcher-plugin; Match is called from every worker at once
+type matcherPlugin interface {
+	Name() string              // the pattern of its results and the name of its default output file
+	Probability() float64      // the chance a single address matches, 0 when the plugin doesn't know
+	Match(address string) bool // reports whether address meets the criterion
+	Close() error              // releases what the plugin holds
+}
+
+// the exports of a WebAssembly -matcher-plugin: the host writes every address into the 56 bytes at the offset address
+// returns, then calls match, which returns anything but 0 for a match; probability is optional
+const (
+	pluginAddress     = "address"
+	pluginMatch       = "match"
+	pluginProbability = "probability"
+)
+
+// wasmPlugin is a matcherPlugin compiled from a WebAssembly module; an instance only runs one call at a time, so every
+// worker takes one of its own from free, which grows to as many instances as workers call it at once
+type wasmPlugin struct {
+	name        string
+	probability float64
+	runtime     wazero.Runtime
+	compiled    wazero.CompiledModule
+	free        chan *wasmInstance // instances no worker is calling
+	failed      sync.Once          // a failing plugin is only reported once
+}
+
+// wasmInstance is an instance of the module of a wasmPlugin
+type wasmInstance struct {
+	module  api.Module
+	match   api.Function
+	address uint32   // the offset of the address in the memory of the instance
+	stack   []uint64 // the stack of match, reused for every call
+}
+
+// loadMatcherPlugin compiles the WebAssembly module at path, checking its exports, and asks it for its probability
+func loadMatcherPlugin(path string) (matcherPlugin, error) {
+	binary, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	p := &wasmPlugin{name: strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)), runtime: wazero.NewRuntime(ctx),
+		free: make(chan *wasmInstance, 256)}
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, p.runtime); err != nil {
+		return nil, errors.Join(err, p.runtime.Close(ctx))
+	}
+	if p.compiled, err = p.runtime.CompileModule(ctx, binary); err != nil {
+		return nil, errors.Join(fmt.Errorf("-matcher-plugin %s: %w", path, err), p.runtime.Close(ctx))
+	}
+	exports := p.compiled.ExportedFunctions()
+	for name, results := range map[string][]api.ValueType{pluginAddress: {api.ValueTypeI32}, pluginMatch: {api.ValueTypeI32}} {
+		if export, ok := exports[name]; !ok || len(export.ParamTypes()) != 0 || !equalTypes(export.ResultTypes(), results) {
+			return nil, errors.Join(fmt.Errorf("-matcher-plugin %s: expected an export %s() i32", path, name), p.runtime.Close(ctx))
+		}
+	}
+
+	instance, err := p.instantiate()
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("-matcher-plugin %s: %w", path, err), p.runtime.Close(ctx))
+	}
+	if export, ok := exports[pluginProbability]; ok {
+		if len(export.ParamTypes()) != 0 || !equalTypes(export.ResultTypes(), []api.ValueType{api.ValueTypeF64}) {
+			return nil, errors.Join(fmt.Errorf("-matcher-plugin %s: expected the export %s() f64", path, pluginProbability), p.runtime.Close(ctx))
+		}
+		results, err := instance.module.ExportedFunction(pluginProbability).Call(ctx)
+		if err != nil {
+			return nil, errors.Join(fmt.Errorf("-matcher-plugin %s: %s: %w", path, pluginProbability, err), p.runtime.Close(ctx))
+		}
+		if p.probability = math.Float64frombits(results[0]); p.probability < 0 || p.probability > 1 || math.IsNaN(p.probability) {
+			return nil, errors.Join(fmt.Errorf("-matcher-plugin %s: %s returned %g, expected 0 to 1", path, pluginProbability, p.probability),
+				p.runtime.Close(ctx))
+		}
+	}
+	p.free <- instance
+	return p, nil
+}
+
+// equalTypes reports whether a and b are the same value types
+func equalTypes(a, b []api.ValueType) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// instantiate starts another instance of the module, initializing a reactor module like a Go or TinyGo c-shared one
+func (p *wasmPlugin) instantiate() (*wasmInstance, error) {
+	ctx := context.Background()
+	module, err := p.runtime.InstantiateModule(ctx, p.compiled, wazero.NewModuleConfig().WithName("").WithStartFunctions("_initialize"))
+	if err != nil {
+		return nil, err
+	}
+	results, err := module.ExportedFunction(pluginAddress).Call(ctx)
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("%s: %w", pluginAddress, err), module.Close(ctx))
+	}
+	instance := &wasmInstance{module: module, match: module.ExportedFunction(pluginMatch), address: api.DecodeU32(results[0]),
+		stack: make([]uint64, 1)}
+	if module.Memory() == nil {
+		return nil, errors.Join(errors.New("expected an exported memory"), module.Close(ctx))
+	}
+	if _, ok := module.Memory().Read(instance.address, addressLength); !ok {
+		return nil, errors.Join(fmt.Errorf("%s returned %d, which is outside of its memory", pluginAddress, instance.address), module.Close(ctx))
+	}
+	return instance, nil
+}
+
+// Name returns the file name of the plugin without its extension
+func (p *wasmPlugin) Name() string {
+	return p.name
+}
+
+// Probability returns what the probability export returned, 0 without one
+func (p *wasmPlugin) Probability() float64 {
+	return p.probability
+}
+
+// Match writes address into an instance and calls match; a plugin that fails doesn't match, its instance is dropped
+// and the failure is reported once
+func (p *wasmPlugin) Match(address string) bool {
+	var instance *wasmInstance
+	select {
+	case instance = <-p.free:
+	default:
+		var err error
+		if instance, err = p.instantiate(); err != nil {
+			p.fail(err)
+			return false
+		}
+	}
+	memory, _ := instance.module.Memory().Read(instance.address, addressLength) // a view, it was in bounds when started
+	copy(memory, address)
+	if err := instance.match.CallWithStack(context.Background(), instance.stack); err != nil {
+		p.fail(err)
+		_ = instance.module.Close(context.Background())
+		return false
+	}
+	matched := api.DecodeI32(instance.stack[0]) != 0
+	select {
+	case p.free <- instance:
+	default: // more instances than ever run at once
+		_ = instance.module.Close(context.Background())
+	}
+	return matched
+}
+
+// fail reports the first failure of the plugin, every call that fails counts as no match
+func (p *wasmPlugin) fail(err error) {
+	p.failed.Do(func() {
+		log.Printf("-matcher-plugin %s failed, the addresses it fails on don't match: %v", p.name, err)
+	})
+}
+
+// Close closes every instance and the runtime
+func (p *wasmPlugin) Close() error {
+	return p.runtime.Close(context.Background())
+}
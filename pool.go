@@ -0,0 +1,592 @@
+package main
+
+import (
+	"bytes"         // used for sending request bodies
+	"context"       // the progress reports end with the search
+	"crypto/subtle" // used for comparing the pool token in constant time
+	"database/sql"  // pool.db is queried through database/sql
+	"encoding/json" // the pool API speaks JSON
+	"errors"        // used for telling a closed order apart from a failed request
+	"flag"          // the pool subcommand parses its own arguments
+	"fmt"           // used for wrapping errors with the order involved
+	"io"            // used for reading error responses
+	"log"           // used for logging the orders as they change hands
+	"net/http"      // the pool API
+	"os"            // access the filesystem and the environment
+	"os/signal"     // the pool server stops on SIGINT and SIGTERM
+	"path/filepath" // used for locating pool.db in the user config directory
+	"strconv"       // used for parsing the order ID of the paths
+	"strings"       // used for trimming the -pool URL
+	"syscall"       // used for catching SIGTERM
+	"time"          // used for the leases and the timestamps of the orders
+)
+
+// poolTokenEnv is the shared secret of a pool server and its workers: when set, claiming, reporting on and cancelling
+// orders needs it as a bearer token, submitting and reading orders never does
+const poolTokenEnv = "XLM_VANITY_POOL_TOKEN"
+
+// poolTimeout bounds every request to a pool server
+const poolTimeout = 30 * time.Second
+
+// poolReportEvery is how often a -pool worker reports its progress, renewing the lease on its order
+const poolReportEvery = 30 * time.Second
+
+// the status of a pool order
+const (
+	poolOpen      = "open"      // waiting for a worker, or for another one once the lease of the last one expired
+	poolSearching = "searching" // a worker holds a lease on it
+	poolFulfilled = "fulfilled" // a worker delivered a tweak the server checked
+	poolCancelled = "cancelled" // the operator of the pool cancelled it
+)
+
+// maxBounty is the longest bounty an order carries, a free text the pool only stores and shows
+const maxBounty = 256
+
+// poolSchema creates the table of pool.db; leased_until is a Unix time in seconds so leases compare as numbers
+const poolSchema = `
+CREATE TABLE IF NOT EXISTS orders (
+	id           INTEGER PRIMARY KEY AUTOINCREMENT,
+	pattern      TEXT    NOT NULL,
+	position     TEXT    NOT NULL,
+	split_key    TEXT    NOT NULL,
+	bounty       TEXT    NOT NULL DEFAULT '',
+	probability  REAL    NOT NULL,
+	created_at   TEXT    NOT NULL,
+	worker       TEXT    NOT NULL DEFAULT '',
+	leased_until INTEGER NOT NULL DEFAULT 0,
+	attempts     INTEGER NOT NULL DEFAULT 0,
+	address      TEXT    NOT NULL DEFAULT '',
+	tweak        TEXT    NOT NULL DEFAULT '',
+	fulfilled_at TEXT    NOT NULL DEFAULT '',
+	cancelled    INTEGER NOT NULL DEFAULT 0
+);`
+
+// poolColumns are the columns scanOrder reads, in its order
+const poolColumns = `id, pattern, position, split_key, bounty, probability, created_at, worker, leased_until, attempts, address, tweak,
+	fulfilled_at, cancelled`
+
+// poolOrder is an order of a pool: the pattern a customer wants for the key they hold the private part of, and once a
+// worker found it, the tweak that only they can combine with it
+type poolOrder struct {
+	ID          int64     `json:"id"`
+	Pattern     string    `json:"pattern"`
+	Position    string    `json:"position"`
+	SplitKey    string    `json:"split_key"`                   // the partial key of split-key new
+	Bounty      string    `json:"bounty,omitempty"`            // what the customer offers, stored and shown as is
+	Status      string    `json:"status"`                      // open, searching, fulfilled or cancelled
+	Expected    float64   `json:"expected_attempts,omitempty"` // the addresses a search takes on average
+	Attempts    int64     `json:"attempts"`                    // the addresses the workers reported scanning
+	Worker      string    `json:"worker,omitempty"`            // the last worker to claim it, or the one that fulfilled it
+	CreatedAt   time.Time `json:"created_at"`
+	Address     string    `json:"address,omitempty"`      // the vanity address, once fulfilled
+	Tweak       string    `json:"tweak,omitempty"`        // the tweak to pass to split-key combine, once fulfilled
+	FulfilledAt string    `json:"fulfilled_at,omitempty"` // when the tweak was delivered
+}
+
+// poolOrderRequest is the body of POST /orders
+type poolOrderRequest struct {
+	Pattern  string `json:"pattern"`            // one pattern, like those of -find
+	Position string `json:"position,omitempty"` // like -position, anywhere by default
+	SplitKey string `json:"split_key"`          // the G... partial key of split-key new
+	Bounty   string `json:"bounty,omitempty"`   // what the customer offers for it
+}
+
+// poolWorkerRequest is the body of POST /orders/claim, /orders/{id}/progress and /orders/{id}/tweak
+type poolWorkerRequest struct {
+	Worker   string `json:"worker"`             // who is asking, the hostname and PID of a -pool worker
+	Attempts int64  `json:"attempts,omitempty"` // with progress, the addresses scanned since the last report
+	Tweak    string `json:"tweak,omitempty"`    // with tweak, the hex tweak of the match
+}
+
+// errPoolOrderClosed is returned to a worker whose order was fulfilled, cancelled or leased to another worker
+var errPoolOrderClosed = errors.New("the order was fulfilled, cancelled or handed to another worker")
+
+// defaultPoolDB is where the pool keeps its orders when -db isn't set, next to runs.db
+func defaultPoolDB() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "pool.db"
+	}
+	return filepath.Join(dir, keyringService, "pool.db")
+}
+
+// openPoolDB opens path, creating it and its table when needed
+func openPoolDB(path string) (*sql.DB, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(poolSchema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to create the table of %s: %w", path, err)
+	}
+	return db, nil
+}
+
+// runPool implements `xlm-vanity-address-finder pool`, serving the orders of pool.db to the -pool workers until SIGINT
+// or SIGTERM; it returns the process exit code
+func runPool(args []string) int {
+	flags := flag.NewFlagSet("pool", flag.ExitOnError)
+	listen := flags.String("listen", "127.0.0.1:8090", "Address the pool API listens on")
+	path := flags.String("db", defaultPoolDB(), "Path of the orders database")
+	lease := flags.Int("lease", 120, "Seconds a worker keeps its order without reporting progress before another worker can claim it")
+	flags.Usage = func() {
+		_, _ = fmt.Fprintf(flags.Output(), "Usage: %s pool [-listen 127.0.0.1:8090] [-db pool.db] [-lease 120]\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args) // ExitOnError handles the error
+	if flags.NArg() != 0 || *lease < 1 {
+		flags.Usage()
+		return 2
+	}
+
+	db, err := openPoolDB(*path)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	defer func() { _ = db.Close() }()
+	p := &poolServer{db: db, token: os.Getenv(poolTokenEnv), lease: time.Duration(*lease) * time.Second}
+	if p.token == "" {
+		log.Printf("%s isn't set, anyone reaching %s can claim orders and cancelling them is disabled", poolTokenEnv, *listen)
+	}
+	server := &http.Server{Addr: *listen, Handler: p.handler(), ReadHeaderTimeout: 10 * time.Second}
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-stop
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(ctx)
+	}()
+	log.Printf("Serving the orders of %s on %s", *path, *listen)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		_, _ = fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// poolServer serves the orders of pool.db: customers submit and follow them, workers claim them, report their progress
+// and deliver the tweaks, which the server checks before fulfilling the order
+type poolServer struct {
+	db    *sql.DB
+	token string        // XLM_VANITY_POOL_TOKEN, empty when workers don't need one
+	lease time.Duration // how long a claim lasts without a progress report
+}
+
+// handler routes the pool API:
+//
+//	POST   /orders               submit an order, anyone
+//	GET    /orders               list the orders, ?status= filters them, anyone
+//	GET    /orders/{id}          follow an order, its tweak included once fulfilled, anyone
+//	POST   /orders/claim         claim the oldest open order, 204 when there is none, workers
+//	POST   /orders/{id}/progress report the addresses scanned and renew the lease, 409 once the order closed, workers
+//	POST   /orders/{id}/tweak    deliver a tweak, 422 when it doesn't match, 409 once the order closed, workers
+//	DELETE /orders/{id}          cancel an order, the operator only
+func (p *poolServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /orders", p.handleSubmit)
+	mux.HandleFunc("GET /orders", p.handleList)
+	mux.HandleFunc("GET /orders/{id}", p.handleOrder)
+	mux.HandleFunc("POST /orders/claim", p.authorized(p.handleClaim))
+	mux.HandleFunc("POST /orders/{id}/progress", p.authorized(p.handleProgress))
+	mux.HandleFunc("POST /orders/{id}/tweak", p.authorized(p.handleTweak))
+	mux.HandleFunc("DELETE /orders/{id}", p.authorized(p.handleCancel))
+	mux.HandleFunc("GET /healthz", handleHealthz)
+	return mux
+}
+
+// authorized lets a request through to next when it carries the pool token, or when there is none; cancelling always
+// needs one, or any customer could cancel the orders of the others
+func (p *poolServer) authorized(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if p.token == "" && r.Method != http.MethodDelete {
+			next(w, r)
+			return
+		}
+		given := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if p.token == "" || subtle.ConstantTimeCompare([]byte(given), []byte(p.token)) != 1 {
+			http.Error(w, "expected the "+poolTokenEnv+" bearer token", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// handleSubmit checks and stores a poolOrderRequest, answering with the order
+func (p *poolServer) handleSubmit(w http.ResponseWriter, r *http.Request) {
+	var body poolOrderRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<12)).Decode(&body); err != nil {
+		http.Error(w, `expected {"pattern":"...","split_key":"G..."}: `+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if body.Position == "" {
+		body.Position = positionAnywhere
+	}
+	m, err := poolMatcher(body.Pattern, body.Position)
+	if err == nil {
+		_, err = parseSplitKey(body.SplitKey)
+	}
+	if err == nil && len(body.Bounty) > maxBounty {
+		err = fmt.Errorf("expected a bounty of at most %d characters", maxBounty)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	inserted, err := p.db.Exec(`INSERT INTO orders (pattern, position, split_key, bounty, probability, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		m.patterns[0], body.Position, body.SplitKey, body.Bounty, m.probability, time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	id, _ := inserted.LastInsertId()
+	log.Printf("Order %d submitted: %s %s for %s", id, m.patterns[0], body.Position, body.SplitKey)
+	p.answer(w, http.StatusCreated, id)
+}
+
+// poolMatcher returns the matcher of the single pattern of an order
+func poolMatcher(pattern, position string) (*matcher, error) {
+	m, err := newMatcher(pattern, matchOptions{position: position})
+	if err != nil {
+		return nil, err
+	}
+	if len(m.patterns) != 1 || m.patterns[0] == "" {
+		return nil, errors.New("expected a single pattern per order")
+	}
+	return m, nil
+}
+
+// handleList answers with the orders, the newest first, only those of the status in ?status= when set
+func (p *poolServer) handleList(w http.ResponseWriter, r *http.Request) {
+	rows, err := p.db.Query(`SELECT ` + poolColumns + ` FROM orders ORDER BY id DESC LIMIT 1000`)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer func() { _ = rows.Close() }()
+	status := r.URL.Query().Get("status")
+	orders := make([]poolOrder, 0)
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if status == "" || order.Status == status {
+			orders = append(orders, order)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(orders)
+}
+
+// handleOrder answers with the order of the path
+func (p *poolServer) handleOrder(w http.ResponseWriter, r *http.Request) {
+	if id, ok := orderID(w, r); ok {
+		p.answer(w, http.StatusOK, id)
+	}
+}
+
+// handleClaim leases the oldest open order to the worker of the request, answering 204 when there is none
+func (p *poolServer) handleClaim(w http.ResponseWriter, r *http.Request) {
+	body, ok := decodeWorkerRequest(w, r)
+	if !ok {
+		return
+	}
+	now := time.Now()
+	var id int64
+	err := p.db.QueryRow(`UPDATE orders SET worker = ?, leased_until = ? WHERE id = (
+		SELECT id FROM orders WHERE tweak = '' AND cancelled = 0 AND leased_until < ? ORDER BY id LIMIT 1) RETURNING id`,
+		body.Worker, now.Add(p.lease).Unix(), now.Unix()).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	log.Printf("Order %d claimed by %s", id, body.Worker)
+	p.answer(w, http.StatusOK, id)
+}
+
+// handleProgress adds the attempts of the worker to its order and renews its lease
+func (p *poolServer) handleProgress(w http.ResponseWriter, r *http.Request) {
+	id, ok := orderID(w, r)
+	if !ok {
+		return
+	}
+	body, ok := decodeWorkerRequest(w, r)
+	if !ok {
+		return
+	}
+	updated, err := p.db.Exec(`UPDATE orders SET attempts = attempts + ?, leased_until = ? WHERE id = ? AND worker = ? AND tweak = '' AND cancelled = 0`,
+		max(body.Attempts, 0), time.Now().Add(p.lease).Unix(), id, body.Worker)
+	p.answerUpdate(w, id, updated, err)
+}
+
+// handleTweak checks the tweak a worker found against the order and fulfills it, whichever worker found it
+func (p *poolServer) handleTweak(w http.ResponseWriter, r *http.Request) {
+	id, ok := orderID(w, r)
+	if !ok {
+		return
+	}
+	body, ok := decodeWorkerRequest(w, r)
+	if !ok {
+		return
+	}
+	order, err := p.order(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "no such order", http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	address, err := checkPoolTweak(order, body.Tweak)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	updated, err := p.db.Exec(`UPDATE orders SET address = ?, tweak = ?, worker = ?, fulfilled_at = ? WHERE id = ? AND tweak = '' AND cancelled = 0`,
+		address, body.Tweak, body.Worker, time.Now().UTC().Format(time.RFC3339), id)
+	if err == nil {
+		log.Printf("Order %d fulfilled by %s: %s", id, body.Worker, address)
+	}
+	p.answerUpdate(w, id, updated, err)
+}
+
+// checkPoolTweak returns the address tweak turns the partial key of order into, when it matches the order
+func checkPoolTweak(order poolOrder, tweakHex string) (string, error) {
+	partial, err := parseSplitKey(order.SplitKey)
+	if err != nil {
+		return "", err
+	}
+	tweak, err := decodeScalar(tweakHex)
+	if err != nil {
+		return "", fmt.Errorf("invalid tweak %q: %w", tweakHex, err)
+	}
+	m, err := poolMatcher(order.Pattern, order.Position)
+	if err != nil {
+		return "", err
+	}
+	address := splitAddress(partial, tweak)
+	if _, found := m.Match(address); !found {
+		return "", fmt.Errorf("the tweak turns %s into %s, which doesn't match %s", order.SplitKey, address, order.Pattern)
+	}
+	return address, nil
+}
+
+// handleCancel cancels an order that isn't fulfilled yet, its workers stop on their next progress report
+func (p *poolServer) handleCancel(w http.ResponseWriter, r *http.Request) {
+	if id, ok := orderID(w, r); ok {
+		updated, err := p.db.Exec(`UPDATE orders SET cancelled = 1 WHERE id = ? AND tweak = '' AND cancelled = 0`, id)
+		if err == nil {
+			log.Printf("Order %d cancelled", id)
+		}
+		p.answerUpdate(w, id, updated, err)
+	}
+}
+
+// answerUpdate answers with the order updated, 404 when there is none and 409 when it is closed for the update
+func (p *poolServer) answerUpdate(w http.ResponseWriter, id int64, updated sql.Result, err error) {
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if rows, _ := updated.RowsAffected(); rows == 0 {
+		if _, err := p.order(id); errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "no such order", http.StatusNotFound)
+		} else {
+			http.Error(w, errPoolOrderClosed.Error(), http.StatusConflict)
+		}
+		return
+	}
+	p.answer(w, http.StatusOK, id)
+}
+
+// answer writes the order id as JSON with status, or 404 when there is none; the order is read before anything is
+// written, so an error still gets its own status
+func (p *poolServer) answer(w http.ResponseWriter, status int, id int64) {
+	order, err := p.order(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "no such order", http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(order)
+}
+
+// order reads the order id
+func (p *poolServer) order(id int64) (poolOrder, error) {
+	return scanOrder(p.db.QueryRow(`SELECT `+poolColumns+` FROM orders WHERE id = ?`, id))
+}
+
+// scanOrder reads the poolColumns of a row into a poolOrder, deriving its status
+func scanOrder(row interface{ Scan(...any) error }) (poolOrder, error) {
+	var order poolOrder
+	var probability float64
+	var created string
+	var leasedUntil int64
+	var cancelled bool
+	err := row.Scan(&order.ID, &order.Pattern, &order.Position, &order.SplitKey, &order.Bounty, &probability, &created, &order.Worker,
+		&leasedUntil, &order.Attempts, &order.Address, &order.Tweak, &order.FulfilledAt, &cancelled)
+	if err != nil {
+		return order, err
+	}
+	order.CreatedAt, _ = time.Parse(time.RFC3339, created)
+	if probability > 0 {
+		order.Expected = 1 / probability
+	}
+	switch {
+	case order.Tweak != "":
+		order.Status = poolFulfilled
+	case cancelled:
+		order.Status = poolCancelled
+	case leasedUntil >= time.Now().Unix():
+		order.Status = poolSearching
+	default:
+		order.Status = poolOpen
+	}
+	return order, nil
+}
+
+// orderID parses the {id} of the path, answering 400 itself when it isn't one
+func orderID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid order ID "+r.PathValue("id"), http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+// decodeWorkerRequest decodes the poolWorkerRequest of a worker, answering 400 itself when it isn't one
+func decodeWorkerRequest(w http.ResponseWriter, r *http.Request) (poolWorkerRequest, bool) {
+	var body poolWorkerRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<10)).Decode(&body); err != nil || body.Worker == "" {
+		http.Error(w, `expected {"worker":"..."}`, http.StatusBadRequest)
+		return body, false
+	}
+	return body, true
+}
+
+// poolClient is a -pool worker talking to a pool server
+type poolClient struct {
+	url    string       // the -pool URL without the trailing slash
+	worker string       // the hostname and PID of this worker
+	token  string       // XLM_VANITY_POOL_TOKEN, sent as a bearer token when set
+	client *http.Client // shared by every request
+}
+
+// newPoolClient returns the client of the pool server at rawURL for worker
+func newPoolClient(rawURL, worker string) *poolClient {
+	return &poolClient{url: strings.TrimRight(rawURL, "/"), worker: worker, token: os.Getenv(poolTokenEnv), client: &http.Client{Timeout: poolTimeout}}
+}
+
+// Claim leases the oldest open order of the pool, nil when there is none
+func (c *poolClient) Claim() (*poolOrder, error) {
+	var order poolOrder
+	status, err := c.request("/orders/claim", poolWorkerRequest{Worker: c.worker}, &order)
+	if err != nil || status == http.StatusNoContent {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// Progress reports the addresses scanned for order since the last report, errPoolOrderClosed once it closed
+func (c *poolClient) Progress(id, attempts int64) error {
+	_, err := c.request(fmt.Sprintf("/orders/%d/progress", id), poolWorkerRequest{Worker: c.worker, Attempts: attempts}, nil)
+	return err
+}
+
+// Deliver hands the tweak of a match to the pool, errPoolOrderClosed when the order closed in the meantime
+func (c *poolClient) Deliver(id int64, tweak string) (poolOrder, error) {
+	var order poolOrder
+	_, err := c.request(fmt.Sprintf("/orders/%d/tweak", id), poolWorkerRequest{Worker: c.worker, Tweak: tweak}, &order)
+	return order, err
+}
+
+// request posts body as JSON to path and decodes the answer into out when out is not nil, returning the status
+func (c *poolClient) request(path string, body, out any) (int, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequest(http.MethodPost, c.url+path, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	switch {
+	case resp.StatusCode == http.StatusConflict:
+		return resp.StatusCode, errPoolOrderClosed
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return resp.StatusCode, fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(message)))
+	case out == nil || resp.StatusCode == http.StatusNoContent:
+		return resp.StatusCode, nil
+	}
+	return resp.StatusCode, json.NewDecoder(resp.Body).Decode(out)
+}
+
+// waitPoolOrder claims an order of the pool, asking again every poolReportEvery while there is none
+func waitPoolOrder(c *poolClient, colors palette) *poolOrder {
+	for waited := false; ; waited = true {
+		order, err := c.Claim()
+		switch {
+		case err != nil:
+			colors.warnf("-pool %s: %v", c.url, err)
+		case order != nil:
+			return order
+		case !waited:
+			log.Printf("No open order on %s, asking again every %s", c.url, poolReportEvery)
+		}
+		time.Sleep(poolReportEvery)
+	}
+}
+
+// reportPoolProgress reports the addresses total counted for order every poolReportEvery until ctx is done, and sends
+// errPoolOrderClosed once the pool says the order closed; failed reports are only warned about, the lease lasts longer
+func reportPoolProgress(ctx context.Context, c *poolClient, order *poolOrder, total func() int64, colors palette) <-chan error {
+	closed := make(chan error, 1)
+	go func() {
+		ticker := time.NewTicker(poolReportEvery)
+		defer ticker.Stop()
+		reported := int64(0)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			scanned := total()
+			err := c.Progress(order.ID, scanned-reported)
+			if errors.Is(err, errPoolOrderClosed) {
+				closed <- err
+				return
+			} else if err != nil {
+				colors.warnf("-pool progress of order %d not reported: %v", order.ID, err)
+				continue
+			}
+			reported = scanned
+		}
+	}()
+	return closed
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"                         // used for sending the request bodies
+	"encoding/hex"                  // used for writing the tweaks
+	"encoding/json"                 // the pool API speaks JSON
+	"filippo.io/edwards25519"       // the scalar of the partial key of the orders
+	"github.com/stellar/go/keypair" // the key pairs the tweaks are drawn from
+	"net/http"                      // the pool API
+	"net/http/httptest"             // used for serving the pool API
+	"path/filepath"                 // used for placing pool.db in the test directory
+	"testing"                       // the test harness
+	"time"                          // the lease of the test pool
+)
+
+// poolTestToken is the XLM_VANITY_POOL_TOKEN of the test pool
+const poolTestToken = "pool-token"
+
+// poolTest is a pool server on a fresh pool.db and the partial key its orders are for
+type poolTest struct {
+	t       *testing.T
+	server  *poolServer
+	url     string
+	partial string
+}
+
+// newPoolTest serves a pool with token, which may be empty, on a fresh pool.db
+func newPoolTest(t *testing.T, token string) *poolTest {
+	db, err := openPoolDB(filepath.Join(t.TempDir(), "pool.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	p := &poolServer{db: db, token: token, lease: time.Minute}
+	server := httptest.NewServer(p.handler())
+	t.Cleanup(server.Close)
+	var entropy [64]byte
+	entropy[0] = 7
+	scalar, _ := new(edwards25519.Scalar).SetUniformBytes(entropy[:])
+	return &poolTest{t: t, server: p, url: server.URL, partial: newSplitKeyFile(scalar).Address}
+}
+
+// do sends body as JSON with the pool token when token is set, and decodes the order of the answer into order when
+// it is not nil and the answer is a success; it returns the status and the Content-Type of the answer
+func (pt *poolTest) do(method, path, token string, body any, order *poolOrder) (int, string) {
+	pt.t.Helper()
+	data, err := json.Marshal(body)
+	if err != nil {
+		pt.t.Fatal(err)
+	}
+	request, err := http.NewRequest(method, pt.url+path, bytes.NewReader(data))
+	if err != nil {
+		pt.t.Fatal(err)
+	}
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		pt.t.Fatal(err)
+	}
+	defer func() { _ = response.Body.Close() }()
+	if order != nil && response.StatusCode < 300 && response.StatusCode != http.StatusNoContent {
+		if err := json.NewDecoder(response.Body).Decode(order); err != nil {
+			pt.t.Fatal(err)
+		}
+	}
+	return response.StatusCode, response.Header.Get("Content-Type")
+}
+
+// submit submits an order for pattern, anywhere in the address, and returns it
+func (pt *poolTest) submit(pattern string) poolOrder {
+	pt.t.Helper()
+	var order poolOrder
+	status, contentType := pt.do(http.MethodPost, "/orders", "", poolOrderRequest{Pattern: pattern, SplitKey: pt.partial}, &order)
+	if status != http.StatusCreated || contentType != "application/json" {
+		pt.t.Fatalf("submitting %s answered %d with %q, want 201 with application/json", pattern, status, contentType)
+	}
+	return order
+}
+
+// expire ends the lease on the order id right away, as if its worker had stopped reporting
+func (pt *poolTest) expire(id int64) {
+	pt.t.Helper()
+	if _, err := pt.server.db.Exec(`UPDATE orders SET leased_until = ? WHERE id = ?`, time.Now().Add(-time.Second).Unix(), id); err != nil {
+		pt.t.Fatal(err)
+	}
+}
+
+// findTweak searches random seeds for a tweak that turns the partial key into an address matching order
+func (pt *poolTest) findTweak(order poolOrder) string {
+	pt.t.Helper()
+	for i := 0; i < 1_000_000; i++ {
+		tweak := hex.EncodeToString(splitTweak(keypair.MustRandom()).Bytes())
+		if _, err := checkPoolTweak(order, tweak); err == nil {
+			return tweak
+		}
+	}
+	pt.t.Fatalf("no tweak found for %s", order.Pattern)
+	return ""
+}
+
+// TestPoolClaimLease claims an order, expects no other worker to get it while the lease lasts and the next worker to
+// get it once the lease expired, after which the first worker's progress is turned down with 409
+func TestPoolClaimLease(t *testing.T) {
+	pt := newPoolTest(t, "")
+	order := pt.submit("AA")
+	if order.Status != poolOpen {
+		t.Fatalf("a new order is %s, want %s", order.Status, poolOpen)
+	}
+
+	var claimed poolOrder
+	if status, _ := pt.do(http.MethodPost, "/orders/claim", "", poolWorkerRequest{Worker: "one"}, &claimed); status != http.StatusOK {
+		t.Fatalf("claiming answered %d, want 200", status)
+	}
+	if claimed.ID != order.ID || claimed.Status != poolSearching || claimed.Worker != "one" {
+		t.Fatalf("worker one claimed order %d, %s by %q, want order %d searching by one", claimed.ID, claimed.Status,
+			claimed.Worker, order.ID)
+	}
+	if status, _ := pt.do(http.MethodPost, "/orders/claim", "", poolWorkerRequest{Worker: "two"}, nil); status != http.StatusNoContent {
+		t.Fatalf("claiming a leased order answered %d, want 204", status)
+	}
+	progress := poolWorkerRequest{Worker: "one", Attempts: 1000}
+	if status, _ := pt.do(http.MethodPost, "/orders/1/progress", "", progress, &claimed); status != http.StatusOK || claimed.Attempts != 1000 {
+		t.Fatalf("reporting progress answered %d with %d attempts, want 200 with 1000", status, claimed.Attempts)
+	}
+
+	pt.expire(order.ID)
+	if status, _ := pt.do(http.MethodPost, "/orders/claim", "", poolWorkerRequest{Worker: "two"}, &claimed); status != http.StatusOK ||
+		claimed.Worker != "two" {
+		t.Fatalf("claiming an expired lease answered %d for %q, want 200 for two", status, claimed.Worker)
+	}
+	if status, _ := pt.do(http.MethodPost, "/orders/1/progress", "", progress, nil); status != http.StatusConflict {
+		t.Fatalf("progress of the worker that lost the lease answered %d, want 409", status)
+	}
+	if status, _ := pt.do(http.MethodPost, "/orders/9/progress", "", progress, nil); status != http.StatusNotFound {
+		t.Fatalf("progress of a missing order answered %d, want 404", status)
+	}
+}
+
+// TestPoolTweak expects tweaks that aren't scalars or don't match the order to be turned down with 422, a matching one
+// to fulfill the order, and any tweak after it to be turned down with 409
+func TestPoolTweak(t *testing.T) {
+	pt := newPoolTest(t, "")
+	order := pt.submit("AA")
+	tweak := pt.findTweak(order)
+
+	for _, bad := range []string{"", "zz", hex.EncodeToString(make([]byte, 31)), "ff" + tweak[2:]} {
+		if _, err := checkPoolTweak(order, bad); err == nil {
+			continue // flipping the first byte may still match, rarely
+		}
+		if status, _ := pt.do(http.MethodPost, "/orders/1/tweak", "", poolWorkerRequest{Worker: "one", Tweak: bad}, nil); status != http.StatusUnprocessableEntity {
+			t.Fatalf("the tweak %q answered %d, want 422", bad, status)
+		}
+	}
+
+	var fulfilled poolOrder
+	status, contentType := pt.do(http.MethodPost, "/orders/1/tweak", "", poolWorkerRequest{Worker: "one", Tweak: tweak}, &fulfilled)
+	if status != http.StatusOK || contentType != "application/json" {
+		t.Fatalf("a matching tweak answered %d with %q, want 200 with application/json", status, contentType)
+	}
+	partial, _ := parseSplitKey(pt.partial)
+	scalar, _ := decodeScalar(tweak)
+	if fulfilled.Status != poolFulfilled || fulfilled.Tweak != tweak || fulfilled.Address != splitAddress(partial, scalar) {
+		t.Fatalf("the order is %s with the tweak %q for %s, want fulfilled with %q", fulfilled.Status, fulfilled.Tweak,
+			fulfilled.Address, tweak)
+	}
+	if status, _ := pt.do(http.MethodPost, "/orders/1/tweak", "", poolWorkerRequest{Worker: "two", Tweak: tweak}, nil); status != http.StatusConflict {
+		t.Fatalf("a tweak for a fulfilled order answered %d, want 409", status)
+	}
+	if status, _ := pt.do(http.MethodPost, "/orders/claim", "", poolWorkerRequest{Worker: "two"}, nil); status != http.StatusNoContent {
+		t.Fatalf("claiming with only a fulfilled order answered %d, want 204", status)
+	}
+}
+
+// TestPoolAuthorization expects the worker endpoints to need the pool token when there is one, and cancelling to
+// need it always
+func TestPoolAuthorization(t *testing.T) {
+	open := newPoolTest(t, "")
+	open.submit("AB")
+	if status, _ := open.do(http.MethodDelete, "/orders/1", "", nil, nil); status != http.StatusUnauthorized {
+		t.Fatalf("cancelling without a pool token configured answered %d, want 401", status)
+	}
+
+	pt := newPoolTest(t, poolTestToken)
+	pt.submit("AB")
+	for _, token := range []string{"", "wrong"} {
+		if status, _ := pt.do(http.MethodPost, "/orders/claim", token, poolWorkerRequest{Worker: "one"}, nil); status != http.StatusUnauthorized {
+			t.Fatalf("claiming with the token %q answered %d, want 401", token, status)
+		}
+		if status, _ := pt.do(http.MethodDelete, "/orders/1", token, nil, nil); status != http.StatusUnauthorized {
+			t.Fatalf("cancelling with the token %q answered %d, want 401", token, status)
+		}
+	}
+	var cancelled poolOrder
+	if status, _ := pt.do(http.MethodDelete, "/orders/1", poolTestToken, nil, &cancelled); status != http.StatusOK || cancelled.Status != poolCancelled {
+		t.Fatalf("cancelling with the token answered %d and left the order %s, want 200 and cancelled", status, cancelled.Status)
+	}
+	if status, _ := pt.do(http.MethodPost, "/orders/claim", poolTestToken, poolWorkerRequest{Worker: "one"}, nil); status != http.StatusNoContent {
+		t.Fatalf("claiming with only a cancelled order answered %d, want 204", status)
+	}
+	if status, _ := pt.do(http.MethodDelete, "/orders/1", poolTestToken, nil, nil); status != http.StatusConflict {
+		t.Fatalf("cancelling a cancelled order answered %d, want 409", status)
+	}
+}
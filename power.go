@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"     // the power watcher stops with the run
+	"errors"      // used for reporting platforms without a power status
+	"log"         // used for announcing pauses and resumes
+	"sync/atomic" // the workers read the pause reasons while the watcher writes them
+	"time"        // used for polling the power source
+)
+
+// powerPoll is how often -pause-on-battery checks the power source
+const powerPoll = 15 * time.Second
+
+// errPowerUnsupported is returned by onBattery on platforms it can't read the power source of
+var errPowerUnsupported = errors.New("the power source can't be read on this platform")
+
+// watchPower pauses the workers through the pauseBattery reason of paused while the machine runs on battery and takes it
+// out on AC power, until ctx is done; it fails right away when the power source can't be read
+func watchPower(ctx context.Context, paused *atomic.Uint32) error {
+	battery, err := onBattery()
+	if err != nil {
+		return err
+	}
+	setPaused(paused, battery)
+	go func() {
+		ticker := time.NewTicker(powerPoll)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				battery, err := onBattery()
+				if err != nil {
+					debugf("failed to read the power source: %v", err)
+					continue
+				}
+				if battery != (paused.Load()&pauseBattery != 0) {
+					setPaused(paused, battery)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// setPaused pauses or resumes the workers for the power source and says so
+func setPaused(paused *atomic.Uint32, battery bool) {
+	wasOnBattery := paused.Load()&pauseBattery != 0
+	switch {
+	case battery:
+		pauseWorkers(paused, pauseBattery)
+		log.Printf("Running on battery, the workers are paused until AC power is back")
+	case resumeWorkers(paused, pauseBattery):
+		log.Printf("Back on AC power, the workers resume")
+	case wasOnBattery:
+		log.Printf("Back on AC power, the workers stay paused until SIGUSR2 or POST /resume")
+	default:
+		debugf("running on AC power")
+	}
+}
@@ -0,0 +1,17 @@
+//go:build darwin
+
+package main
+
+import (
+	"os/exec" // pmset reports the power source
+	"strings" // used for reading the pmset output
+)
+
+// onBattery reports whether pmset says the Mac is drawing from its battery
+func onBattery() (bool, error) {
+	out, err := exec.Command("pmset", "-g", "batt").Output()
+	if err != nil {
+		return false, err
+	}
+	return strings.Contains(string(out), "'Battery Power'"), nil
+}
@@ -0,0 +1,43 @@
+//go:build linux
+
+package main
+
+import (
+	"errors"        // used for telling a missing /sys/class/power_supply apart
+	"os"            // access the filesystem
+	"path/filepath" // used for walking /sys/class/power_supply
+	"strings"       // used for trimming the sysfs attributes
+)
+
+// powerSupplies is where the kernel lists AC adapters and batteries
+const powerSupplies = "/sys/class/power_supply"
+
+// onBattery reports whether no AC adapter is online while a battery is discharging; machines without a battery, like
+// desktops, servers and most containers, are never on battery
+func onBattery() (bool, error) {
+	supplies, err := os.ReadDir(powerSupplies)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	discharging := false
+	for _, supply := range supplies {
+		attribute := func(name string) string {
+			data, _ := os.ReadFile(filepath.Join(powerSupplies, supply.Name(), name))
+			return strings.TrimSpace(string(data))
+		}
+		switch attribute("type") {
+		case "Mains", "USB":
+			if attribute("online") == "1" {
+				return false, nil
+			}
+		case "Battery":
+			if attribute("status") == "Discharging" {
+				discharging = true
+			}
+		}
+	}
+	return discharging, nil
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !darwin && !windows
+
+package main
+
+// onBattery can't read the power source on this platform
+func onBattery() (bool, error) {
+	return false, errPowerUnsupported
+}
@@ -0,0 +1,30 @@
+//go:build windows
+
+package main
+
+import (
+	"golang.org/x/sys/windows" // used for calling GetSystemPowerStatus
+	"unsafe"                   // used for passing the SYSTEM_POWER_STATUS to kernel32
+)
+
+// systemPowerStatus is the SYSTEM_POWER_STATUS structure of GetSystemPowerStatus
+type systemPowerStatus struct {
+	ACLineStatus        byte // 0 offline, 1 online, 255 unknown
+	BatteryFlag         byte
+	BatteryLifePercent  byte
+	SystemStatusFlag    byte
+	BatteryLifeTime     uint32
+	BatteryFullLifeTime uint32
+}
+
+// getSystemPowerStatus is GetSystemPowerStatus of kernel32.dll
+var getSystemPowerStatus = windows.NewLazySystemDLL("kernel32.dll").NewProc("GetSystemPowerStatus")
+
+// onBattery reports whether Windows says the AC line is offline
+func onBattery() (bool, error) {
+	var status systemPowerStatus
+	if ok, _, err := getSystemPowerStatus.Call(uintptr(unsafe.Pointer(&status))); ok == 0 {
+		return false, err
+	}
+	return status.ACLineStatus == 0, nil
+}
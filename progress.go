@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json" // every progress event is one line of JSON
+	"fmt"           // used for describing an invalid -every
+	"io"            // the events go to STDERR
+	"strconv"       // used for parsing an -every in seconds
+	"time"          // used for the elapsed time and the rate
+)
+
+// progressEvent is one line of -progress-json, for GUIs and wrappers that render their own progress
+type progressEvent struct {
+	Event          string    `json:"event"`           // always "progress"
+	Time           time.Time `json:"time"`            // when the event was written
+	Attempts       int64     `json:"attempts"`        // addresses scanned since the start
+	Rate           float64   `json:"rate"`            // addresses per second over the last few seconds
+	ElapsedSeconds float64   `json:"elapsed_seconds"` // how long the search has been running
+	Chance         float64   `json:"chance"`          // 0 to 1, the chance of a match by now since the most recent match
+	Matches        int       `json:"matches"`         // matches saved since the start
+}
+
+// minEvery is the shortest -every, faster updates would cost the workers more than they tell
+const minEvery = 50 * time.Millisecond
+
+// parseEvery parses the -every between two updates, whole seconds like it always took or a duration like 250ms
+func parseEvery(value string) (time.Duration, error) {
+	every, err := time.ParseDuration(value)
+	if seconds, atoiErr := strconv.Atoi(value); atoiErr == nil {
+		every, err = time.Duration(seconds)*time.Second, nil
+	}
+	if err != nil || every < minEvery {
+		return 0, fmt.Errorf("expected seconds or a duration of at least %s, like 30 or 250ms", minEvery)
+	}
+	return every, nil
+}
+
+// progressReporter writes a progressEvent per -every tick, one JSON document per line
+type progressReporter struct {
+	w            io.Writer // STDERR, STDOUT belongs to the matches
+	started      time.Time // when the search started
+	last         time.Time // when the previous event was written
+	lastAttempts int64     // the attempts of the previous event
+	lastRate     float64   // the rate of the previous event
+}
+
+// newProgressReporter returns the reporter of a search starting now
+func newProgressReporter(w io.Writer) *progressReporter {
+	now := time.Now()
+	return &progressReporter{w: w, started: now, last: now}
+}
+
+// Report writes the progress event of attempts, chance and matches
+func (p *progressReporter) Report(attempts int64, chance float64, matches int) error {
+	now := time.Now()
+	event := progressEvent{Event: "progress", Time: now, Attempts: attempts, ElapsedSeconds: now.Sub(p.started).Seconds(),
+		Chance: chance, Matches: matches}
+	if interval := now.Sub(p.last); interval > 0 {
+		event.Rate = smoothRate(p.lastRate, float64(attempts-p.lastAttempts)/interval.Seconds(), interval)
+	}
+	p.last, p.lastAttempts, p.lastRate = now, attempts, event.Rate
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = p.w.Write(append(data, '\n'))
+	return err
+}
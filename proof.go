@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/base64"               // signatures are base64 encoded, like the signatures of Stellar transactions
+	"errors"                        // used for reporting a proof of another address
+	"fmt"                           // used for wrapping errors
+	"github.com/stellar/go/keypair" // used for signing with the found key and verifying with its address
+	"strings"                       // used for taking the address out of the message
+	"time"                          // the message is stamped with when the match was found
+)
+
+// proofPrefix starts the message of every proof of possession
+const proofPrefix = "vanity-proof:"
+
+// proof is a proof of possession: the found key signed "vanity-proof:<address>:<timestamp>", so whoever holds the
+// results file can show a buyer or auditor that the seed exists without revealing it
+type proof struct {
+	Message   string `json:"message"`   // vanity-proof:<address>:<RFC 3339 time the match was found>
+	Signature string `json:"signature"` // the ed25519 signature of Message by the key of the address, base64 encoded
+}
+
+// signProof signs the proof message of the address of seed, stamped with at
+func signProof(seed string, at time.Time) (*proof, error) {
+	pair, err := keypair.ParseFull(seed)
+	if err != nil {
+		return nil, err
+	}
+	message := proofPrefix + pair.Address() + ":" + at.UTC().Format(time.RFC3339)
+	signature, err := pair.Sign([]byte(message))
+	if err != nil {
+		return nil, err
+	}
+	return &proof{Message: message, Signature: base64.StdEncoding.EncodeToString(signature)}, nil
+}
+
+// Verify checks that p is a proof of possession of address, signed by its key
+func (p *proof) Verify(address string) error {
+	if !strings.HasPrefix(p.Message, proofPrefix+address+":") {
+		return errors.New("the message is not about " + address)
+	}
+	signature, err := base64.StdEncoding.DecodeString(p.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+	pair, err := keypair.ParseAddress(address)
+	if err != nil {
+		return err
+	}
+	return pair.Verify([]byte(p.Message), signature)
+}
@@ -0,0 +1,43 @@
+package main
+
+// quotas counts the matches of every pattern against -quota; the output files are the saved state, so the matches of
+// earlier runs count too and an interrupted run resumes with the patterns that are still short of their quota
+type quotas struct {
+	quota   int            // matches wanted per pattern
+	matches map[string]int // matches per pattern in the output files
+}
+
+// newQuotas returns the quotas of patterns seeded with the matches already in saved, nil when quota is 0 (no quota)
+func newQuotas(quota int, saved *outputs, patterns []string) *quotas {
+	if quota <= 0 {
+		return nil
+	}
+	q := &quotas{quota: quota, matches: make(map[string]int)}
+	for _, pattern := range patterns {
+		q.matches[pattern] = saved.Matches(pattern)
+	}
+	return q
+}
+
+// Unfinished returns the patterns that don't have their quota of matches yet, all of them without a quota
+func (q *quotas) Unfinished(patterns []string) []string {
+	if q == nil {
+		return patterns
+	}
+	unfinished := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		if q.matches[pattern] < q.quota {
+			unfinished = append(unfinished, pattern)
+		}
+	}
+	return unfinished
+}
+
+// Add counts a saved match of pattern and reports whether it completed the quota of pattern
+func (q *quotas) Add(pattern string) bool {
+	if q == nil {
+		return false
+	}
+	q.matches[pattern]++
+	return q.matches[pattern] == q.quota
+}
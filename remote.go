@@ -0,0 +1,274 @@
+package main
+
+import (
+	"bufio"         // used for reading the object and the journal line by line
+	"bytes"         // used for batching the appended lines
+	"encoding/json" // every result is one JSON line of the object
+	"errors"        // used for joining the errors of Close
+	"fmt"           // used for wrapping errors with the object involved
+	"os"            // access the filesystem for the local journal
+	"path/filepath" // used for naming the local journal
+	"strings"       // used for telling the remote -output schemes apart
+	"sync"          // used for guarding the index from concurrent access
+	"time"          // used for backing off between attempts
+)
+
+// remoteAttempts is how many times an append is tried before the results are left in the journal for the next one
+const remoteAttempts = 4
+
+// remoteObject is the object in a bucket an s3://, gs:// or azblob:// -output appends its results to
+type remoteObject interface {
+	Read() ([]byte, error)     // the object, nothing when it doesn't exist yet
+	Append(lines []byte) error // adds lines to the end, failing when someone else changed the object since the last call
+}
+
+// remoteOptions are how the remote objects are written
+type remoteOptions struct {
+	s3 s3Options // -s3-sse, -s3-sse-kms-key and -s3-endpoint
+}
+
+// remoteURL reports whether path names an object in a bucket instead of a file
+func remoteURL(path string) bool {
+	return strings.HasPrefix(path, s3Scheme) || strings.HasPrefix(path, gcsScheme) || strings.HasPrefix(path, azureScheme)
+}
+
+// splitRemoteURL returns the bucket and the key of rawURL, which starts with scheme
+func splitRemoteURL(rawURL, scheme string) (string, string, error) {
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(rawURL, scheme), "/")
+	if bucket == "" || key == "" || strings.HasSuffix(key, "/") {
+		return "", "", fmt.Errorf("invalid -output %s: expected %s<bucket>/<key>", rawURL, scheme)
+	}
+	return bucket, key, nil
+}
+
+// remoteStore is the store of a remote -output: every result is journaled to a local file like the file store does,
+// and every -compact results the journal is appended to the object as JSON lines, so a headless worker in the cloud
+// needs no sync step afterwards
+type remoteStore struct {
+	mu           sync.Mutex
+	url          string              // the -output, like s3://bucket/key
+	object       remoteObject        // reads and appends the object
+	lock         *os.File            // the advisory lock on the journal, held until Close
+	journal      *os.File            // the results not yet appended to the object
+	journalPath  string              // where journal is, in the working directory
+	index        map[string]struct{} // every address in the object and the journal
+	uploaded     map[string]struct{} // every address in the object
+	matches      map[string]int      // results in the object per pattern
+	pending      int                 // results appended to the journal since the last append to the object
+	compactEvery int                 // append after this many pending results, 0 appends on Close only
+}
+
+// openRemoteStore opens the object of rawURL, locks its local journal, loads the object and appends any journal left
+// behind by a previous run
+func openRemoteStore(rawURL string, compactEvery int, options remoteOptions) (*remoteStore, error) {
+	var object remoteObject
+	var scheme, bucket, key string
+	var err error
+	for _, scheme = range []string{s3Scheme, gcsScheme, azureScheme} {
+		if strings.HasPrefix(rawURL, scheme) {
+			break
+		}
+	}
+	if bucket, key, err = splitRemoteURL(rawURL, scheme); err != nil {
+		return nil, err
+	}
+	switch scheme {
+	case s3Scheme:
+		object, err = openS3Object(bucket, key, options.s3)
+	case gcsScheme:
+		object, err = openGCSObject(bucket, key)
+	case azureScheme:
+		object, err = openAzureObject(bucket, key)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	local := filepath.Join(".", strings.TrimSuffix(scheme, "://")+"-"+bucket+"-"+strings.ReplaceAll(key, "/", "-"))
+	lock, err := lockOutput(local) // two instances appending the same object would drop each other's results
+	if err != nil {
+		return nil, err
+	}
+	s := &remoteStore{url: rawURL, object: object, lock: lock, journalPath: local + ".journal",
+		index: make(map[string]struct{}), uploaded: make(map[string]struct{}), matches: make(map[string]int), compactEvery: compactEvery}
+	if err := s.load(); err != nil {
+		_ = lock.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// load fills the index from the object and the journal; the caller must be the only reference to s
+func (s *remoteStore) load() error {
+	object, err := s.object.Read()
+	defer zeroize(object)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", s.url, err)
+	}
+	for _, r := range decodeLines(object) {
+		s.index[r.Address] = struct{}{}
+		s.uploaded[r.Address] = struct{}{}
+		s.matches[r.Pattern]++
+	}
+
+	journal, err := os.OpenFile(s.journalPath, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open journal %s: %w", s.journalPath, err)
+	}
+	s.journal = journal
+	journaled, err := os.ReadFile(s.journalPath)
+	defer zeroize(journaled)
+	if err != nil {
+		return fmt.Errorf("failed to replay journal %s: %w", s.journalPath, err)
+	}
+	for _, r := range decodeLines(journaled) {
+		if _, exists := s.index[r.Address]; !exists {
+			s.index[r.Address] = struct{}{}
+			s.pending++
+		}
+	}
+
+	debugf("loaded %d results from %s and replayed %d from %s", len(s.uploaded), s.url, s.pending, s.journalPath)
+	if s.pending > 0 { // append the previous run's results before we start journaling our own
+		return s.flush()
+	}
+	return nil
+}
+
+// decodeLines returns the results of the JSON lines in data, skipping blank and torn lines
+func decodeLines(data []byte) []result {
+	var results []result
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), bufio.MaxScanTokenSize)
+	for scanner.Scan() {
+		var r result
+		if err := json.Unmarshal(scanner.Bytes(), &r); err == nil && r.Address != "" {
+			results = append(results, r)
+		}
+	}
+	return results
+}
+
+// Add appends r to the journal and the index, appending the journal to the object when -compact results are pending;
+// a result whose address is already stored is skipped and Add reports false
+func (s *remoteStore) Add(r result) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.index[r.Address]; exists {
+		debugf("skipped %s, already in %s", r.Address, s.url)
+		return false, nil
+	}
+	line, err := json.Marshal(r)
+	if err != nil {
+		return false, err
+	}
+	line = append(line, '\n')
+	defer zeroize(line)
+	if _, err := s.journal.Write(line); err != nil {
+		return false, fmt.Errorf("failed to append to journal %s: %w", s.journalPath, err)
+	}
+	if err := s.journal.Sync(); err != nil { // the seed only leaves this machine with the next append
+		return false, fmt.Errorf("failed to sync journal %s: %w", s.journalPath, err)
+	}
+	s.index[r.Address] = struct{}{}
+	s.pending++
+	tracef("journaled %s to %s (%d pending)", r.Address, s.journalPath, s.pending)
+
+	if s.compactEvery > 0 && s.pending >= s.compactEvery {
+		return true, s.flush()
+	}
+	return true, nil
+}
+
+// flush appends the journaled results the object doesn't have yet, trying remoteAttempts times, then empties the
+// journal; the caller must hold s.mu (or be the only reference to s)
+func (s *remoteStore) flush() error {
+	journaled, err := os.ReadFile(s.journalPath)
+	defer zeroize(journaled)
+	if err != nil {
+		return fmt.Errorf("failed to read journal %s: %w", s.journalPath, err)
+	}
+	var batch bytes.Buffer
+	defer func() { zeroize(batch.Bytes()) }()
+	var results []result
+	for _, r := range decodeLines(journaled) {
+		if _, exists := s.uploaded[r.Address]; exists {
+			continue // appended by a run that crashed before emptying the journal
+		}
+		line, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		batch.Write(append(line, '\n'))
+		zeroize(line)
+		s.uploaded[r.Address] = struct{}{}
+		results = append(results, r)
+	}
+
+	if batch.Len() > 0 {
+		for attempt := 1; ; attempt++ {
+			if err = s.object.Append(batch.Bytes()); err == nil {
+				break
+			}
+			if attempt == remoteAttempts {
+				for _, r := range results { // still in the journal, the next flush tries them again
+					delete(s.uploaded, r.Address)
+				}
+				return fmt.Errorf("failed to append %d results to %s, they stay in %s: %w", len(results), s.url, s.journalPath, err)
+			}
+			debugf("attempt %d of appending to %s failed: %v", attempt, s.url, err)
+			time.Sleep(time.Duration(attempt*attempt) * time.Second)
+		}
+		for _, r := range results {
+			s.matches[r.Pattern]++
+		}
+	}
+
+	if err := s.journal.Truncate(0); err != nil { // everything in the journal now lives in the object
+		return fmt.Errorf("failed to truncate journal %s: %w", s.journalPath, err)
+	}
+	debugf("appended %d results (%d pending) to %s", len(results), s.pending, s.url)
+	s.pending = 0
+	return nil
+}
+
+// Fund reports false, the object is only ever appended to and the first line of an address is the one that counts
+func (s *remoteStore) Fund(string, *funding) (bool, error) {
+	return false, nil
+}
+
+// Matches returns how many results of pattern the object holds
+func (s *remoteStore) Matches(pattern string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.matches[pattern]
+}
+
+// Len returns how many results are known, both appended and pending
+func (s *remoteStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.index)
+}
+
+// Path returns the URL of the object this store appends to
+func (s *remoteStore) Path() string {
+	return s.url
+}
+
+// Close appends any pending results to the object, removes the journal and releases the lock
+func (s *remoteStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var flushErr error
+	if s.pending > 0 {
+		flushErr = s.flush()
+	}
+	closeErr := s.journal.Close()
+	if flushErr == nil && closeErr == nil {
+		_ = os.Remove(s.journalPath) // only remove the journal once its results are safely in the object
+	}
+	return errors.Join(flushErr, closeErr, s.lock.Close())
+}
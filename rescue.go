@@ -0,0 +1,40 @@
+package main
+
+import (
+	"encoding/json" // every rescued result is a JSON line
+	"fmt"           // used for wrapping errors with the path involved
+	"os"            // access the filesystem
+	"path/filepath" // used for reporting where the rescued results are
+)
+
+// rescueFile is where results that couldn't be saved where they were meant to go are appended, in the working
+// directory, so a failing output file, share directory or seed backend never costs a seed
+const rescueFile = "xlm-vanity-address-finder.rescue.jsonl"
+
+// rescueResult appends r, seed included, as a JSON line to rescueFile, readable by its owner only, and returns where
+// the file is
+func rescueResult(r result) (string, error) {
+	path, err := filepath.Abs(rescueFile)
+	if err != nil {
+		return "", err
+	}
+	line, err := json.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+	line = append(line, '\n')
+	defer zeroize(line)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	if _, err := file.Write(line); err != nil {
+		_ = file.Close()
+		return "", fmt.Errorf("failed to append to %s: %w", path, err)
+	}
+	if err := file.Sync(); err != nil { // it may be the only copy of the seed
+		_ = file.Close()
+		return "", fmt.Errorf("failed to sync %s: %w", path, err)
+	}
+	return path, file.Close()
+}
@@ -0,0 +1,37 @@
+//go:build !unix
+
+package main
+
+import (
+	"golang.org/x/term" // used for measuring the console
+	"os"                // the resizes are delivered as os.Signal, like SIGWINCH
+	"time"              // used for polling the console
+)
+
+// resizeEvery is how often the console is measured, there is no SIGWINCH to tell it was resized
+const resizeEvery = 250 * time.Millisecond
+
+// consoleResized is the os.Signal notifyResize delivers, in place of SIGWINCH
+type consoleResized struct{}
+
+func (consoleResized) String() string { return "console resized" }
+func (consoleResized) Signal()        {}
+
+// notifyResize returns the channel a resize of the console of fd is delivered on, which is measured every resizeEvery;
+// a Windows console only reports its resizes among the input events, which belong to whoever reads STDIN
+func notifyResize(fd int) <-chan os.Signal {
+	resized := make(chan os.Signal, 1)
+	go func() {
+		width, _, _ := term.GetSize(fd)
+		for range time.Tick(resizeEvery) {
+			if current, _, err := term.GetSize(fd); err == nil && current != width {
+				width = current
+				select {
+				case resized <- consoleResized{}:
+				default: // a resize is still waiting to be handled, it measures the console again anyway
+				}
+			}
+		}
+	}()
+	return resized
+}
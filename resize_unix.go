@@ -0,0 +1,17 @@
+//go:build unix
+
+package main
+
+import (
+	"os"        // the signals are delivered as os.Signal
+	"os/signal" // used for catching SIGWINCH
+	"syscall"   // used for naming SIGWINCH
+)
+
+// notifyResize returns the channel SIGWINCH, the terminal being resized, is delivered on; it stands for the terminal
+// of any fd
+func notifyResize(int) <-chan os.Signal {
+	resized := make(chan os.Signal, 1)
+	signal.Notify(resized, syscall.SIGWINCH)
+	return resized
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bytes"         // used for feeding the output file into a streaming decoder
+	"encoding/json" // used for decoding the output file record by record
+	"errors"        // used for detecting the end of a truncated file
+	"flag"          // the results subcommands parse their own arguments
+	"fmt"           // used for writing the report to STDOUT
+	"io"            // used for detecting the end of a truncated file
+	"os"            // access the filesystem
+	"reflect"       // used for listing the json fields a result knows about
+	"sort"          // used for reporting unknown fields in a stable order
+	"strings"       // used for reading the json struct tags
+)
+
+// resultsCheck is what `results check` found wrong with an output file
+type resultsCheck struct {
+	Checksum      error          // why the file doesn't match the checksum of its last compaction, nil when it does
+	Malformed     error          // why decoding stopped early, nil when the whole file decoded
+	Duplicates    int            // results whose address appeared earlier in the file
+	Mismatched    int            // results whose seed is invalid or derives another address
+	UnknownFields map[string]int // fields a result does not know about, with how many records had them
+	Clean         []result       // every unique, consistent result that could be recovered
+}
+
+// OK reports whether the file needs no repair
+func (c resultsCheck) OK() bool {
+	return c.Checksum == nil && c.Malformed == nil && c.Duplicates == 0 && c.Mismatched == 0 && len(c.UnknownFields) == 0
+}
+
+// runResults implements `xlm-vanity-address-finder results <command>`; it returns the process exit code
+func runResults(args []string) int {
+	if len(args) > 0 && args[0] == "check" {
+		return runResultsCheck(args[1:])
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "Usage: %s results check [-fix] [-out path] results.json\n", os.Args[0])
+	return 2
+}
+
+// runResultsCheck implements `results check [-fix] results.json`, reporting malformed JSON, duplicate addresses,
+// mismatched pairs and unknown fields, and with -fix writing a cleaned copy of the file
+func runResultsCheck(args []string) int {
+	flags := flag.NewFlagSet("results check", flag.ExitOnError)
+	fix := flags.Bool("fix", false, "Write a cleaned copy of the results file")
+	out := flags.String("out", "", "Path of the cleaned copy written by -fix (default <file>.fixed.json)")
+	_ = flags.Parse(args) // ExitOnError handles the error
+	if flags.NArg() != 1 {
+		_, _ = fmt.Fprintf(os.Stderr, "Usage: %s results check [-fix] [-out path] results.json\n", os.Args[0])
+		flags.PrintDefaults()
+		return 2
+	}
+	path := flags.Arg(0)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	check := checkResults(data)
+	check.Checksum = checkChecksum(path, data)
+
+	if check.Checksum != nil {
+		fmt.Printf("checksum mismatch: %v\n", check.Checksum)
+	}
+	if check.Malformed != nil {
+		fmt.Printf("malformed JSON: %v\n", check.Malformed)
+	}
+	fmt.Printf("duplicate addresses: %d\n", check.Duplicates)
+	fmt.Printf("mismatched pairs: %d\n", check.Mismatched)
+	unknown := make([]string, 0, len(check.UnknownFields))
+	for field := range check.UnknownFields {
+		unknown = append(unknown, field)
+	}
+	sort.Strings(unknown)
+	for _, field := range unknown {
+		fmt.Printf("unknown field %q in %d results\n", field, check.UnknownFields[field])
+	}
+	fmt.Printf("%d clean results\n", len(check.Clean))
+
+	if check.OK() {
+		return 0
+	}
+	if !*fix {
+		return 1
+	}
+
+	if *out == "" {
+		*out = strings.TrimSuffix(path, ".json") + ".fixed.json"
+	}
+	cleaned, err := json.Marshal(document{Results: check.Clean, Stats: make(map[string]*patternStats)})
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := os.WriteFile(*out, cleaned, 0600); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("wrote %d clean results to %s\n", len(check.Clean), *out)
+	return 0
+}
+
+// checkResults decodes an output file record by record, so a truncated or corrupted file still yields every record
+// before the damage, and sorts the records into clean ones and the problems found
+func checkResults(data []byte) resultsCheck {
+	check := resultsCheck{UnknownFields: make(map[string]int), Clean: make([]result, 0)}
+	records, malformed := decodeRecords(data)
+	check.Malformed = malformed
+
+	known := resultFields()
+	seen := make(map[string]struct{})
+	for _, raw := range records {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			check.Mismatched++
+			continue
+		}
+		for field := range fields {
+			if _, ok := known[field]; !ok {
+				check.UnknownFields[field]++
+			}
+		}
+
+		var r result
+		if err := json.Unmarshal(raw, &r); err != nil {
+			check.Mismatched++
+			continue
+		}
+		if _, duplicate := seen[r.Address]; duplicate {
+			check.Duplicates++
+			continue
+		}
+		if entry := verifyResult("", r); entry.Err != nil || r.Address == "" {
+			check.Mismatched++
+			continue
+		}
+		seen[r.Address] = struct{}{}
+		check.Clean = append(check.Clean, r)
+	}
+	return check
+}
+
+// decodeRecords returns the raw results of an output file in either layout, stopping at the first malformed record
+func decodeRecords(data []byte) ([]json.RawMessage, error) {
+	records := make([]json.RawMessage, 0)
+	decoder := json.NewDecoder(bytes.NewReader(data))
+
+	token, err := decoder.Token()
+	if err != nil {
+		return records, err
+	}
+	if token == json.Delim('{') { // the document layout, find its "results" array
+		for {
+			key, err := decoder.Token()
+			if err != nil {
+				return records, err
+			}
+			if key == json.Delim('}') {
+				return records, nil // a document without results
+			}
+			if key == "results" {
+				break
+			}
+			var skip json.RawMessage
+			if err := decoder.Decode(&skip); err != nil {
+				return records, err
+			}
+		}
+		if token, err = decoder.Token(); err != nil {
+			return records, err
+		}
+	}
+	if token != json.Delim('[') {
+		return records, fmt.Errorf("expected an array of results, found %v", token)
+	}
+
+	for decoder.More() {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			if errors.Is(err, io.ErrUnexpectedEOF) {
+				err = fmt.Errorf("file is truncated after %d results", len(records))
+			}
+			return records, err
+		}
+		records = append(records, raw)
+	}
+	if _, err := decoder.Token(); err != nil { // the closing bracket
+		return records, err
+	}
+	return records, nil
+}
+
+// resultFields returns the json field names of result
+func resultFields() map[string]struct{} {
+	fields := make(map[string]struct{})
+	t := reflect.TypeOf(result{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		fields[name] = struct{}{}
+	}
+	return fields
+}
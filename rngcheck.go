@@ -0,0 +1,245 @@
+package main
+
+import (
+	"flag"                                                       // the rngcheck subcommand parses its own arguments
+	"fmt"                                                        // used for printing the report
+	"github.com/andreimerlescu/xlm-vanity-address-finder/vanity" // the characters every position can take
+	"github.com/stellar/go/keypair"                              // the keygen being checked
+	"github.com/stellar/go/strkey"                               // used for the raw bits of the seeds
+	"math"                                                       // used for the p-values of the tests
+	"math/bits"                                                  // used for counting the bits of the seeds
+	"os"                                                         // access the filesystem
+	"runtime"                                                    // used for the default number of cores
+	"strings"                                                    // used for indexing the characters
+	"sync"                                                       // used for waiting on the workers
+)
+
+// rngTest is one test of rngcheck, also an entry of its --json document
+type rngTest struct {
+	Name      string  `json:"name"`
+	Statistic float64 `json:"statistic"`    // the chi-square, or the z-score of the bits
+	DF        int     `json:"df,omitempty"` // the degrees of freedom of a chi-square
+	P         float64 `json:"p"`            // the chance a uniform source scores at least as far from the expected
+	OK        bool    `json:"ok"`           // whether P stays above the threshold of the sample
+}
+
+// rngReport is what rngcheck found, also its --json document
+type rngReport struct {
+	Samples    int       `json:"samples"`    // the key pairs generated
+	Alpha      float64   `json:"alpha"`      // the chance that a uniform source fails any test
+	Threshold  float64   `json:"threshold"`  // the p-value below which a test fails, alpha over the number of tests
+	Duplicates int       `json:"duplicates"` // addresses or seeds generated twice, which never happens to a working source
+	Tests      []rngTest `json:"tests"`
+	OK         bool      `json:"ok"`
+}
+
+// rngCounts are the characters found at every position of the addresses and seeds of a worker, and the bits of its seeds
+type rngCounts struct {
+	address, seed [vanity.AddressLength][32]int64
+	ones, bits    int64
+	addresses     []string
+	seeds         []string
+}
+
+// runRngCheck implements `xlm-vanity-address-finder rngcheck`, generating -n key pairs and testing whether every
+// position of their addresses and seeds is as uniform as a working entropy source makes it; it returns the process
+// exit code, 1 when a test failed
+func runRngCheck(args []string) int {
+	flags := flag.NewFlagSet("rngcheck", flag.ExitOnError)
+	flags.Usage = func() {
+		_, _ = fmt.Fprintf(flags.Output(), "Usage: %s rngcheck [-n 200000] [-cores n] [-alpha 0.001] [-json]\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	samples := flags.Int("n", 200000, "Key pairs to generate, at least 10000")
+	cores := flags.Int("cores", runtime.GOMAXPROCS(0), "Processors to generate them with")
+	alpha := flags.Float64("alpha", 0.001, "Chance that a working entropy source fails any of the tests")
+	asJSON := flags.Bool("json", jsonOutput, "Print a single JSON document instead of the report")
+	_ = flags.Parse(args) // ExitOnError handles the error
+	if flags.NArg() != 0 || *samples < 10000 || *cores < 1 || *alpha <= 0 || *alpha >= 1 {
+		flags.Usage()
+		return 2
+	}
+	if !*asJSON {
+		fmt.Printf("Generating %s key pairs on %d cores...\n", FormatInt64(int64(*samples)), *cores)
+	}
+
+	counts, err := countKeys(*samples, *cores)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	report := testKeys(counts, *samples, *alpha)
+	if *asJSON {
+		if err := printJSON(report); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "%v\n", err)
+			return 1
+		}
+	} else {
+		printRngReport(report)
+	}
+	if !report.OK {
+		return 1
+	}
+	return 0
+}
+
+// countKeys generates samples key pairs on cores go-routines and counts their characters and bits
+func countKeys(samples, cores int) (*rngCounts, error) {
+	parts := make([]rngCounts, cores)
+	errs := make([]error, cores)
+	var workers sync.WaitGroup
+	for i := range parts {
+		workers.Add(1)
+		go func(c *rngCounts, n int, err *error) {
+			defer workers.Done()
+			for ; n > 0; n-- {
+				pair, randomErr := keypair.Random()
+				if randomErr != nil {
+					*err = randomErr
+					return
+				}
+				address, seed := pair.Address(), pair.Seed()
+				for position := 1; position < vanity.AddressLength; position++ {
+					c.address[position][strings.IndexByte(vanity.Alphabet, address[position])]++
+					c.seed[position][strings.IndexByte(vanity.Alphabet, seed[position])]++
+				}
+				raw, decodeErr := strkey.Decode(strkey.VersionByteSeed, seed)
+				if decodeErr != nil {
+					*err = decodeErr
+					return
+				}
+				for _, b := range raw {
+					c.ones += int64(bits.OnesCount8(b))
+				}
+				c.bits += int64(len(raw)) * 8
+				c.addresses, c.seeds = append(c.addresses, address), append(c.seeds, seed)
+			}
+		}(&parts[i], samples/cores+btoi(i < samples%cores), &errs[i])
+	}
+	workers.Wait()
+
+	total := &parts[0]
+	for i := range parts {
+		if errs[i] != nil {
+			return nil, fmt.Errorf("the keygen failed: %w", errs[i])
+		}
+		if i == 0 {
+			continue
+		}
+		for position := range total.address {
+			for char := range total.address[position] {
+				total.address[position][char] += parts[i].address[position][char]
+				total.seed[position][char] += parts[i].seed[position][char]
+			}
+		}
+		total.ones, total.bits = total.ones+parts[i].ones, total.bits+parts[i].bits
+		total.addresses, total.seeds = append(total.addresses, parts[i].addresses...), append(total.seeds, parts[i].seeds...)
+	}
+	return total, nil
+}
+
+// btoi is 1 for true
+func btoi(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// testKeys runs the tests of rngcheck over counts of samples key pairs, failing those a working source only fails
+// with a chance of alpha between all of them
+func testKeys(counts *rngCounts, samples int, alpha float64) rngReport {
+	report := rngReport{Samples: samples, Alpha: alpha, OK: true}
+	for _, list := range [][]string{counts.addresses, counts.seeds} {
+		seen := make(map[string]struct{}, len(list))
+		for _, key := range list {
+			if _, ok := seen[key]; ok {
+				report.Duplicates++
+			}
+			seen[key] = struct{}{}
+		}
+	}
+
+	var overall [2][32]int64 // the characters of the uniform positions, of the addresses and the seeds
+	for field, positions := range [][vanity.AddressLength][32]int64{counts.address, counts.seed} {
+		name := [...]string{"address", "seed"}[field]
+		for position := 1; position < vanity.AddressLength; position++ {
+			alphabet := vanity.PositionAlphabet(position)
+			observed := positions[position][:len(alphabet)] // the alphabet is the start of vanity.Alphabet
+			statistic, df := chiSquare(observed)
+			report.Tests = append(report.Tests, rngTest{Name: fmt.Sprintf("%s position %d", name, position+1),
+				Statistic: statistic, DF: df, P: chiSquareP(statistic, df)})
+			if len(alphabet) == len(vanity.Alphabet) {
+				for char := range observed {
+					overall[field][char] += observed[char]
+				}
+			}
+		}
+		statistic, df := chiSquare(overall[field][:])
+		report.Tests = append(report.Tests, rngTest{Name: name + " characters", Statistic: statistic, DF: df,
+			P: chiSquareP(statistic, df)})
+	}
+	z := (float64(counts.ones) - float64(counts.bits)/2) / math.Sqrt(float64(counts.bits)/4)
+	report.Tests = append(report.Tests, rngTest{Name: "seed bits", Statistic: z, P: math.Erfc(math.Abs(z) / math.Sqrt2)})
+
+	report.Threshold = alpha / float64(len(report.Tests)) // Bonferroni, the tests are as many chances to fail
+	for i := range report.Tests {
+		report.Tests[i].OK = report.Tests[i].P >= report.Threshold
+		report.OK = report.OK && report.Tests[i].OK
+	}
+	report.OK = report.OK && report.Duplicates == 0
+	return report
+}
+
+// chiSquare returns the chi-square of observed against the same count in every cell, and its degrees of freedom
+func chiSquare(observed []int64) (float64, int) {
+	var total int64
+	for _, count := range observed {
+		total += count
+	}
+	expected := float64(total) / float64(len(observed))
+	var statistic float64
+	for _, count := range observed {
+		statistic += (float64(count) - expected) * (float64(count) - expected) / expected
+	}
+	return statistic, len(observed) - 1
+}
+
+// chiSquareP returns the chance of a chi-square of at least statistic with df degrees of freedom, by the
+// Wilson-Hilferty approximation, close enough from 3 degrees of freedom on
+func chiSquareP(statistic float64, df int) float64 {
+	k := float64(df)
+	z := (math.Cbrt(statistic/k) - (1 - 2/(9*k))) / math.Sqrt(2/(9*k))
+	return math.Erfc(z/math.Sqrt2) / 2
+}
+
+// printRngReport prints the failed tests and the closest call of those that passed
+func printRngReport(report rngReport) {
+	worst := report.Tests[0]
+	failed := 0
+	for _, test := range report.Tests {
+		if test.P < worst.P {
+			worst = test
+		}
+		if !test.OK {
+			failed++
+			fmt.Printf("FAIL  %s: p = %.2g, below %.2g\n", test.Name, test.P, report.Threshold)
+		}
+	}
+	if report.Duplicates > 0 {
+		fmt.Printf("FAIL  %s addresses or seeds were generated twice\n", FormatInt64(int64(report.Duplicates)))
+	} else {
+		fmt.Println("ok    no address or seed was generated twice")
+	}
+	status := "ok  "
+	if failed > 0 {
+		status = "FAIL"
+	}
+	fmt.Printf("%s  %d of %d tests passed, the lowest p = %.2g (%s), a test fails below %.2g\n", status,
+		len(report.Tests)-failed, len(report.Tests), worst.P, worst.Name, report.Threshold)
+	if report.OK {
+		fmt.Println("Nothing suggests the entropy source of this machine is broken.")
+	} else {
+		fmt.Println("The keys of this machine don't look random: don't fund any of them, and run rngcheck again with a larger -n.")
+	}
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"database/sql"         // runs.db is queried through database/sql
+	"encoding/json"        // the config snapshot is stored as JSON
+	"flag"                 // the runs subcommand parses its own arguments, and the config snapshot lists every flag
+	"fmt"                  // used for printing runs and wrapping errors
+	_ "modernc.org/sqlite" // the pure Go SQLite driver, no cgo needed
+	"os"                   // access the filesystem
+	"path/filepath"        // used for locating runs.db in the user config directory
+	"sort"                 // used for listing the patterns of a run in order
+	"strconv"              // used for parsing the run ID of runs show
+	"strings"              // used for joining patterns
+	"text/tabwriter"       // used for aligning runs list
+	"time"                 // used for the start and end of every run
+)
+
+// runsSchema creates the tables of runs.db; a run is inserted when it starts and completed when it ends, so a run
+// without an ended_at crashed or was killed
+const runsSchema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	started_at TEXT    NOT NULL,
+	ended_at   TEXT,
+	hostname   TEXT    NOT NULL,
+	pid        INTEGER NOT NULL,
+	cores      INTEGER NOT NULL,
+	patterns   TEXT    NOT NULL,
+	config     TEXT    NOT NULL,
+	attempts   INTEGER NOT NULL DEFAULT 0,
+	matches    INTEGER NOT NULL DEFAULT 0,
+	rate       REAL    NOT NULL DEFAULT 0,
+	exit_code  INTEGER
+);
+CREATE TABLE IF NOT EXISTS run_matches (
+	run_id  INTEGER NOT NULL REFERENCES runs(id),
+	pattern TEXT    NOT NULL,
+	matches INTEGER NOT NULL,
+	PRIMARY KEY (run_id, pattern)
+);`
+
+// defaultRunsDB is where runs are recorded when -runs-db isn't set, next to the other per-user state
+func defaultRunsDB() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "runs.db"
+	}
+	return filepath.Join(dir, keyringService, "runs.db")
+}
+
+// openRunsDB opens path, creating it and its tables when needed
+func openRunsDB(path string) (*sql.DB, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(runsSchema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to create the tables of %s: %w", path, err)
+	}
+	return db, nil
+}
+
+// runRecord is the row of the current run in runs.db
+type runRecord struct {
+	db      *sql.DB
+	id      int64
+	started time.Time
+}
+
+// startRun records a run starting now, with a snapshot of every flag
+func startRun(path, hostname string, pid, cores int, patterns []string) (*runRecord, error) {
+	db, err := openRunsDB(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open -runs-db %s: %w", path, err)
+	}
+	config := make(map[string]string)
+	flag.VisitAll(func(f *flag.Flag) { config[f.Name] = flagValue(f) })
+	snapshot, err := json.Marshal(config)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	started := time.Now()
+	inserted, err := db.Exec(`INSERT INTO runs (started_at, hostname, pid, cores, patterns, config) VALUES (?, ?, ?, ?, ?, ?)`,
+		started.Format(time.RFC3339), hostname, pid, cores, strings.Join(patterns, ","), string(snapshot))
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to record the run in %s: %w", path, err)
+	}
+	id, err := inserted.LastInsertId()
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return &runRecord{db: db, id: id, started: started}, nil
+}
+
+// Finish completes the run with its totals and exit code and closes runs.db
+func (r *runRecord) Finish(attempts int64, byPattern map[string]int, code int) error {
+	defer func() { _ = r.db.Close() }()
+	ended := time.Now()
+	matches, rate := 0, 0.0
+	for _, n := range byPattern {
+		matches += n
+	}
+	if seconds := ended.Sub(r.started).Seconds(); seconds > 0 {
+		rate = float64(attempts) / seconds
+	}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }() // a no-op after Commit
+	if _, err := tx.Exec(`UPDATE runs SET ended_at = ?, attempts = ?, matches = ?, rate = ?, exit_code = ? WHERE id = ?`,
+		ended.Format(time.RFC3339), attempts, matches, rate, code, r.id); err != nil {
+		return err
+	}
+	for pattern, n := range byPattern {
+		if _, err := tx.Exec(`INSERT INTO run_matches (run_id, pattern, matches) VALUES (?, ?, ?)`, r.id, pattern, n); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// runRuns implements `xlm-vanity-address-finder runs list` and `runs show <id>`; it returns the process exit code
+func runRuns(args []string) int {
+	flags := flag.NewFlagSet("runs", flag.ExitOnError)
+	path := flags.String("db", defaultRunsDB(), "Path of the runs database")
+	limit := flags.Int("n", 50, "Most recent runs listed by runs list")
+	flags.Usage = func() {
+		_, _ = fmt.Fprintf(flags.Output(), "Usage: %s runs [-db path] [-n 50] list | show <id>\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args) // ExitOnError handles the error
+
+	var (
+		err  error
+		list = flags.NArg() == 1 && flags.Arg(0) == "list"
+		show = flags.NArg() == 2 && flags.Arg(0) == "show"
+	)
+	if !list && !show {
+		flags.Usage()
+		return 2
+	}
+	if _, statErr := os.Stat(*path); statErr != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "no runs recorded yet: %v\n", statErr)
+		return 1
+	}
+	db, err := openRunsDB(*path)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	defer func() { _ = db.Close() }()
+
+	if list {
+		err = listRuns(db, *limit)
+	} else {
+		id, parseErr := strconv.ParseInt(flags.Arg(1), 10, 64)
+		if parseErr != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "invalid run ID %q\n", flags.Arg(1))
+			return 2
+		}
+		err = showRun(db, id)
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// listRuns prints the most recent runs, newest first
+func listRuns(db *sql.DB, limit int) error {
+	rows, err := db.Query(`SELECT id, started_at, COALESCE(ended_at, ''), hostname, cores, patterns, attempts, rate, matches,
+		COALESCE(exit_code, -1) FROM runs ORDER BY id DESC LIMIT ?`, limit)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "ID\tSTARTED\tDURATION\tHOST\tCORES\tPATTERNS\tATTEMPTS\tRATE/S\tMATCHES\tEXIT")
+	for rows.Next() {
+		var (
+			id, attempts             int64
+			cores, matches, exitCode int
+			started, ended, host     string
+			patterns                 string
+			rate                     float64
+		)
+		if err := rows.Scan(&id, &started, &ended, &host, &cores, &patterns, &attempts, &rate, &matches, &exitCode); err != nil {
+			return err
+		}
+		duration, exit := "running", "-"
+		if ended != "" {
+			duration, exit = runDuration(started, ended), strconv.Itoa(exitCode)
+		}
+		_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%d\t%s\t%s\t%s\t%d\t%s\n", id, started, duration, host, cores, patterns,
+			FormatInt64(attempts), FormatInt64(int64(rate)), matches, exit)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// showRun prints everything recorded about one run
+func showRun(db *sql.DB, id int64) error {
+	var (
+		started, ended, host, patterns, config string
+		pid, cores, matches, exitCode          int
+		attempts                               int64
+		rate                                   float64
+	)
+	err := db.QueryRow(`SELECT started_at, COALESCE(ended_at, ''), hostname, pid, cores, patterns, config, attempts, rate,
+		matches, COALESCE(exit_code, -1) FROM runs WHERE id = ?`, id).
+		Scan(&started, &ended, &host, &pid, &cores, &patterns, &config, &attempts, &rate, &matches, &exitCode)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("no run %d", id)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Run:      %d\n", id)
+	fmt.Printf("Host:     %s (PID %d, %d cores)\n", host, pid, cores)
+	fmt.Printf("Started:  %s\n", started)
+	if ended != "" {
+		fmt.Printf("Ended:    %s (%s, exit code %d)\n", ended, runDuration(started, ended), exitCode)
+	} else {
+		fmt.Printf("Ended:    never, still running or killed\n")
+	}
+	fmt.Printf("Patterns: %s\n", patterns)
+	fmt.Printf("Attempts: %s at %s addresses/s\n", FormatInt64(attempts), FormatInt64(int64(rate)))
+	fmt.Printf("Matches:  %d\n", matches)
+
+	rows, err := db.Query(`SELECT pattern, matches FROM run_matches WHERE run_id = ? ORDER BY pattern`, id)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var pattern string
+		var n int
+		if err := rows.Scan(&pattern, &n); err != nil {
+			return err
+		}
+		fmt.Printf("          %s: %d\n", pattern, n)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	values := make(map[string]string)
+	if err := json.Unmarshal([]byte(config), &values); err != nil {
+		return fmt.Errorf("corrupt config snapshot: %w", err)
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Println("Config:")
+	for _, name := range names {
+		fmt.Printf("  -%s=%q\n", name, values[name])
+	}
+	return nil
+}
+
+// runDuration is the time between two RFC 3339 timestamps of runs.db, to the second
+func runDuration(started, ended string) string {
+	start, startErr := time.Parse(time.RFC3339, started)
+	end, endErr := time.Parse(time.RFC3339, ended)
+	if startErr != nil || endErr != nil {
+		return "?"
+	}
+	return end.Sub(start).Round(time.Second).String()
+}
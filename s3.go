@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bytes"                                                   // used for joining the object and the appended lines
+	"context"                                                 // every S3 call runs with a timeout
+	"errors"                                                  // used for detecting a missing object on first run
+	"fmt"                                                     // used for wrapping errors with the object involved
+	"github.com/aws/aws-sdk-go-v2/aws"                        // used for the pointers of the S3 inputs
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http" // used for telling a conditional write that lost apart
+	"github.com/aws/aws-sdk-go-v2/service/s3"                 // the s3:// -output
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"   // used for the encryption and the parts of an append
+	"io"                                                      // used for reading the object
+	"net/http"                                                // used for the status of a conditional write that lost
+	"net/url"                                                 // used for escaping the copy source of an append
+)
+
+// s3Scheme prefixes an -output (or -output-template) that appends every result to an S3 object as a JSON line
+const s3Scheme = "s3://"
+
+// s3PartMinimum is the smallest part of a multipart upload but the last one, an object this big is appended to by
+// copying it into the first part; a smaller one is read and written back whole
+const s3PartMinimum = 5 << 20
+
+// the -s3-sse values
+const (
+	s3SSES3  = "AES256"  // keys managed by S3
+	s3SSEKMS = "aws:kms" // keys managed by KMS, -s3-sse-kms-key or the aws/s3 key
+)
+
+// s3Options are how every s3:// object is written
+type s3Options struct {
+	sse      string // -s3-sse, the bucket default when empty
+	kmsKey   string // -s3-sse-kms-key, with -s3-sse aws:kms
+	endpoint string // -s3-endpoint of an S3-compatible service like MinIO, AWS when empty
+}
+
+// validate checks the combination of the options
+func (o s3Options) validate() error {
+	if o.sse != "" && o.sse != s3SSES3 && o.sse != s3SSEKMS {
+		return fmt.Errorf("invalid -s3-sse %q: expected %s or %s", o.sse, s3SSES3, s3SSEKMS)
+	}
+	if o.kmsKey != "" && o.sse != s3SSEKMS {
+		return fmt.Errorf("-s3-sse-kms-key needs -s3-sse %s", s3SSEKMS)
+	}
+	return nil
+}
+
+// s3Object is the object of an s3:// -output. S3 can't append, so an object under s3PartMinimum is written back whole
+// and a bigger one is completed by a multipart upload whose first part is a copy of the object; both only go ahead
+// while the object still has the ETag it was read with, and an append that loses to another writer reads it again.
+type s3Object struct {
+	bucket, key string     // where the object is
+	client      *s3.Client // reads and writes the object
+	options     s3Options  // how the object is encrypted
+	size        int64      // bytes of the object
+	etag        string     // ETag of the object, an append fails when someone else changed it
+}
+
+// openS3Object returns the object key of bucket, with the credentials and the region resolved like the aws CLI does
+func openS3Object(bucket, key string, options s3Options) (*s3Object, error) {
+	if err := options.validate(); err != nil {
+		return nil, err
+	}
+	cfg, err := loadAWSConfig()
+	if err != nil {
+		return nil, err
+	}
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		o.RetryMaxAttempts = remoteAttempts // every call retries throttling and 5xx on top of the attempts of an append
+		if options.endpoint != "" {
+			o.BaseEndpoint = aws.String(options.endpoint)
+			o.UsePathStyle = true // MinIO and most S3-compatible services don't serve buckets as subdomains
+		}
+	})
+	return &s3Object{bucket: bucket, key: key, client: client, options: options}, nil
+}
+
+// Read returns the object, nothing when it doesn't exist yet, and remembers its size and ETag for the next append
+func (o *s3Object) Read() ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), awsTimeout)
+	defer cancel()
+	object, err := o.client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(o.bucket), Key: aws.String(o.key)})
+	var missing *s3types.NoSuchKey
+	if errors.As(err, &missing) {
+		o.size, o.etag = 0, ""
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = object.Body.Close() }()
+	data, err := io.ReadAll(object.Body)
+	if err != nil {
+		zeroize(data)
+		return nil, err
+	}
+	o.size, o.etag = int64(len(data)), aws.ToString(object.ETag)
+	return data, nil
+}
+
+// Append adds lines to the end of the object, creating it when it doesn't exist yet. When another writer changed the
+// object since it was read, or a write went through but its response was lost, the object is read again: the append
+// is done when lines are already in it, and tried again on top of the object as it is now otherwise.
+func (o *s3Object) Append(lines []byte) error {
+	for attempt := 1; ; attempt++ {
+		var err error
+		if o.size >= s3PartMinimum {
+			err = o.appendParts(lines)
+		} else {
+			err = o.appendWhole(lines)
+		}
+		if !s3Conflict(err) || attempt == remoteAttempts {
+			return err
+		}
+		debugf("s3://%s/%s changed since it was read, reading it again: %v", o.bucket, o.key, err)
+		existing, err := o.Read() // the ETag the append was conditional on is stale, never write on top of it again
+		appended := hasLines(existing, lines)
+		zeroize(existing)
+		if err != nil || appended {
+			return err
+		}
+	}
+}
+
+// appendWhole writes the object back with lines at its end, unless lines are already in it, only while the object
+// still has the ETag it was read with or still doesn't exist
+func (o *s3Object) appendWhole(lines []byte) error {
+	existing, err := o.Read()
+	defer zeroize(existing)
+	if err != nil {
+		return err
+	}
+	if hasLines(existing, lines) {
+		return nil // an earlier attempt went through, only its response was lost
+	}
+	body := append(append(make([]byte, 0, len(existing)+len(lines)), existing...), lines...)
+	defer zeroize(body)
+
+	ctx, cancel := context.WithTimeout(context.Background(), awsTimeout)
+	defer cancel()
+	input := &s3.PutObjectInput{Bucket: aws.String(o.bucket), Key: aws.String(o.key), Body: bytes.NewReader(body),
+		ContentType: aws.String("application/x-ndjson")}
+	if o.etag == "" {
+		input.IfNoneMatch = aws.String("*") // someone else may create it first
+	} else {
+		input.IfMatch = aws.String(o.etag) // someone else may append first, don't write back over what they wrote
+	}
+	if o.options.sse != "" {
+		input.ServerSideEncryption = s3types.ServerSideEncryption(o.options.sse)
+	}
+	if o.options.kmsKey != "" {
+		input.SSEKMSKeyId = aws.String(o.options.kmsKey)
+	}
+	put, err := o.client.PutObject(ctx, input)
+	if err != nil {
+		return err
+	}
+	o.size, o.etag = int64(len(body)), aws.ToString(put.ETag)
+	return nil
+}
+
+// appendParts appends lines to an object of at least s3PartMinimum with a multipart upload of two parts, a server side
+// copy of the object and lines, aborting the upload when any step fails; the copy and the completion both only go
+// ahead while the object still has the ETag it was read with
+func (o *s3Object) appendParts(lines []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*awsTimeout) // the copy of a big object takes a while
+	defer cancel()
+	create := &s3.CreateMultipartUploadInput{Bucket: aws.String(o.bucket), Key: aws.String(o.key),
+		ContentType: aws.String("application/x-ndjson")}
+	if o.options.sse != "" {
+		create.ServerSideEncryption = s3types.ServerSideEncryption(o.options.sse)
+	}
+	if o.options.kmsKey != "" {
+		create.SSEKMSKeyId = aws.String(o.options.kmsKey)
+	}
+	upload, err := o.client.CreateMultipartUpload(ctx, create)
+	if err != nil {
+		return err
+	}
+	abort := func(err error) error {
+		_, _ = o.client.AbortMultipartUpload(context.Background(), &s3.AbortMultipartUploadInput{Bucket: aws.String(o.bucket),
+			Key: aws.String(o.key), UploadId: upload.UploadId}) // best effort, a lifecycle rule cleans up the rest
+		return err
+	}
+
+	copied, err := o.client.UploadPartCopy(ctx, &s3.UploadPartCopyInput{Bucket: aws.String(o.bucket), Key: aws.String(o.key),
+		UploadId: upload.UploadId, PartNumber: aws.Int32(1), CopySource: aws.String(o.bucket + "/" + url.PathEscape(o.key)),
+		CopySourceIfMatch: aws.String(o.etag)})
+	if err != nil {
+		return abort(err)
+	}
+	part, err := o.client.UploadPart(ctx, &s3.UploadPartInput{Bucket: aws.String(o.bucket), Key: aws.String(o.key),
+		UploadId: upload.UploadId, PartNumber: aws.Int32(2), Body: bytes.NewReader(lines)})
+	if err != nil {
+		return abort(err)
+	}
+	done, err := o.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{Bucket: aws.String(o.bucket),
+		Key: aws.String(o.key), UploadId: upload.UploadId, IfMatch: aws.String(o.etag), MultipartUpload: &s3types.CompletedMultipartUpload{Parts: []s3types.CompletedPart{
+			{PartNumber: aws.Int32(1), ETag: copied.CopyPartResult.ETag},
+			{PartNumber: aws.Int32(2), ETag: part.ETag},
+		}}})
+	if err != nil {
+		return abort(err)
+	}
+	o.size, o.etag = o.size+int64(len(lines)), aws.ToString(done.ETag)
+	return nil
+}
+
+// s3Conflict reports whether err is S3 turning down a conditional write or copy, the object changed since it was read
+func s3Conflict(err error) bool {
+	var response *awshttp.ResponseError
+	if !errors.As(err, &response) {
+		return false
+	}
+	return response.HTTPStatusCode() == http.StatusPreconditionFailed || response.HTTPStatusCode() == http.StatusConflict
+}
+
+// hasLines reports whether lines, whole lines each ending with a newline, appear in object starting at a line
+func hasLines(object, lines []byte) bool {
+	for start := 0; len(lines) > 0; {
+		at := bytes.Index(object[start:], lines)
+		if at < 0 {
+			return false
+		}
+		if start+at == 0 || object[start+at-1] == '\n' {
+			return true
+		}
+		start += at + 1
+	}
+	return false
+}
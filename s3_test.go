@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"                                     // used for numbering the ETags of the fake bucket
+	"github.com/aws/aws-sdk-go-v2/aws"        // used for pointing the client at the fake bucket
+	"github.com/aws/aws-sdk-go-v2/service/s3" // the client of the object under test
+	"io"                                      // used for reading the bodies of the writes
+	"net/http"                                // the fake bucket
+	"net/http/httptest"                       // used for serving the fake bucket
+	"sync"                                    // used for guarding the fake object
+	"testing"                                 // the test harness
+)
+
+// fakeS3 is a bucket of one object that honours If-Match and If-None-Match on writes like S3 does; beforePut, when
+// set, runs before a write is handled and tells whether to drop the connection after it was applied
+type fakeS3 struct {
+	mu        sync.Mutex
+	body      []byte
+	etag      string
+	writes    int
+	beforePut func(f *fakeS3) (lose bool)
+}
+
+// set replaces the object, the caller must hold f.mu
+func (f *fakeS3) set(body []byte) {
+	f.writes++
+	f.body, f.etag = body, fmt.Sprintf(`"%d"`, f.writes)
+}
+
+// ServeHTTP serves GetObject and PutObject
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodGet:
+		if f.etag == "" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, `<Error><Code>NoSuchKey</Code></Error>`)
+			return
+		}
+		w.Header().Set("ETag", f.etag)
+		_, _ = w.Write(f.body)
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		lose := false
+		if f.beforePut != nil {
+			lose = f.beforePut(f)
+		}
+		if match := r.Header.Get("If-Match"); match != "" && match != f.etag ||
+			r.Header.Get("If-None-Match") == "*" && f.etag != "" {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			_, _ = io.WriteString(w, `<Error><Code>PreconditionFailed</Code></Error>`)
+			return
+		}
+		f.set(body)
+		if lose {
+			panic(http.ErrAbortHandler) // the write went through, its response never arrives
+		}
+		w.Header().Set("ETag", f.etag)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// newFakeS3Object returns the object of f, served by a test server
+func newFakeS3Object(t *testing.T, f *fakeS3) *s3Object {
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	client := s3.New(s3.Options{Region: "us-east-1", BaseEndpoint: aws.String(server.URL), UsePathStyle: true,
+		Credentials: aws.AnonymousCredentials{}, RetryMaxAttempts: 1,
+		RequestChecksumCalculation: aws.RequestChecksumCalculationWhenRequired,
+		ResponseChecksumValidation: aws.ResponseChecksumValidationWhenRequired})
+	return &s3Object{bucket: "bucket", key: "results.jsonl", client: client}
+}
+
+// TestS3AppendConflict expects an append that loses to another writer between its read and its write to keep what the
+// other writer wrote
+func TestS3AppendConflict(t *testing.T) {
+	f := &fakeS3{}
+	f.set([]byte("first\n"))
+	f.beforePut = func(f *fakeS3) bool {
+		f.beforePut = nil
+		f.set(append(f.body, "other\n"...))
+		return false
+	}
+	o := newFakeS3Object(t, f)
+	if _, err := o.Read(); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Append([]byte("mine\n")); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(f.body), "first\nother\nmine\n"; got != want {
+		t.Fatalf("the object holds %q, want %q", got, want)
+	}
+}
+
+// TestS3AppendLostResponse expects an append whose write went through without a response not to wedge the object on
+// the stale ETag, and the append tried again not to write its lines twice
+func TestS3AppendLostResponse(t *testing.T) {
+	f := &fakeS3{}
+	f.beforePut = func(f *fakeS3) bool {
+		f.beforePut = nil
+		return true
+	}
+	o := newFakeS3Object(t, f)
+	if err := o.Append([]byte("mine\n")); err == nil {
+		t.Fatal("Append succeeded without a response")
+	}
+	for _, lines := range []string{"mine\n", "next\n"} {
+		if err := o.Append([]byte(lines)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := string(f.body), "mine\nnext\n"; got != want {
+		t.Fatalf("the object holds %q, want %q", got, want)
+	}
+}
+
+// TestHasLines expects lines to be found only where they start a line of the object
+func TestHasLines(t *testing.T) {
+	for _, c := range []struct {
+		object, lines string
+		want          bool
+	}{
+		{"a\nb\n", "a\n", true},
+		{"a\nb\n", "b\n", true},
+		{"a\nb\nc\n", "b\nc\n", true},
+		{"ab\n", "b\n", false},
+		{"xb\nb\n", "b\n", true},
+		{"", "a\n", false},
+	} {
+		if got := hasLines([]byte(c.object), []byte(c.lines)); got != c.want {
+			t.Errorf("hasLines(%q, %q) = %v, want %v", c.object, c.lines, got, c.want)
+		}
+	}
+}
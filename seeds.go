@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"  // used for rejecting -kms-key with a backend that can't use it
+	"fmt"     // used for reporting an invalid -store
+	"strings" // used for telling the -output schemes apart
+	"time"    // used for pausing between the attempts of storeSeed
+)
+
+// seedAttempts is how many times storeSeed tries a backend before the seed goes to the rescueFile
+const seedAttempts = 3
+
+// the -store values
+const (
+	storeFile    = "file"    // seeds are saved in the output files, or where -output and -kms-key send them
+	storeKeyring = "keyring" // seeds go to the OS keyring
+)
+
+// seedBackend keeps found seeds out of the output files: Store sends or seals the seed of r somewhere else, empties
+// r.Seed and records in r where the seed went
+type seedBackend interface {
+	Store(r *result) error
+}
+
+// storeSeed stores the seed of r with seeds, trying seedAttempts times with a growing pause in between, so a backend
+// that is down for a moment doesn't end the search; r is only changed once the seed is stored
+func storeSeed(seeds seedBackend, r *result) error {
+	for attempt := 1; ; attempt++ {
+		err := seeds.Store(r)
+		if err == nil || attempt == seedAttempts {
+			return err
+		}
+		debugf("attempt %d of storing the seed of %s failed: %v", attempt, r.Address, err)
+		time.Sleep(time.Duration(attempt*attempt) * time.Second)
+	}
+}
+
+// seedURL reports whether output names a secret store such as vault://secret/data/xlm instead of a file
+func seedURL(output string) bool {
+	return strings.HasPrefix(output, vaultScheme) || strings.HasPrefix(output, secretsManagerScheme)
+}
+
+// openSeedBackend returns the -store or the secret store an -output URL points at, or the -kms-key envelope encryption
+// of seeds kept in the output files; nil when seeds are saved as they are
+func openSeedBackend(output, kmsKey, store, storeVault string) (seedBackend, error) {
+	if store != storeFile && (kmsKey != "" || seedURL(output)) {
+		return nil, errors.New("-store " + store + " can't be combined with -kms-key or an -output secret store")
+	}
+	switch {
+	case store == storeKeyring:
+		return openKeyring()
+	case store == storeOnePassword || store == storeBitwarden:
+		return openPasswordManager(store, storeVault)
+	case store != storeFile:
+		return nil, fmt.Errorf("invalid -store %q: expected %s, %s, %s or %s", store, storeFile, storeKeyring, storeOnePassword, storeBitwarden)
+	case strings.HasPrefix(output, vaultScheme):
+		if kmsKey != "" {
+			return nil, errors.New("-kms-key can't be combined with -output " + output)
+		}
+		return openVault(output)
+	case strings.HasPrefix(output, secretsManagerScheme):
+		return openSecretsManager(output, kmsKey)
+	case kmsKey != "":
+		return openKMSEnvelope(kmsKey)
+	default:
+		return nil, nil
+	}
+}
@@ -0,0 +1,340 @@
+package main
+
+import (
+	"bytes"                                                      // used for comparing the keys and signatures with the vectors
+	"crypto/ed25519"                                             // the RFC 8032 vector is checked against the standard library too
+	crand "crypto/rand"                                          // the random strkeys the vector encoder is checked with
+	"encoding/hex"                                               // the vectors are written in hex
+	"errors"                                                     // used for describing a failed check
+	"flag"                                                       // the selftest subcommand parses its own arguments
+	"fmt"                                                        // used for printing the report
+	"github.com/andreimerlescu/xlm-vanity-address-finder/vanity" // the zero-allocation keygen being checked
+	"github.com/stellar/go/keypair"                              // the keygen being checked
+	"github.com/stellar/go/strkey"                               // the encoding being checked
+	"os"                                                         // access the filesystem
+	"path/filepath"                                              // used for the results file of the round-trip
+	"time"                                                       // used for timing every check
+)
+
+// selfCheck is one check of selftest
+type selfCheck struct {
+	name string
+	run  func() error
+}
+
+// selfCheckResult is the outcome of a selfCheck, also an entry of the --json document of selftest
+type selfCheckResult struct {
+	Name    string  `json:"name"`
+	OK      bool    `json:"ok"`
+	Error   string  `json:"error,omitempty"`
+	Seconds float64 `json:"seconds"`
+}
+
+// selfChecks are the checks of selftest, in the order they run: the encoding and keygen first, as everything else
+// relies on them
+var selfChecks = []selfCheck{
+	{"strkey vectors", checkStrkeyVectors},
+	{"ed25519 vectors", checkSignatureVectors},
+	{"deterministic search", checkDeterministicSearch},
+	{"zero-allocation keygen", checkZeroAlloc},
+	{"vector encoder", checkVectorEncoder},
+	{"batch keygen", checkBatchKeys},
+	{"matcher", checkMatcher},
+	{"persistence round-trip", checkPersistence},
+	{"keystore round-trip", checkKeystore},
+}
+
+// runSelftest implements `xlm-vanity-address-finder selftest`, running every selfCheck and reporting which passed;
+// it returns the process exit code, 1 when any failed
+func runSelftest(args []string) int {
+	flags := flag.NewFlagSet("selftest", flag.ExitOnError)
+	flags.Usage = func() {
+		_, _ = fmt.Fprintf(flags.Output(), "Usage: %s selftest [-json]\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	asJSON := flags.Bool("json", jsonOutput, "Print a single JSON document instead of the report")
+	_ = flags.Parse(args) // ExitOnError handles the error
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return 2
+	}
+
+	code := 0
+	results := make([]selfCheckResult, 0, len(selfChecks))
+	for _, check := range selfChecks {
+		started := time.Now()
+		err := check.run()
+		result := selfCheckResult{Name: check.name, OK: err == nil, Seconds: time.Since(started).Seconds()}
+		if err != nil {
+			result.Error, code = err.Error(), 1
+		}
+		results = append(results, result)
+		if *asJSON {
+			continue
+		}
+		if err != nil {
+			fmt.Printf("FAIL  %s: %v\n", check.name, err)
+		} else {
+			fmt.Printf("ok    %s (%s)\n", check.name, time.Duration(result.Seconds*float64(time.Second)).Round(time.Millisecond))
+		}
+	}
+	if *asJSON {
+		if err := printJSON(results); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "%v\n", err)
+			return 1
+		}
+	}
+	return code
+}
+
+// checkStrkeyVectors derives known seeds into their known addresses and makes sure a seed with a bad checksum is
+// rejected
+func checkStrkeyVectors() error {
+	vectors := []struct{ raw, seed, address string }{
+		{"0000000000000000000000000000000000000000000000000000000000000000",
+			"SAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABSU2", "GA5WUJ54Z23KILLCUOUNAKTPBVZWKMQVO4O6EQ5GHLAERIMLLHNCSKYH"},
+		{"000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f",
+			"SAAACAQDAQCQMBYIBEFAWDANBYHRAEISCMKBKFQXDAMRUGY4DUPB6NKI", "GAB2CB576PHBBPQ5ODORRZ2LYCMWPZGWGCN2KDK7DXOIMZASKUY3QZ6Q"},
+		{"", "SDJHRQF4GCMIIKAAAQ6IHY42X73FQFLHUULAPSKKD4DFDM7UXWWCRHBE", "GCZHXL5HXQX5ABDM26LHYRCQZ5OJFHLOPLZX47WEBP3V2PF5AVFK2A5D"},
+	}
+	for _, vector := range vectors {
+		if vector.raw != "" {
+			raw, _ := hex.DecodeString(vector.raw)
+			pair, err := keypair.FromRawSeed([32]byte(raw))
+			if err != nil {
+				return err
+			}
+			if pair.Seed() != vector.seed {
+				return fmt.Errorf("raw seed %s encodes to %s, expected %s", vector.raw, pair.Seed(), vector.seed)
+			}
+		}
+		pair, err := keypair.ParseFull(vector.seed)
+		if err != nil {
+			return fmt.Errorf("%s: %w", vector.seed, err)
+		}
+		if pair.Address() != vector.address {
+			return fmt.Errorf("%s derives %s, expected %s", vector.seed, pair.Address(), vector.address)
+		}
+	}
+	corrupt := vectors[2].seed[:20] + "A" + vectors[2].seed[21:] // one character off, the checksum must catch it
+	if _, err := keypair.ParseFull(corrupt); err == nil {
+		return errors.New("a seed with a bad checksum was accepted")
+	}
+	return nil
+}
+
+// checkSignatureVectors signs the first test vector of RFC 8032 with the keygen of the search and checks the key, the
+// address and the signature
+func checkSignatureVectors() error {
+	raw, _ := hex.DecodeString("9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60")
+	public, _ := hex.DecodeString("d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a")
+	signature, _ := hex.DecodeString("e5564300c360ac729086e2cc806e828a84877f1eb8e5d974d873e065224901555fb8821590a33bacc61e39701cf9b46bd25bf5f0595bbe24655141438e7a100b")
+
+	if derived := ed25519.NewKeyFromSeed(raw).Public().(ed25519.PublicKey); !bytes.Equal(derived, public) {
+		return fmt.Errorf("crypto/ed25519 derives the public key %x, expected %x", []byte(derived), public)
+	}
+	pair, err := keypair.FromRawSeed([32]byte(raw))
+	if err != nil {
+		return err
+	}
+	address, err := strkey.Encode(strkey.VersionByteAccountID, public)
+	if err != nil {
+		return err
+	}
+	if pair.Address() != address {
+		return fmt.Errorf("the key derives %s, expected %s", pair.Address(), address)
+	}
+	signed, err := pair.Sign(nil)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(signed, signature) {
+		return fmt.Errorf("the signature is %x, expected %x", signed, signature)
+	}
+	if err := pair.Verify(nil, signed); err != nil {
+		return fmt.Errorf("the signature doesn't verify: %w", err)
+	}
+	if err := pair.Verify([]byte("x"), signed); err == nil {
+		return errors.New("the signature verifies a message it doesn't sign")
+	}
+	return nil
+}
+
+// checkDeterministicSearch searches the -deterministic-seed selftest keys of worker 0 for CAT, which is known to first
+// match after 2181 keys, on the buffers the workers encode the keys into
+func checkDeterministicSearch() error {
+	const want = "GCUXYHIRURB4RRG7DUPRGK7L2567ANRE7SKL6JPGQATF4R2NS4XAMCAT"
+	m, err := newMatcher("CAT", matchOptions{position: positionAnywhere})
+	if err != nil {
+		return err
+	}
+	keys := deterministicKeys("selftest", 0)
+	for attempts := 1; attempts <= 2181; attempts++ {
+		if err := keys.Next(); err != nil {
+			return err
+		}
+		if _, _, found := m.MatchKeys(keys); !found {
+			continue
+		}
+		pair, err := keys.Pair()
+		if err != nil {
+			return err
+		}
+		if attempts != 2181 || keys.Address() != want || pair.Address() != want || keys.Seed() != pair.Seed() {
+			return fmt.Errorf("matched %s after %d keys, expected %s after 2181", keys.Address(), attempts, want)
+		}
+		return verifySeed("selftest", want, pair.Seed()).Err
+	}
+	return fmt.Errorf("no match in 2181 keys, expected %s", want)
+}
+
+// checkZeroAlloc makes sure generating, encoding and matching a key that doesn't match allocates nothing, the hot loop
+// of every worker, with and without -fast-crypto
+func checkZeroAlloc() error {
+	m, err := vanity.NewMatcher([]string{"SELFTEST"}, vanity.Options{})
+	if err != nil {
+		return err
+	}
+	for _, keys := range []*vanity.Keys{vanity.NewKeys(nil), vanity.NewBatchKeys(nil)} {
+		allocs, err := vanity.AllocsPerKey(keys, m, 10000)
+		if err != nil {
+			return err
+		}
+		if allocs > 0 {
+			return fmt.Errorf("%g allocations per key, expected none", allocs)
+		}
+	}
+	return nil
+}
+
+// checkBatchKeys derives the -deterministic-seed selftest keys of worker 0 with and without -fast-crypto, which must
+// be the same keys in the same order
+func checkBatchKeys() error {
+	keys, batch := deterministicKeys("selftest", 0), vanity.NewBatchKeys(deterministicStream("selftest", 0))
+	for i := 0; i < 3*vanity.BatchSize; i++ {
+		if err := errors.Join(keys.Next(), batch.Next()); err != nil {
+			return err
+		}
+		if keys.Address() != batch.Address() || keys.Seed() != batch.Seed() {
+			return fmt.Errorf("key %d is %s in a batch, expected %s", i, batch.Address(), keys.Address())
+		}
+	}
+	return nil
+}
+
+// checkVectorEncoder encodes random strkeys and finds patterns in them with the AVX2 or NEON code the CPU selected and
+// with the generic one, which must agree; it fails when the CPU has the instructions but they were left unused, and
+// there is nothing to check on other CPUs
+func checkVectorEncoder() error {
+	if err := vanity.VectorError(); err != nil {
+		return err
+	}
+	return vanity.CheckEncoder(crand.Reader, 100000)
+}
+
+// checkMatcher runs the matcher over known addresses with every -position, -prefix, -suffix and -lowercase, and makes
+// sure a pattern no address can contain is rejected
+func checkMatcher() error {
+	const address = "GCUXYHIRURB4RRG7DUPRGK7L2567ANRE7SKL6JPGQATF4R2NS4XAMCAT"
+	cases := []struct {
+		find    string
+		options matchOptions
+		match   bool
+	}{
+		{"cat", matchOptions{position: positionAnywhere}, true},
+		{"dog,rurb", matchOptions{position: positionAnywhere}, true},
+		{"dog", matchOptions{position: positionAnywhere}, false},
+		{"CUXY", matchOptions{position: positionStart}, true},
+		{"CAT", matchOptions{position: positionStart}, false},
+		{"CAT", matchOptions{position: positionEnd}, true},
+		{"CUXY", matchOptions{position: positionEnd}, false},
+		{"", matchOptions{position: positionAnywhere, prefix: "GCU", suffix: "CAT"}, true},
+		{"", matchOptions{position: positionAnywhere, prefix: "GCU", suffix: "DOG"}, false},
+		{"ZS67", matchOptions{position: positionAnywhere}, false},
+		{"ZS67", matchOptions{position: positionAnywhere, lowercase: true}, true}, // 2567 reads zs67 once the address is lowercase
+	}
+	for _, c := range cases {
+		m, err := newMatcher(c.find, c.options)
+		if err != nil {
+			return fmt.Errorf("-find %q: %w", c.find, err)
+		}
+		if _, found := m.Match(address); found != c.match {
+			return fmt.Errorf("-find %q %+v matched %s: %t, expected %t", c.find, c.options, address, found, c.match)
+		}
+	}
+	if _, err := newMatcher("CAT0", matchOptions{position: positionAnywhere}); err == nil {
+		return errors.New("-find CAT0 was accepted, no address contains a 0")
+	}
+	return nil
+}
+
+// checkPersistence saves a result to a results file in a temporary directory, reopens it and reads it back
+func checkPersistence() error {
+	dir, err := os.MkdirTemp("", "xlm-vanity-selftest-")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	path := filepath.Join(dir, "selftest.json")
+
+	pair, err := keypair.FromRawSeed([32]byte{1})
+	if err != nil {
+		return err
+	}
+	want := result{Address: pair.Address(), Seed: pair.Seed(), Pattern: "SELFTEST", FoundAt: time.Now().UTC(), PID: os.Getpid()}
+	s, err := openStore(path, 1, 0, time.Now())
+	if err != nil {
+		return err
+	}
+	added, err := s.Add(want)
+	if err != nil {
+		return errors.Join(err, s.Close())
+	}
+	if err := s.Close(); err != nil {
+		return err
+	}
+	if !added {
+		return errors.New("the result was not added to an empty results file")
+	}
+
+	s, err = openStore(path, 1, 0, time.Now())
+	if err != nil {
+		return fmt.Errorf("reopening: %w", err)
+	}
+	defer func() { _ = s.Close() }()
+	results, err := s.readAll()
+	if err != nil {
+		return err
+	}
+	if len(results) != 1 || results[0].Address != want.Address || results[0].Seed != want.Seed || !results[0].FoundAt.Equal(want.FoundAt) {
+		return fmt.Errorf("read back %d results, expected %s", len(results), want.Address)
+	}
+	if s.Matches(want.Pattern) != 1 {
+		return fmt.Errorf("the stats count %d matches of %s, expected 1", s.Matches(want.Pattern), want.Pattern)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return checkChecksum(path, data)
+}
+
+// checkKeystore seals a known seed into a keystore the way export keystore does, which must open with its password
+// only
+func checkKeystore() error {
+	const seed, address = "SDJHRQF4GCMIIKAAAQ6IHY42X73FQFLHUULAPSKKD4DFDM7UXWWCRHBE", "GCZHXL5HXQX5ABDM26LHYRCQZ5OJFHLOPLZX47WEBP3V2PF5AVFK2A5D"
+	k, err := sealKeystore(seed, "selftest password")
+	if err != nil {
+		return err
+	}
+	if k.Address != address {
+		return fmt.Errorf("the keystore of %s is addressed to %s", address, k.Address)
+	}
+	if opened, err := k.Open("selftest password"); err != nil || opened != seed {
+		return fmt.Errorf("the keystore of %s doesn't open with its password: %v", address, err)
+	}
+	if _, err := k.Open("wrong password"); err == nil {
+		return fmt.Errorf("the keystore of %s opens with a wrong password", address)
+	}
+	return nil
+}
@@ -0,0 +1,340 @@
+package main
+
+import (
+	"context"       // used for shutting the server down
+	"crypto/subtle" // used for comparing the -listen-token in constant time
+	_ "embed"       // the dashboard of GET / is compiled in
+	"encoding/json" // the endpoints answer with JSON
+	"errors"        // used for telling a closed server apart from a failed one
+	"fmt"           // used for reporting a refused request
+	"mime"          // used for checking the Content-Type of the bodies
+	"net"           // the listener is opened up front so a taken port fails the start
+	"net/http"      // serve and -listen answer over HTTP
+	"net/url"       // used for comparing the Origin of a request with its Host
+	"strings"       // used for upper-casing the pattern of a DELETE
+	"sync/atomic"   // used for pausing and resuming the workers
+	"time"          // used for the timeouts of the server
+)
+
+// dashboardPage is the single page dashboard of GET /, drawn from the frames of GET /ws
+//
+//go:embed dashboard.html
+var dashboardPage []byte
+
+// statusServer serves the progress of a running search over HTTP for serve and -listen; the counts belong to the main
+// loop of runFind, so every request asks it for a snapshot through requests instead of reading them itself, and the
+// main loop pushes the frames of GET /ws to feed
+type statusServer struct {
+	server   *http.Server
+	mux      *http.ServeMux          // more endpoints can be added while it serves
+	addr     net.Addr                // where it listens, the port is picked when -listen ends in :0
+	requests chan chan statsSnapshot // received by the main loop of runFind, which answers on the channel sent
+	feed     *liveFeed               // the subscribers of GET /ws
+	patterns chan patternChange      // received by the main loop of runFind once servePatterns was called
+	done     chan struct{}           // closed once the search ended, nobody answers requests anymore
+	token    string                  // the -listen-token the endpoints changing the search require, none when empty
+	loopback bool                    // whether it only listens on the loopback interface
+}
+
+// startStatusServer listens on addr and serves GET /status, the statsSnapshot of the search, GET /metrics, the same for
+// Prometheus, GET /healthz and GET /readyz for the probes of Kubernetes, GET /ws, a WebSocket of its progress and
+// matches, and GET /, a dashboard of both, until Close; errors after the start are only warned about, the
+// search goes on without it; the endpoints that change the search are only served to the holders of token over the
+// network, see guard
+func startStatusServer(addr, token string, colors palette) (*statusServer, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	s := &statusServer{mux: http.NewServeMux(), addr: listener.Addr(), requests: make(chan chan statsSnapshot), feed: newLiveFeed(),
+		patterns: make(chan patternChange), done: make(chan struct{}), token: token}
+	if tcp, ok := listener.Addr().(*net.TCPAddr); ok {
+		s.loopback = tcp.IP.IsLoopback()
+	}
+	if !s.loopback && token == "" {
+		colors.warnf("-listen %s is reachable from the network, the endpoints changing the search refuse every request until -listen-token is set", addr)
+	}
+	s.mux.HandleFunc("GET /status", s.handleStatus)
+	s.mux.HandleFunc("GET /metrics", s.handleMetrics)
+	s.mux.HandleFunc("GET /healthz", handleHealthz)
+	s.mux.HandleFunc("GET /readyz", s.handleReadyz)
+	s.mux.Handle("GET /ws", s.feed)
+	s.mux.HandleFunc("GET /{$}", handleDashboard)
+	s.server = &http.Server{Handler: s.mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			colors.warnf("-listen %s stopped: %v", addr, err)
+		}
+	}()
+	return s, nil
+}
+
+// snapshot asks the main loop for the statsSnapshot of the search, answering 503 itself once the search ended
+func (s *statusServer) snapshot(w http.ResponseWriter, r *http.Request) (statsSnapshot, bool) {
+	reply := make(chan statsSnapshot, 1)
+	select {
+	case s.requests <- reply:
+	case <-s.done:
+		http.Error(w, "the search has ended", http.StatusServiceUnavailable)
+		return statsSnapshot{}, false
+	case <-r.Context().Done():
+		return statsSnapshot{}, false
+	}
+	return <-reply, true // the main loop answers every request it received
+}
+
+// handleStatus answers with the statsSnapshot of the search
+func (s *statusServer) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if snapshot, ok := s.snapshot(w, r); ok {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(snapshot)
+	}
+}
+
+// handleMetrics answers with the statsSnapshot of the search in the Prometheus text format
+func (s *statusServer) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if snapshot, ok := s.snapshot(w, r); ok {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		writeMetrics(w, snapshot)
+	}
+}
+
+// handleHealthz answers 200 for as long as the process serves, the liveness probe of Kubernetes
+func handleHealthz(w http.ResponseWriter, _ *http.Request) {
+	_, _ = w.Write([]byte("ok\n"))
+}
+
+// handleReadyz answers 200 while the workers search and 503 once the search is ending, the readiness probe of
+// Kubernetes
+func (s *statusServer) handleReadyz(w http.ResponseWriter, _ *http.Request) {
+	select {
+	case <-s.done:
+		http.Error(w, "the search has ended", http.StatusServiceUnavailable)
+	default:
+		_, _ = w.Write([]byte("ok\n"))
+	}
+}
+
+// guard wraps the handler of an endpoint that changes the search, which must refuse requests another site forges in the
+// browser of whoever runs the search (CSRF), and those of a site whose name was made to resolve to 127.0.0.1 (DNS
+// rebinding); over the network only the holders of the -listen-token may change anything
+func (s *statusServer) guard(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if status, err := s.authorize(r); err != nil {
+			http.Error(w, err.Error(), status)
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// authorize returns the status and error r is refused with by guard, nil when it may change the search
+func (s *statusServer) authorize(r *http.Request) (int, error) {
+	switch {
+	case s.token == "" && !s.loopback:
+		return http.StatusForbidden, errors.New("-listen is reachable from the network, set -listen-token to change the search over it")
+	case s.token != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+s.token)) != 1:
+		return http.StatusUnauthorized, errors.New("expected the -listen-token as Authorization: Bearer <token>")
+	case s.loopback && !loopbackHost(r.Host):
+		return http.StatusForbidden, fmt.Errorf("-listen only answers requests for localhost, not %s", r.Host)
+	case !sameOrigin(r):
+		return http.StatusForbidden, errors.New("requests from other sites can't change the search")
+	}
+	return 0, nil
+}
+
+// loopbackHost reports whether host, the Host of a request with or without its port, names the loopback interface
+func loopbackHost(host string) bool {
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		host = name
+	}
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(strings.Trim(host, "[]"))
+	return ip != nil && ip.IsLoopback()
+}
+
+// sameOrigin reports whether r comes from a page of the server itself, or from something that isn't a browser, like
+// curl, which sends neither Sec-Fetch-Site nor Origin
+func sameOrigin(r *http.Request) bool {
+	if site := r.Header.Get("Sec-Fetch-Site"); site != "" && site != "same-origin" && site != "none" {
+		return false
+	}
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+// decodeJSON decodes the body of r, at most limit bytes, into v; only a JSON Content-Type is taken, which a form of
+// another site can't send without a CORS preflight the server never allows
+func decodeJSON(w http.ResponseWriter, r *http.Request, limit int64, v any) error {
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+		return errors.New("expected Content-Type: application/json")
+	}
+	return json.NewDecoder(http.MaxBytesReader(w, r.Body, limit)).Decode(v)
+}
+
+// patternChange is a request of the /patterns endpoints for the main loop of runFind: the patterns to search for on
+// top of the current ones and those to stop searching for, neither for a GET
+type patternChange struct {
+	add    []string           // upper-cased patterns of a POST
+	remove []string           // the upper-cased pattern of a DELETE
+	by     string             // who asked for the change, for the log
+	reply  chan patternResult // answered once the workers search the new patterns, or with why they don't
+}
+
+// patternResult answers a patternChange with the patterns searched for afterwards, or the status and error of a
+// change that wasn't made
+type patternResult struct {
+	Patterns []string `json:"patterns"`        // what the workers search for now
+	Error    string   `json:"error,omitempty"` // why the change wasn't made
+	status   int      // the HTTP status of the answer
+}
+
+// patternRequest is the body of POST /patterns
+type patternRequest struct {
+	Patterns []string `json:"patterns"` // added to those searched for, like the comma separated ones of -find
+}
+
+// servePatterns adds GET /patterns, POST /patterns and DELETE /patterns/{pattern}, changing the patterns the workers
+// search for without restarting them, and returns the changes for the main loop of runFind to make
+func (s *statusServer) servePatterns() <-chan patternChange {
+	s.mux.HandleFunc("GET /patterns", func(w http.ResponseWriter, r *http.Request) {
+		s.changePatterns(w, r, patternChange{})
+	})
+	s.mux.HandleFunc("POST /patterns", s.guard(func(w http.ResponseWriter, r *http.Request) {
+		var body patternRequest
+		if err := decodeJSON(w, r, 1<<16, &body); err != nil {
+			http.Error(w, "expected {\"patterns\":[...]}: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		change := patternChange{add: parsePatterns(strings.Join(body.Patterns, ",")), by: "-listen"}
+		if len(change.add) == 1 && change.add[0] == "" {
+			http.Error(w, "no patterns to add", http.StatusBadRequest)
+			return
+		}
+		s.changePatterns(w, r, change)
+	}))
+	s.mux.HandleFunc("DELETE /patterns/{pattern}", s.guard(func(w http.ResponseWriter, r *http.Request) {
+		s.changePatterns(w, r, patternChange{remove: []string{strings.ToUpper(r.PathValue("pattern"))}, by: "-listen"})
+	}))
+	return s.patterns
+}
+
+// changePatterns hands change to the main loop and answers with its patternResult
+func (s *statusServer) changePatterns(w http.ResponseWriter, r *http.Request, change patternChange) {
+	change.reply = make(chan patternResult, 1)
+	select {
+	case s.patterns <- change:
+	case <-s.done:
+		http.Error(w, "the search has ended", http.StatusServiceUnavailable)
+		return
+	case <-r.Context().Done():
+		return
+	}
+	result := <-change.reply // the main loop answers every change it received
+	w.Header().Set("Content-Type", "application/json")
+	if result.status != 0 {
+		w.WriteHeader(result.status)
+	}
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+// workerChange is a request of the /workers endpoints for the main loop of runFind: how many workers should run, 0 for
+// a GET
+type workerChange struct {
+	count int               // the workers to run
+	by    string            // what asked for it, for the logs
+	reply chan workerResult // answered once the workers were started or stopped
+}
+
+// workerResult answers a workerChange with how many workers run afterwards, or the status and error of a change that
+// wasn't made
+type workerResult struct {
+	Count  int    `json:"count"`           // the workers running
+	Limit  int    `json:"limit"`           // the most workers that can run, the larger of -cores and the cores of the machine
+	Error  string `json:"error,omitempty"` // why the change wasn't made
+	status int    // the HTTP status of the answer
+}
+
+// workerRequest is the body of PATCH /workers
+type workerRequest struct {
+	Count int `json:"count"` // the workers to run
+}
+
+// serveWorkers adds GET /workers and PATCH /workers, starting or stopping workers without restarting the search, and
+// returns the changes for the main loop of runFind to make
+func (s *statusServer) serveWorkers() <-chan workerChange {
+	changes := make(chan workerChange)
+	change := func(w http.ResponseWriter, r *http.Request, change workerChange) {
+		change.reply = make(chan workerResult, 1)
+		select {
+		case changes <- change:
+		case <-s.done:
+			http.Error(w, "the search has ended", http.StatusServiceUnavailable)
+			return
+		case <-r.Context().Done():
+			return
+		}
+		result := <-change.reply // the main loop answers every change it received
+		w.Header().Set("Content-Type", "application/json")
+		if result.status != 0 {
+			w.WriteHeader(result.status)
+		}
+		_ = json.NewEncoder(w).Encode(result)
+	}
+	s.mux.HandleFunc("GET /workers", func(w http.ResponseWriter, r *http.Request) {
+		change(w, r, workerChange{})
+	})
+	s.mux.HandleFunc("PATCH /workers", s.guard(func(w http.ResponseWriter, r *http.Request) {
+		var body workerRequest
+		if err := decodeJSON(w, r, 1<<10, &body); err != nil || body.Count < 1 {
+			http.Error(w, "expected {\"count\":N} with N at least 1 as application/json", http.StatusBadRequest)
+			return
+		}
+		change(w, r, workerChange{count: body.Count, by: "PATCH /workers"})
+	}))
+	return changes
+}
+
+// pauseState is the answer of POST /pause and POST /resume
+type pauseState struct {
+	Paused bool `json:"paused"` // whether the workers are paused afterwards, for any reason
+}
+
+// servePause adds POST /pause and POST /resume, holding the workers through paused like SIGUSR1 and SIGUSR2 do
+func (s *statusServer) servePause(paused *atomic.Uint32) {
+	answer := func(w http.ResponseWriter) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(pauseState{Paused: paused.Load() != 0})
+	}
+	s.mux.HandleFunc("POST /pause", s.guard(func(w http.ResponseWriter, _ *http.Request) {
+		requestPause(paused, "POST /pause")
+		answer(w)
+	}))
+	s.mux.HandleFunc("POST /resume", s.guard(func(w http.ResponseWriter, _ *http.Request) {
+		requestResume(paused, "POST /resume")
+		answer(w)
+	}))
+}
+
+// handleDashboard answers with dashboardPage
+func handleDashboard(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Security-Policy", "default-src 'self'; script-src 'unsafe-inline'; style-src 'unsafe-inline'")
+	_, _ = w.Write(dashboardPage)
+}
+
+// Close stops answering requests, ends the WebSockets, which shutting down leaves alone, and shuts the server down,
+// giving the requests in flight a moment to finish
+func (s *statusServer) Close() error {
+	close(s.done)
+	s.feed.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return s.server.Shutdown(ctx)
+}
@@ -0,0 +1,8 @@
+package main
+
+// defaultServiceName is the Windows service `service install` registers when -name isn't set
+const defaultServiceName = "xlm-vanity-address-finder"
+
+// serviceStop is closed when the Windows service control manager asks the service to stop, and runFind() then stops
+// like it does on SIGTERM; it is never closed outside of a service
+var serviceStop = make(chan struct{})
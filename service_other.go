@@ -0,0 +1,14 @@
+//go:build !windows
+
+package main
+
+import (
+	"fmt" // used for pointing at -daemon instead
+	"os"  // used for writing to STDERR
+)
+
+// runService explains that Windows services only exist on Windows
+func runService(_ []string) int {
+	_, _ = fmt.Fprintln(os.Stderr, "the service subcommand manages Windows services, use -daemon or a systemd unit elsewhere")
+	return exitError
+}
@@ -0,0 +1,222 @@
+//go:build windows
+
+package main
+
+import (
+	"errors"                                // used for reporting a service that didn't stop
+	"flag"                                  // the service subcommand parses its own arguments
+	"fmt"                                   // used for reporting the state of the service
+	"golang.org/x/sys/windows/svc"          // used for running under the service control manager
+	"golang.org/x/sys/windows/svc/eventlog" // the service logs to the Windows event log
+	"golang.org/x/sys/windows/svc/mgr"      // used for installing, starting and stopping the service
+	"log"                                   // the log package is redirected to the event log
+	"os"                                    // access the filesystem
+	"path/filepath"                         // used for resolving the working directory of the service
+	"strings"                               // used for trimming log lines
+	"time"                                  // used for waiting on the service control manager
+)
+
+// serviceStateTimeout is how long start and stop wait for the service to get there
+const serviceStateTimeout = 30 * time.Second
+
+// runService implements `xlm-vanity-address-finder service install|uninstall|start|stop`; install takes the flags of
+// the search after --, and the service control manager starts the service with `service run`
+func runService(args []string) int {
+	flags := flag.NewFlagSet("service", flag.ExitOnError)
+	name := flags.String("name", defaultServiceName, "Name of the Windows service")
+	dir := flags.String("dir", "", "Working directory of the service, where relative -output paths land (service run)")
+	flags.Usage = func() {
+		_, _ = fmt.Fprintf(flags.Output(), "Usage: %s service [-name name] install -- <flags> | uninstall | start | stop\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args) // ExitOnError handles the error
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	var err error
+	switch command := flags.Arg(0); command {
+	case "install":
+		err = installService(*name, flags.Args()[1:])
+	case "uninstall":
+		err = uninstallService(*name)
+	case "start", "stop":
+		err = controlService(*name, command)
+	case "run":
+		return runAsService(*name, *dir, flags.Args()[1:])
+	default:
+		flags.Usage()
+		return 2
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "service %s: %v\n", flags.Arg(0), err)
+		return exitError
+	}
+	return 0
+}
+
+// installService registers the service to start automatically with findArgs, in the current directory, and the event
+// log source it logs to
+func installService(name string, findArgs []string) error {
+	if len(findArgs) > 0 && findArgs[0] == "--" {
+		findArgs = findArgs[1:]
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = m.Disconnect() }()
+	if s, err := m.OpenService(name); err == nil {
+		_ = s.Close()
+		return fmt.Errorf("%s is already installed", name)
+	}
+
+	runArgs := append([]string{"service", "-name", name, "-dir", dir, "run"}, findArgs...)
+	s, err := m.CreateService(name, executable, mgr.Config{
+		DisplayName: "Stellar vanity address finder",
+		Description: "Searches Stellar addresses for " + strings.Join(findArgs, " "),
+		StartType:   mgr.StartAutomatic,
+	}, runArgs...)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = s.Close() }()
+	if err := eventlog.InstallAsEventCreate(name, eventlog.Error|eventlog.Warning|eventlog.Info); err != nil {
+		_ = s.Delete()
+		return fmt.Errorf("failed to register the event log source: %w", err)
+	}
+	fmt.Printf("installed %s, searching in %s\n", name, dir)
+	return nil
+}
+
+// uninstallService removes the service and its event log source
+func uninstallService(name string) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = m.Disconnect() }()
+	s, err := m.OpenService(name)
+	if err != nil {
+		return fmt.Errorf("%s is not installed: %w", name, err)
+	}
+	defer func() { _ = s.Close() }()
+	if err := s.Delete(); err != nil {
+		return err
+	}
+	_ = eventlog.Remove(name)
+	fmt.Printf("uninstalled %s\n", name)
+	return nil
+}
+
+// controlService starts or stops the service and waits until it is running or stopped
+func controlService(name, command string) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = m.Disconnect() }()
+	s, err := m.OpenService(name)
+	if err != nil {
+		return fmt.Errorf("%s is not installed: %w", name, err)
+	}
+	defer func() { _ = s.Close() }()
+
+	want := svc.Running
+	if command == "start" {
+		err = s.Start()
+	} else {
+		want = svc.Stopped
+		_, err = s.Control(svc.Stop)
+	}
+	if err != nil {
+		return err
+	}
+	for deadline := time.Now().Add(serviceStateTimeout); ; time.Sleep(300 * time.Millisecond) {
+		status, err := s.Query()
+		if err != nil {
+			return err
+		}
+		if status.State == want {
+			fmt.Printf("%s %s\n", name, map[svc.State]string{svc.Running: "started", svc.Stopped: "stopped"}[want])
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return errors.New(name + " didn't " + command + " in time, see the event log")
+		}
+	}
+}
+
+// finderService runs the search under the service control manager
+type finderService struct{}
+
+// Execute runs runFind() until it returns or a stop request closes serviceStop, and reports its exit code
+func (finderService) Execute(_ []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	status <- svc.Status{State: svc.StartPending}
+	code := make(chan int, 1)
+	go func() {
+		command, args := searchCommand(os.Args[1:]) // `service install -- serve ...` runs serve
+		os.Args = append(os.Args[:1], args...)
+		code <- runFind(command)
+	}()
+	status <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+	for {
+		select {
+		case exitCode := <-code:
+			return false, uint32(exitCode)
+		case request := <-requests:
+			switch request.Cmd {
+			case svc.Interrogate:
+				status <- request.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				status <- svc.Status{State: svc.StopPending}
+				close(serviceStop) // runFind() saves its results and returns
+				return false, uint32(<-code)
+			}
+		}
+	}
+}
+
+// eventLogWriter sends every log line to the event log
+type eventLogWriter struct {
+	log *eventlog.Log
+}
+
+// Write logs p as an information event
+func (w eventLogWriter) Write(p []byte) (int, error) {
+	return len(p), w.log.Info(1, strings.TrimSpace(string(p)))
+}
+
+// runAsService is `service run`: it moves to the directory the service was installed from, logs to the event log and
+// runs the search with findArgs until the service control manager stops it
+func runAsService(name, dir string, findArgs []string) int {
+	elog, err := eventlog.Open(name)
+	if err != nil {
+		return exitError
+	}
+	defer func() { _ = elog.Close() }()
+	log.SetFlags(0) // events have their own timestamps
+	log.SetOutput(eventLogWriter{log: elog})
+	if dir != "" {
+		if err := os.Chdir(filepath.Clean(dir)); err != nil {
+			_ = elog.Error(1, fmt.Sprintf("failed to enter %s: %v", dir, err))
+			return exitError
+		}
+	}
+
+	os.Args = append([]string{os.Args[0]}, findArgs...) // runFind() parses os.Args like the command line
+	if err := svc.Run(name, finderService{}); err != nil {
+		_ = elog.Error(1, fmt.Sprintf("failed to run as a service: %v", err))
+		return exitError
+	}
+	return 0
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"crypto/rand"                   // used for the random coefficients of every polynomial
+	"encoding/hex"                  // used for writing shares as text
+	"encoding/json"                 // used for the share files
+	"errors"                        // used for reporting shares that can't be combined
+	"flag"                          // the shamir subcommand parses its own arguments
+	"fmt"                           // used for naming the share files and wrapping errors
+	"github.com/stellar/go/keypair" // used for checking a combined seed against its address
+	"os"                            // access the filesystem
+	"path/filepath"                 // used for placing the share files in -shamir-dir
+)
+
+// Shamir's secret sharing over GF(2^8) with the AES reduction polynomial x^8 + x^4 + x^3 + x + 1: every byte of the
+// secret is the constant term of its own random polynomial of degree threshold-1, and share i holds the value of all
+// of them at x = i, so any threshold shares interpolate the secret back while fewer reveal nothing about it
+
+// gfExp and gfLog are the exponent and logarithm tables of GF(2^8) with generator 3
+var gfExp, gfLog = func() (exp [510]byte, log [256]byte) {
+	x := byte(1)
+	for i := 0; i < 255; i++ {
+		exp[i], exp[i+255] = x, x
+		log[x] = byte(i)
+		x ^= x<<1 ^ (x>>7)*0x1b // x *= 3, reduced by the AES polynomial
+	}
+	return exp, log
+}()
+
+// gfMul multiplies a and b in GF(2^8)
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+int(gfLog[b])]
+}
+
+// gfDiv divides a by b in GF(2^8), b must not be 0
+func gfDiv(a, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+255-int(gfLog[b])]
+}
+
+// shamirShare is the content of one share file
+type shamirShare struct {
+	Address   string `json:"address"`           // the address whose seed was split
+	Pattern   string `json:"pattern,omitempty"` // the -find pattern the address matched
+	Index     int    `json:"index"`             // the x coordinate of this share, 1 through Shares
+	Threshold int    `json:"threshold"`         // how many shares recover the seed
+	Shares    int    `json:"shares"`            // how many shares were written
+	Share     string `json:"share"`             // the hex encoded value of every polynomial at x = Index
+}
+
+// shamirSplit is what a result records about the shares of its seed instead of the seed itself
+type shamirSplit struct {
+	Threshold int      `json:"threshold"` // how many of Files recover the seed
+	Files     []string `json:"files"`     // where the shares were written, move them to separate places
+}
+
+// validateSplit checks that a threshold-of-shares split is possible
+func validateSplit(threshold, shares int) error {
+	if threshold < 2 || threshold > shares || shares > 255 {
+		return fmt.Errorf("invalid %d-of-%d split: need 2 <= threshold <= shares <= 255", threshold, shares)
+	}
+	return nil
+}
+
+// splitSecret splits secret into shares shares of which any threshold recover it
+func splitSecret(secret []byte, threshold, shares int) ([][]byte, error) {
+	if err := validateSplit(threshold, shares); err != nil {
+		return nil, err
+	}
+	coefficients := make([]byte, threshold-1)
+	defer zeroize(coefficients)
+	out := make([][]byte, shares)
+	for i := range out {
+		out[i] = make([]byte, len(secret))
+	}
+	for b, constant := range secret {
+		if _, err := rand.Read(coefficients); err != nil {
+			return nil, err
+		}
+		for i := range out {
+			x := byte(i + 1)
+			y := byte(0)
+			for c := len(coefficients) - 1; c >= 0; c-- { // Horner's method, highest degree first
+				y = gfMul(y, x) ^ coefficients[c]
+			}
+			out[i][b] = gfMul(y, x) ^ constant
+		}
+	}
+	return out, nil
+}
+
+// combineShares interpolates the secret at x = 0 from shares, keyed by their x coordinate
+func combineShares(shares map[byte][]byte) []byte {
+	var secret []byte
+	for xi, yi := range shares {
+		if secret == nil {
+			secret = make([]byte, len(yi))
+		}
+		basis := byte(1) // the Lagrange basis polynomial of xi evaluated at 0
+		for xj := range shares {
+			if xj != xi {
+				basis = gfMul(basis, gfDiv(xj, xj^xi))
+			}
+		}
+		for b := range secret {
+			secret[b] ^= gfMul(yi[b], basis)
+		}
+	}
+	return secret
+}
+
+// writeShares splits the seed of r into -shamir-shares files in dir, named after the address, and returns where they
+// were written
+func writeShares(dir string, r result, threshold, shares int) (*shamirSplit, error) {
+	secret := []byte(r.Seed)
+	defer zeroize(secret)
+	values, err := splitSecret(secret, threshold, shares)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	split := &shamirSplit{Threshold: threshold, Files: make([]string, 0, shares)}
+	for i, value := range values {
+		share := shamirShare{Address: r.Address, Pattern: r.Pattern, Index: i + 1, Threshold: threshold, Shares: shares, Share: hex.EncodeToString(value)}
+		zeroize(value)
+		data, err := json.MarshalIndent(share, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		path := filepath.Join(dir, fmt.Sprintf("%s.share-%d-of-%d.json", r.Address, i+1, shares))
+		if err := writeShare(path, append(data, '\n')); err != nil {
+			return nil, fmt.Errorf("failed to write share %s: %w", path, err)
+		}
+		split.Files = append(split.Files, path)
+	}
+	return split, nil
+}
+
+// writeShare writes a share file and syncs it, the seed is dropped from the result once every share is on disk
+func writeShare(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// runShamir implements `xlm-vanity-address-finder shamir combine <share.json>...`, printing the seed the shares
+// recover after checking it derives their address; it returns the process exit code
+func runShamir(args []string) int {
+	flags := flag.NewFlagSet("shamir", flag.ExitOnError)
+	auditPath := flags.String("audit-log", defaultAuditLog, "Audit log of a -compliance run every exported seed is recorded in, when it exists")
+	flags.Usage = func() {
+		_, _ = fmt.Fprintf(flags.Output(), "Usage: %s shamir combine <share.json>...\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args) // ExitOnError handles the error
+	if flags.NArg() < 2 || flags.Arg(0) != "combine" {
+		flags.Usage()
+		return 2
+	}
+
+	seed, err := combineShareFiles(flags.Args()[1:])
+	if err == nil { // the seed was checked against the address of the shares
+		err = auditExport(*auditPath, "shamir combine", keypair.MustParseFull(seed).Address())
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	fmt.Println(seed)
+	return 0
+}
+
+// combineShareFiles reads share files of one address and recovers its seed
+func combineShareFiles(paths []string) (string, error) {
+	var first shamirShare
+	values := make(map[byte][]byte)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		var share shamirShare
+		if err := json.Unmarshal(data, &share); err != nil {
+			return "", fmt.Errorf("%s: %w", path, err)
+		}
+		value, err := hex.DecodeString(share.Share)
+		if err != nil || share.Index < 1 || share.Index > 255 {
+			return "", fmt.Errorf("%s: not a valid share", path)
+		}
+		if len(values) == 0 {
+			first = share
+		} else if share.Address != first.Address || len(value) != len(values[byte(first.Index)]) {
+			return "", fmt.Errorf("%s: share of %s, not %s", path, share.Address, first.Address)
+		}
+		values[byte(share.Index)] = value
+	}
+	if len(values) < first.Threshold {
+		return "", fmt.Errorf("%d distinct shares given, %s needs %d of %d", len(values), first.Address, first.Threshold, first.Shares)
+	}
+
+	seed := string(combineShares(values))
+	pair, err := keypair.ParseFull(seed)
+	if err != nil || pair.Address() != first.Address {
+		return "", errors.New("the shares do not recover the seed of " + first.Address + ", one of them is corrupt")
+	}
+	return seed, nil
+}
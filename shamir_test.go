@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"         // used for comparing the recovered secrets
+	"encoding/hex"  // used for corrupting a share
+	"encoding/json" // used for rewriting a corrupted share file
+	"os"            // used for reading and rewriting the share files
+	"strings"       // used for checking the errors
+	"testing"       // the test harness
+)
+
+// subsets returns every subset of size of the indexes 0 to n-1
+func subsets(n, size int) [][]int {
+	if size == 0 {
+		return [][]int{{}}
+	}
+	var out [][]int
+	for first := size - 1; first < n; first++ {
+		for _, rest := range subsets(first, size-1) {
+			out = append(out, append(rest, first))
+		}
+	}
+	return out
+}
+
+// TestGF256Inverse expects every non-zero element of GF(2^8) times its inverse to be 1
+func TestGF256Inverse(t *testing.T) {
+	for a := 1; a < 256; a++ {
+		if got := gfMul(byte(a), gfDiv(1, byte(a))); got != 1 {
+			t.Fatalf("%d times its inverse is %d", a, got)
+		}
+	}
+}
+
+// TestShamirRoundTrip splits a seed 3-of-5 and expects every subset of 3 or more shares to recover it
+func TestShamirRoundTrip(t *testing.T) {
+	secret := []byte(keystoreVectorSeed)
+	shares, err := splitSecret(secret, 3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for size := 3; size <= 5; size++ {
+		for _, subset := range subsets(5, size) {
+			values := make(map[byte][]byte)
+			for _, i := range subset {
+				values[byte(i+1)] = shares[i]
+			}
+			if got := combineShares(values); !bytes.Equal(got, secret) {
+				t.Fatalf("shares %v recover %q, want the seed", subset, got)
+			}
+		}
+	}
+}
+
+// writeTestShares splits keystoreVectorSeed 3-of-5 into share files in a test directory
+func writeTestShares(t *testing.T) []string {
+	t.Helper()
+	split, err := writeShares(t.TempDir(), result{Address: keystoreVectorAddress, Seed: keystoreVectorSeed, Pattern: "GCZ"}, 3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(split.Files) != 5 || split.Threshold != 3 {
+		t.Fatalf("wrote %d shares with a threshold of %d, want 5 and 3", len(split.Files), split.Threshold)
+	}
+	return split.Files
+}
+
+// pick returns the paths of subset
+func pick(paths []string, subset []int) []string {
+	picked := make([]string, 0, len(subset))
+	for _, i := range subset {
+		picked = append(picked, paths[i])
+	}
+	return picked
+}
+
+// TestCombineShareFiles expects every 3 of the 5 share files to recover the seed, and every 2 of them to be refused
+func TestCombineShareFiles(t *testing.T) {
+	paths := writeTestShares(t)
+	for _, subset := range subsets(5, 3) {
+		seed, err := combineShareFiles(pick(paths, subset))
+		if err != nil {
+			t.Fatalf("shares %v: %v", subset, err)
+		}
+		if seed != keystoreVectorSeed {
+			t.Fatalf("shares %v recover another seed", subset)
+		}
+	}
+	for _, subset := range subsets(5, 2) {
+		if seed, err := combineShareFiles(pick(paths, subset)); err == nil {
+			t.Fatalf("shares %v, below the threshold, recovered %d characters", subset, len(seed))
+		}
+	}
+	if _, err := combineShareFiles(pick(paths, []int{0, 0, 1})); err == nil {
+		t.Fatal("the same share given twice counted towards the threshold")
+	}
+}
+
+// TestCombineShareFilesCorrupt flips a byte of one share at a time and expects combining it with good shares to report
+// the corruption rather than recover a wrong seed
+func TestCombineShareFilesCorrupt(t *testing.T) {
+	paths := writeTestShares(t)
+	data, err := os.ReadFile(paths[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var share shamirShare
+	if err := json.Unmarshal(data, &share); err != nil {
+		t.Fatal(err)
+	}
+	value, err := hex.DecodeString(share.Share)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for b := range value {
+		corrupt := share
+		flipped := bytes.Clone(value)
+		flipped[b] ^= 0x20
+		corrupt.Share = hex.EncodeToString(flipped)
+		data, err := json.Marshal(corrupt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(paths[0], data, 0600); err != nil {
+			t.Fatal(err)
+		}
+		for _, others := range [][]int{{0, 1, 2}, {0, 2, 3, 4}} {
+			seed, err := combineShareFiles(pick(paths, others))
+			if err == nil {
+				t.Fatalf("byte %d of share 1 flipped, shares %v recovered %q without an error", b, others, seed)
+			}
+			if !strings.Contains(err.Error(), "corrupt") {
+				t.Fatalf("byte %d of share 1 flipped, shares %v: %v, want it reported corrupt", b, others, err)
+			}
+		}
+	}
+}
+
+// TestValidateSplit expects the splits the flags allow and refuses the others
+func TestValidateSplit(t *testing.T) {
+	for _, c := range []struct {
+		threshold, shares int
+		valid             bool
+	}{{2, 2, true}, {3, 5, true}, {255, 255, true}, {1, 3, false}, {4, 3, false}, {2, 256, false}} {
+		if err := validateSplit(c.threshold, c.shares); (err == nil) != c.valid {
+			t.Errorf("validateSplit(%d, %d) = %v, want valid %v", c.threshold, c.shares, err, c.valid)
+		}
+	}
+}
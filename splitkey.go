@@ -0,0 +1,343 @@
+package main
+
+import (
+	"bufio"                                                      // used for reading the transaction to sign from STDIN
+	"crypto/ed25519"                                             // used for checking the signatures of split-key sign before printing them
+	"crypto/rand"                                                // the private part of a partial key
+	"crypto/sha512"                                              // tweaks and nonces are reduced from SHA-512 hashes, like ed25519 does
+	"encoding/hex"                                               // the scalars and tweaks are written as hex
+	"encoding/json"                                              // the key files are JSON
+	"errors"                                                     // used for describing a key file that doesn't add up
+	"filippo.io/edwards25519"                                    // the curve arithmetic combining a partial key with a tweak
+	"flag"                                                       // split-key parses its own arguments
+	"fmt"                                                        // used for printing the keys and wrapping errors
+	"github.com/andreimerlescu/xlm-vanity-address-finder/vanity" // the layout of the combined keys, those of accounts
+	"github.com/stellar/go/keypair"                              // the key pairs whose seeds the tweaks are drawn from
+	"github.com/stellar/go/strkey"                               // used for encoding the public keys as G... addresses
+	"github.com/stellar/go/txnbuild"                             // used for signing transactions with a combined key
+	"github.com/stellar/go/xdr"                                  // the decorated signature added to a transaction
+	"os"                                                         // access the filesystem
+	"strings"                                                    // used for telling a results file from a tweak
+)
+
+// keyTypeSplit is the key type of -split-key, whose keys are the partial key of a customer plus a tweak
+const keyTypeSplit = "split"
+
+// splitKeyNonce separates the nonces of split-key sign from any other use of the scalar
+const splitKeyNonce = "xlm-vanity-address-finder split-key nonce"
+
+// splitKeyFile is a key written by split-key new or split-key combine: a private scalar rather than a seed, since no
+// S... seed derives a key that was combined from two parts
+type splitKeyFile struct {
+	Address string `json:"address"` // the G... public key of Scalar
+	Scalar  string `json:"scalar"`  // the hex private scalar, never share it
+}
+
+// parseSplitKey decodes the G... partial key a -split-key search adds its tweaks to
+func parseSplitKey(partial string) (*edwards25519.Point, error) {
+	raw, err := strkey.Decode(strkey.VersionByteAccountID, partial)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -split-key %q: expected the G... partial key of split-key new", partial)
+	}
+	point, err := new(edwards25519.Point).SetBytes(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -split-key %q: %w", partial, err)
+	}
+	return point, nil
+}
+
+// newSplitKeyEncoder returns the encoder of -split-key partial, which searches for the tweaks whose keys, partial plus
+// the tweak times the base point, match the patterns
+func newSplitKeyEncoder(partial string) (*keyEncoder, error) {
+	point, err := parseSplitKey(partial)
+	if err != nil {
+		return nil, err
+	}
+	return &keyEncoder{Type: keyTypeSplit, Layout: vanity.AccountLayout, SplitKey: partial, version: strkey.VersionByteAccountID, partial: point}, nil
+}
+
+// splitTweak is the tweak a worker draws from the seed of pair, reduced from its SHA-512 like ed25519 reduces nonces
+func splitTweak(pair *keypair.Full) *edwards25519.Scalar {
+	hash := sha512.Sum512(rawSeed(pair))
+	tweak, _ := new(edwards25519.Scalar).SetUniformBytes(hash[:]) // never fails on 64 bytes
+	return tweak
+}
+
+// splitAddress is the G... address of partial plus tweak times the base point
+func splitAddress(partial *edwards25519.Point, tweak *edwards25519.Scalar) string {
+	point := new(edwards25519.Point).ScalarBaseMult(tweak)
+	return strkey.MustEncode(strkey.VersionByteAccountID, point.Add(point, partial).Bytes())
+}
+
+// verifySplit checks that the tweak of r is the one of its seed and that added to its -split-key it gives its key
+func verifySplit(r result) error {
+	partial, err := parseSplitKey(r.SplitKey)
+	if err != nil {
+		return err
+	}
+	if pair, err := keypair.ParseFull(r.Seed); err != nil || hex.EncodeToString(splitTweak(pair).Bytes()) != r.Tweak {
+		return fmt.Errorf("the tweak %q isn't the one of the seed", r.Tweak)
+	}
+	tweak, err := decodeScalar(r.Tweak)
+	if err != nil || splitAddress(partial, tweak) != r.Strkey {
+		return fmt.Errorf("the tweak %q doesn't turn %s into %s", r.Tweak, r.SplitKey, r.Strkey)
+	}
+	return nil
+}
+
+// decodeScalar decodes a scalar written in hex by split-key
+func decodeScalar(value string) (*edwards25519.Scalar, error) {
+	raw, err := hex.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(edwards25519.Scalar).SetCanonicalBytes(raw)
+}
+
+// runSplitKey implements `xlm-vanity-address-finder split-key new|combine|sign`, the part of a split-key search the
+// owner of the key runs: new makes the partial key to hand to the searcher, combine adds the tweak of the result to it
+// and sign signs transactions with the combined key; it returns the process exit code
+func runSplitKey(args []string) int {
+	flags := flag.NewFlagSet("split-key", flag.ExitOnError)
+	keyPath := flags.String("key", "", "Key file of split-key new to combine, or of split-key combine to sign with")
+	outPath := flags.String("out", "", "Key file to write, partial-key.json for new and vanity-key.json for combine")
+	networkName := flags.String("network", "public", "Stellar network the transactions of sign are for: public or testnet")
+	flags.Usage = func() {
+		_, _ = fmt.Fprintf(flags.Output(), `Usage: %[1]s split-key new [-out partial-key.json]
+       %[1]s split-key combine -key partial-key.json [-out vanity-key.json] <results.json|tweak>
+       %[1]s split-key sign -key vanity-key.json [-network public] < transaction.xdr
+`, os.Args[0])
+		flags.PrintDefaults()
+	}
+	if len(args) == 0 {
+		flags.Usage()
+		return 2
+	}
+	_ = flags.Parse(args[1:]) // ExitOnError handles the error
+
+	var err error
+	switch args[0] {
+	case "new":
+		err = splitKeyNew(defaultString(*outPath, "partial-key.json"))
+	case "combine":
+		if *keyPath == "" || flags.NArg() != 1 {
+			flags.Usage()
+			return 2
+		}
+		err = splitKeyCombine(*keyPath, defaultString(*outPath, "vanity-key.json"), flags.Arg(0))
+	case "sign":
+		if *keyPath == "" {
+			flags.Usage()
+			return 2
+		}
+		err = splitKeySign(*keyPath, *networkName)
+	default:
+		flags.Usage()
+		return 2
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// defaultString returns value, or fallback when it is empty
+func defaultString(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// splitKeyNew draws a private scalar, writes it to path and prints its public key, the partial key to hand over
+func splitKeyNew(path string) error {
+	var entropy [64]byte
+	if _, err := rand.Read(entropy[:]); err != nil {
+		return err
+	}
+	scalar, _ := new(edwards25519.Scalar).SetUniformBytes(entropy[:])
+	key := newSplitKeyFile(scalar)
+	if err := writeSplitKey(path, key); err != nil {
+		return err
+	}
+	fmt.Printf("Partial key: %s\n", key.Address)
+	fmt.Printf("Keep %s to yourself and send the partial key to the searcher:\n", path)
+	fmt.Printf("  %s -split-key %s -find PATTERN\n", os.Args[0], key.Address)
+	return nil
+}
+
+// splitKeyCombine adds the tweak found for the partial key in keyPath to its scalar, writes the vanity key to outPath
+// and prints its address; found is a results file holding the match of the partial key, or the hex tweak itself
+func splitKeyCombine(keyPath, outPath, found string) error {
+	partial, err := readSplitKey(keyPath)
+	if err != nil {
+		return err
+	}
+	tweakHex, want := found, ""
+	if strings.HasSuffix(found, ".json") {
+		if tweakHex, want, err = findTweak(found, partial.Address); err != nil {
+			return err
+		}
+	}
+	tweak, err := decodeScalar(tweakHex)
+	if err != nil {
+		return fmt.Errorf("invalid tweak %q: %w", tweakHex, err)
+	}
+	scalar, _ := decodeScalar(partial.Scalar) // checked by readSplitKey
+	key := newSplitKeyFile(scalar.Add(scalar, tweak))
+	if want != "" && key.Address != want {
+		return fmt.Errorf("the tweak turns %s into %s, not %s", partial.Address, key.Address, want)
+	}
+	if err := writeSplitKey(outPath, key); err != nil {
+		return err
+	}
+	fmt.Printf("Vanity address: %s\n", key.Address)
+	fmt.Printf("Its private key is in %s, sign with: %s split-key sign -key %s < transaction.xdr\n", outPath, os.Args[0], outPath)
+	return nil
+}
+
+// findTweak returns the tweak and the key of the first result of the results file at path found for partial
+func findTweak(path, partial string) (string, string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", err
+	}
+	doc, err := decodeDocument(data)
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %w", path, err)
+	}
+	for _, r := range doc.Results {
+		if r.KeyType == keyTypeSplit && r.SplitKey == partial {
+			return r.Tweak, r.Strkey, nil
+		}
+	}
+	return "", "", fmt.Errorf("%s has no match for the partial key %s", path, partial)
+}
+
+// splitKeySign reads a base64 transaction envelope from STDIN, signs it with the key in keyPath for the network and
+// prints the signed envelope
+func splitKeySign(keyPath, networkName string) error {
+	key, err := readSplitKey(keyPath)
+	if err != nil {
+		return err
+	}
+	n, err := lookupNetwork(networkName)
+	if err != nil {
+		return err
+	}
+	envelope, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && envelope == "" {
+		return fmt.Errorf("expected a base64 transaction envelope on STDIN: %w", err)
+	}
+	parsed, err := txnbuild.TransactionFromXDR(strings.TrimSpace(envelope))
+	if err != nil {
+		return fmt.Errorf("invalid transaction: %w", err)
+	}
+	scalar, _ := decodeScalar(key.Scalar) // checked by readSplitKey
+	public := strkey.MustDecode(strkey.VersionByteAccountID, key.Address)
+	sign := func(hash [32]byte) (xdr.DecoratedSignature, error) {
+		signature := splitSignature(scalar, public, hash[:])
+		if !ed25519.Verify(public, hash[:], signature.Signature) {
+			return signature, errors.New("the signature doesn't verify against " + key.Address)
+		}
+		return signature, nil
+	}
+	var signed string
+	if tx, ok := parsed.Transaction(); ok {
+		hash, err := tx.Hash(n.Passphrase)
+		if err != nil {
+			return err
+		}
+		signature, err := sign(hash)
+		if err != nil {
+			return err
+		}
+		if tx, err = tx.AddSignatureDecorated(signature); err != nil {
+			return err
+		}
+		if signed, err = tx.Base64(); err != nil {
+			return err
+		}
+	} else if tx, ok := parsed.FeeBump(); ok {
+		hash, err := tx.Hash(n.Passphrase)
+		if err != nil {
+			return err
+		}
+		signature, err := sign(hash)
+		if err != nil {
+			return err
+		}
+		if tx, err = tx.AddSignatureDecorated(signature); err != nil {
+			return err
+		}
+		if signed, err = tx.Base64(); err != nil {
+			return err
+		}
+	}
+	fmt.Println(signed)
+	return nil
+}
+
+// splitSignature signs message with scalar, whose public key is public, the way ed25519 does but with a nonce derived
+// from the scalar, since there is no seed to take it from; any ed25519 verifier accepts the signature
+func splitSignature(scalar *edwards25519.Scalar, public, message []byte) xdr.DecoratedSignature {
+	prefix := sha512.Sum512(append([]byte(splitKeyNonce), scalar.Bytes()...))
+	nonceHash := sha512.New()
+	nonceHash.Write(prefix[:32])
+	nonceHash.Write(message)
+	nonce, _ := new(edwards25519.Scalar).SetUniformBytes(nonceHash.Sum(nil))
+	r := new(edwards25519.Point).ScalarBaseMult(nonce).Bytes()
+
+	challengeHash := sha512.New()
+	challengeHash.Write(r)
+	challengeHash.Write(public)
+	challengeHash.Write(message)
+	challenge, _ := new(edwards25519.Scalar).SetUniformBytes(challengeHash.Sum(nil))
+	s := new(edwards25519.Scalar).MultiplyAdd(challenge, scalar, nonce)
+
+	signature := append(r, s.Bytes()...)
+	var hint xdr.SignatureHint
+	copy(hint[:], public[len(public)-4:])
+	return xdr.DecoratedSignature{Hint: hint, Signature: signature}
+}
+
+// newSplitKeyFile returns the key file of scalar
+func newSplitKeyFile(scalar *edwards25519.Scalar) splitKeyFile {
+	public := new(edwards25519.Point).ScalarBaseMult(scalar).Bytes()
+	return splitKeyFile{Address: strkey.MustEncode(strkey.VersionByteAccountID, public), Scalar: hex.EncodeToString(scalar.Bytes())}
+}
+
+// readSplitKey reads the key file at path, checking its scalar derives its address
+func readSplitKey(path string) (splitKeyFile, error) {
+	var key splitKeyFile
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return key, err
+	}
+	if err := json.Unmarshal(data, &key); err != nil {
+		return key, fmt.Errorf("%s: %w", path, err)
+	}
+	scalar, err := decodeScalar(key.Scalar)
+	if err != nil || newSplitKeyFile(scalar).Address != key.Address {
+		return key, errors.New(path + ": the scalar doesn't derive the address, the key file is corrupt")
+	}
+	return key, nil
+}
+
+// writeSplitKey writes key to path, readable by its owner only, never over an existing key
+func writeSplitKey(path string, key splitKeyFile) error {
+	data, err := json.MarshalIndent(key, "", "  ")
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
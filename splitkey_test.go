@@ -0,0 +1,171 @@
+package main
+
+import (
+	"crypto/ed25519"                // the reference verifier of the split-key signatures
+	"crypto/sha512"                 // used for expanding an ed25519 seed into its scalar
+	"encoding/hex"                  // the scalars, tweaks and signatures of the known vector
+	"encoding/json"                 // used for writing the results file split-key combine reads
+	"filippo.io/edwards25519"       // the scalars under test
+	"github.com/stellar/go/keypair" // the key pairs the tweaks are drawn from
+	"github.com/stellar/go/strkey"  // used for decoding the public keys
+	"os"                            // used for writing the results file
+	"path/filepath"                 // used for placing the key files in the test directory
+	"testing"                       // the test harness
+)
+
+// splitKeyVector is a partial key, the tweak of keystoreVectorSeed, the key they combine into and its signature of
+// splitKeyVectorMessage; every version must keep deriving the same
+var splitKeyVector = struct {
+	partialScalar, partialAddress, tweak, scalar, address, signature string
+}{
+	partialScalar:  "7a3c6282f02d37a05023b60d5428e6cc5961d4c31221937adae0b574e4d07205",
+	partialAddress: "GD4TAL6LHIUTPT7USUHEYYTSGQHBOGYKMXWWQDMPZJZAQ6VU3IDY3T6Z",
+	tweak:          "99f37a4878f3549e18ed3a3e9f4b8ac9017154809a1089a142a326f112507e00",
+	scalar:         "1330ddca68218c3e6910f14bf37370965bd22844ad311c1c1d84dc65f720f105",
+	address:        "GAGX3JOYXGH644XORJ3RK7VXLG3A7DDJSLXH4WBWLSHCVCFA4MEV763G",
+	signature: "6ace81c75c045e1952140c06635bbf00b7bc73f042d64a3d6d08e2579d51385657fc3f1fc19d7c2efcf6cd78225923b3c361d3ce" +
+		"77f2361524931cd8c1b7820a",
+}
+
+// splitKeyVectorMessage is the message signed in splitKeyVector
+const splitKeyVectorMessage = "xlm-vanity-address-finder split-key vector"
+
+// mustScalar decodes a hex scalar of the test
+func mustScalar(t *testing.T, value string) *edwards25519.Scalar {
+	t.Helper()
+	scalar, err := decodeScalar(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return scalar
+}
+
+// TestSplitKeyKnownVector derives every value of splitKeyVector from its partial scalar and seed
+func TestSplitKeyKnownVector(t *testing.T) {
+	v := splitKeyVector
+	partial := mustScalar(t, v.partialScalar)
+	if got := newSplitKeyFile(partial).Address; got != v.partialAddress {
+		t.Fatalf("the partial scalar derives %s, want %s", got, v.partialAddress)
+	}
+	tweak := splitTweak(keypair.MustParseFull(keystoreVectorSeed))
+	if got := hex.EncodeToString(tweak.Bytes()); got != v.tweak {
+		t.Fatalf("the tweak of the seed is %s, want %s", got, v.tweak)
+	}
+	point, err := parseSplitKey(v.partialAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := splitAddress(point, tweak); got != v.address {
+		t.Fatalf("the search finds %s, want %s", got, v.address)
+	}
+	combined := new(edwards25519.Scalar).Add(partial, tweak)
+	if got := newSplitKeyFile(combined); got.Scalar != v.scalar || got.Address != v.address {
+		t.Fatalf("combining gives %s for %s, want %s for %s", got.Scalar, got.Address, v.scalar, v.address)
+	}
+	signature := splitSignature(combined, strkey.MustDecode(strkey.VersionByteAccountID, v.address), []byte(splitKeyVectorMessage))
+	if got := hex.EncodeToString(signature.Signature); got != v.signature {
+		t.Fatalf("the signature is %s, want %s", got, v.signature)
+	}
+}
+
+// TestSplitKeyMatchesEd25519 expects the scalar of an ed25519 seed to derive the address of the seed, and its split
+// signatures to verify against that address with the stellar/go key pair, so the split keys are plain ed25519 keys
+func TestSplitKeyMatchesEd25519(t *testing.T) {
+	pair := keypair.MustParseFull(keystoreVectorSeed)
+	expanded := sha512.Sum512(rawSeed(pair))
+	scalar, err := new(edwards25519.Scalar).SetBytesWithClamping(expanded[:32])
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := newSplitKeyFile(scalar)
+	if key.Address != pair.Address() {
+		t.Fatalf("the scalar of the seed derives %s, want %s", key.Address, pair.Address())
+	}
+	message := []byte(splitKeyVectorMessage)
+	signature := splitSignature(scalar, strkey.MustDecode(strkey.VersionByteAccountID, key.Address), message)
+	if err := pair.Verify(message, signature.Signature); err != nil {
+		t.Fatalf("the split signature doesn't verify against %s: %v", pair.Address(), err)
+	}
+	if hint := pair.Hint(); hint != [4]byte(signature.Hint) {
+		t.Fatalf("the signature hint is %x, want %x", signature.Hint, hint)
+	}
+}
+
+// TestSplitKeyCombine searches a few random seeds against a partial key, combines the partial key with the tweak of
+// each result through split-key combine, and expects the combined key to be the one the search found and to sign for it
+func TestSplitKeyCombine(t *testing.T) {
+	dir := t.TempDir()
+	partialPath := filepath.Join(dir, "partial-key.json")
+	var entropy [64]byte
+	entropy[0] = 1
+	partial, _ := new(edwards25519.Scalar).SetUniformBytes(entropy[:])
+	partialKey := newSplitKeyFile(partial)
+	if err := writeSplitKey(partialPath, partialKey); err != nil {
+		t.Fatal(err)
+	}
+	encoder, err := newSplitKeyEncoder(partialKey.Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 8; i++ {
+		pair := keypair.MustRandom()
+		r := result{Address: pair.Address(), Seed: pair.Seed()}
+		encoder.Describe(&r, pair)
+		if err := verifySplit(r); err != nil {
+			t.Fatalf("%s: %v", r.Strkey, err)
+		}
+		data, err := json.Marshal(document{Results: []result{r}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		resultsPath, outPath := filepath.Join(dir, "results.json"), filepath.Join(dir, "vanity-key.json")
+		_ = os.Remove(outPath)
+		if err := os.WriteFile(resultsPath, data, 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := splitKeyCombine(partialPath, outPath, resultsPath); err != nil {
+			t.Fatal(err)
+		}
+		key, err := readSplitKey(outPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if key.Address != r.Strkey {
+			t.Fatalf("combining gives %s, the search found %s", key.Address, r.Strkey)
+		}
+
+		public := strkey.MustDecode(strkey.VersionByteAccountID, key.Address)
+		message := []byte(r.Strkey)
+		signature := splitSignature(mustScalar(t, key.Scalar), public, message)
+		if !ed25519.Verify(public, message, signature.Signature) {
+			t.Fatalf("the signature of %s doesn't verify", key.Address)
+		}
+		if ed25519.Verify(public, append(message, '!'), signature.Signature) {
+			t.Fatalf("the signature of %s verifies another message", key.Address)
+		}
+	}
+}
+
+// TestSplitKeyCombineWrongTweak expects split-key combine to refuse a results file whose tweak doesn't give its key
+func TestSplitKeyCombineWrongTweak(t *testing.T) {
+	dir := t.TempDir()
+	partialPath := filepath.Join(dir, "partial-key.json")
+	partialKey := newSplitKeyFile(mustScalar(t, splitKeyVector.partialScalar))
+	if err := writeSplitKey(partialPath, partialKey); err != nil {
+		t.Fatal(err)
+	}
+	r := result{KeyType: keyTypeSplit, SplitKey: partialKey.Address, Tweak: splitKeyVector.partialScalar,
+		Strkey: splitKeyVector.address}
+	data, err := json.Marshal(document{Results: []result{r}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resultsPath := filepath.Join(dir, "results.json")
+	if err := os.WriteFile(resultsPath, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := splitKeyCombine(partialPath, filepath.Join(dir, "vanity-key.json"), resultsPath); err == nil {
+		t.Fatal("split-key combine accepted a tweak that gives another key")
+	}
+}
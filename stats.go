@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"        // stats.json is JSON
+	"golang.org/x/sys/cpu" // used for padding the counters of the workers to a cache line
+	"math"                 // used for smoothing the rates
+	"os"                   // access the filesystem
+	"slices"               // used for the median rate of the workers
+	"sync/atomic"          // the workers count concurrently
+	"time"                 // used for the uptime and the rates
+)
+
+// slowWorker is how much of the median rate a worker must fall below to be reported, like one on an efficiency core
+const slowWorker = 0.5
+
+// rateSmoothing is about how far back the rates reach, so that an -every of a fraction of a second shows the rate of
+// the last few seconds instead of how many keys happened to fall between two ticks
+const rateSmoothing = 5 * time.Second
+
+// smoothRate returns the exponential moving average of rate measured over interval after the average previous, 0
+// before the first; the longer the interval the more rate counts, an interval of 30 seconds is all but rate itself
+func smoothRate(previous, rate float64, interval time.Duration) float64 {
+	if previous == 0 {
+		return rate
+	}
+	return previous + (1-math.Exp(-interval.Seconds()/rateSmoothing.Seconds()))*(rate-previous)
+}
+
+// workerCounter counts the addresses one worker scanned, padded so that no other counter shares its cache line: the
+// counters of the workers are written by a different core each, on every key, and counters next to each other would
+// make those cores take turns owning the line
+type workerCounter struct {
+	atomic.Int64
+	_ cpu.CacheLinePad
+}
+
+// workerCounters counts the addresses every worker scanned, one counter per worker, summed up by whoever reports them
+type workerCounters []workerCounter
+
+// Total returns the addresses scanned by all workers
+func (c workerCounters) Total() int64 {
+	var total int64
+	for i := range c {
+		total += c[i].Load()
+	}
+	return total
+}
+
+// workerStats is the progress of one worker in stats.json
+type workerStats struct {
+	Worker   int     `json:"worker"`   // the index of the -cores go-routine
+	Attempts int64   `json:"attempts"` // addresses it scanned since the start
+	Rate     float64 `json:"rate"`     // addresses per second over the last few seconds
+	Matches  int     `json:"matches"`  // matches it found that were saved since the start
+}
+
+// statsSnapshot is the content of stats.json
+type statsSnapshot struct {
+	UpdatedAt     time.Time      `json:"updated_at"`         // when the snapshot was written
+	StartedAt     time.Time      `json:"started_at"`         // when the search started
+	UptimeSeconds float64        `json:"uptime_seconds"`     // how long the search has been running
+	Hostname      string         `json:"hostname,omitempty"` // the machine searching
+	PID           int            `json:"pid"`                // the process searching
+	Patterns      []string       `json:"patterns"`           // the -find patterns searched for
+	Attempts      int64          `json:"attempts"`           // addresses scanned since the start
+	Rate          float64        `json:"rate"`               // addresses per second over the last few seconds
+	AverageRate   float64        `json:"average_rate"`       // addresses per second since the start
+	Matches       int            `json:"matches"`            // matches saved since the start
+	ByPattern     map[string]int `json:"matches_by_pattern"` // matches saved since the start, per pattern
+	Workers       []workerStats  `json:"workers"`            // the progress of every worker
+}
+
+// statsTracker takes statsSnapshots of a search, the rates of each one measured since the previous one
+type statsTracker struct {
+	started   time.Time // when the search started
+	hostname  string    // the machine searching
+	pid       int       // the process searching
+	last      time.Time // when the previous snapshot was taken
+	lastCount []int64   // the counter of every worker at the previous snapshot
+	lastRate  []float64 // the smoothed rate of every worker at the previous snapshot
+}
+
+// newStatsTracker returns the stats tracker of a search with workers workers starting now
+func newStatsTracker(hostname string, pid, workers int) *statsTracker {
+	now := time.Now()
+	return &statsTracker{started: now, hostname: hostname, pid: pid, last: now, lastCount: make([]int64, workers),
+		lastRate: make([]float64, workers)}
+}
+
+// slowWorkers returns the workers, among the first running ones of workers, scanning under slowWorker of their median
+// rate; none with fewer than two of them running or while they are paused
+func slowWorkers(workers []workerStats, running int) []workerStats {
+	var rates []float64
+	for _, worker := range workers {
+		if worker.Worker < running {
+			rates = append(rates, worker.Rate)
+		}
+	}
+	if len(rates) < 2 {
+		return nil
+	}
+	slices.Sort(rates)
+	median := rates[len(rates)/2]
+	var slow []workerStats
+	for _, worker := range workers {
+		if worker.Worker < running && worker.Rate < slowWorker*median {
+			slow = append(slow, worker)
+		}
+	}
+	return slow
+}
+
+// statsWriter writes a statsSnapshot to -stats every -stats-every seconds, so monitoring can scrape the progress from
+// disk without any network exporter
+type statsWriter struct {
+	*statsTracker
+	path string // the -stats file
+}
+
+// newStatsWriter returns the stats writer of a search with workers workers starting now
+func newStatsWriter(path, hostname string, pid, workers int) *statsWriter {
+	return &statsWriter{statsTracker: newStatsTracker(hostname, pid, workers), path: path}
+}
+
+// Write replaces the -stats file with a snapshot of counters and the matches saved per pattern and per worker, through
+// a temporary file so readers never see half of it
+func (s *statsWriter) Write(counters workerCounters, patterns []string, byPattern map[string]int, byWorker []int) error {
+	data, err := json.MarshalIndent(s.Snapshot(counters, patterns, byPattern, byWorker), "", "  ")
+	if err != nil {
+		return err
+	}
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, s.path)
+}
+
+// Snapshot returns the progress of counters and the matches saved per pattern and per worker; the counters of workers
+// that never scanned anything, the spare ones PATCH /workers may start, are left out of the workers
+func (s *statsTracker) Snapshot(counters workerCounters, patterns []string, byPattern map[string]int, byWorker []int) statsSnapshot {
+	now := time.Now()
+	interval, uptime := now.Sub(s.last), now.Sub(s.started).Seconds()
+	snapshot := statsSnapshot{UpdatedAt: now, StartedAt: s.started, UptimeSeconds: uptime, Hostname: s.hostname, PID: s.pid,
+		Patterns: patterns, ByPattern: byPattern, Workers: make([]workerStats, 0, len(counters))}
+	for i := range counters {
+		attempts := counters[i].Load()
+		if attempts == 0 {
+			continue
+		}
+		worker := workerStats{Worker: i, Attempts: attempts, Matches: byWorker[i]}
+		if interval > 0 {
+			worker.Rate = smoothRate(s.lastRate[i], float64(attempts-s.lastCount[i])/interval.Seconds(), interval)
+		}
+		snapshot.Workers, s.lastCount[i], s.lastRate[i] = append(snapshot.Workers, worker), attempts, worker.Rate
+		snapshot.Attempts += attempts
+		snapshot.Rate += worker.Rate
+	}
+	for _, matches := range byPattern {
+		snapshot.Matches += matches
+	}
+	if uptime > 0 {
+		snapshot.AverageRate = float64(snapshot.Attempts) / uptime
+	}
+	s.last = now
+	return snapshot
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"sync/atomic" // the shared counter the padded ones replaced
+	"testing"     // the test harness
+)
+
+// BenchmarkWorkerCounters counts on one workerCounter per go-routine and on a single shared counter, the contention
+// the padding removes; it only tells them apart with many cores, like -cpu 1,32,64 on a machine with 64 of them
+func BenchmarkWorkerCounters(b *testing.B) {
+	b.Run("padded", func(b *testing.B) {
+		counters := make(workerCounters, 1024)
+		var next atomic.Int64
+		b.RunParallel(func(pb *testing.PB) {
+			counter := &counters[next.Add(1)%int64(len(counters))]
+			for pb.Next() {
+				counter.Add(1)
+			}
+		})
+	})
+	b.Run("shared", func(b *testing.B) {
+		var counter atomic.Int64
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				counter.Add(1)
+			}
+		})
+	})
+}
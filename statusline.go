@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"               // used for writing the line
+	"golang.org/x/term" // used for measuring the width of the terminal
+	"io"                // the line goes to STDOUT, or STDERR with -output -
+	"os"                // used for finding the terminal behind the writer
+	"strings"           // used for padding the line
+)
+
+// defaultWidth is the width of the status line when it doesn't go to a terminal that can be measured
+const defaultWidth = 80
+
+// statusLine writes the status line, and the spinner of -fancy, over the one before it with a carriage return; every
+// line is cut one column short of the width of the terminal so that it never wraps, and only padded over what is left
+// of the one before, so a terminal that shrinks has no padding of a wider line to wrap either. The width is measured
+// once, and again by Resize whenever the terminal is resized.
+type statusLine struct {
+	w     io.Writer // STDOUT, or STDERR with -output -
+	fd    int       // the terminal behind w, -1 when w isn't a file
+	width int       // the columns of the terminal
+	text  []rune    // the line on screen, as it was written, nil once a newline ended it
+}
+
+// newStatusLine returns the status line written to w
+func newStatusLine(w io.Writer) *statusLine {
+	s := &statusLine{w: w, fd: -1}
+	if file, ok := w.(*os.File); ok {
+		s.fd = int(file.Fd())
+	}
+	s.measure()
+	return s
+}
+
+// measure measures the width of the terminal, defaultWidth when there is none
+func (s *statusLine) measure() {
+	s.width = defaultWidth
+	if s.fd < 0 {
+		return
+	}
+	if width, _, err := term.GetSize(s.fd); err == nil && width > 0 {
+		s.width = width
+	}
+}
+
+// Write writes line over the line on screen and ends it with end, a newline keeping it above the next one
+func (s *statusLine) Write(line, end string) error {
+	text := []rune(line) // some locales group numbers with multi-byte spaces, the line is cut and padded in characters
+	if limit := max(s.width-1, 0); len(text) > limit {
+		text = text[:limit]
+	}
+	padding := max(min(len(s.text), s.width-1)-len(text), 0)
+	s.text = text
+	if end != "" {
+		s.text = nil
+	}
+	_, err := fmt.Fprintf(s.w, "\r%s%s%s", string(text), strings.Repeat(" ", padding), end)
+	return err
+}
+
+// Resize measures the width of the terminal again and redraws the line on screen cut to it, right away rather than on
+// the next update
+func (s *statusLine) Resize() error {
+	s.measure()
+	if s.text == nil {
+		return nil
+	}
+	return s.Write(string(s.text), "")
+}
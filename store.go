@@ -0,0 +1,657 @@
+package main
+
+import (
+	"bufio"         // used for replaying the journal line by line
+	"bytes"         // used for telling the legacy array layout from the document layout
+	"encoding/json" // used for encoding the -output file and its journal entries
+	"errors"        // used for detecting a missing -output file on first run
+	"fmt"           // used for wrapping errors with the path involved
+	"io"            // -output - writes the results to STDOUT
+	"log"           // used for reporting the recovery of a damaged -output file
+	"os"            // access the filesystem
+	"strings"       // used for expanding the -output-template
+	"sync"          // used for guarding the in-memory index from concurrent access
+	"time"          // used for the match timestamps and search rate in the stats block
+)
+
+// patternPlaceholder is replaced by the matched pattern in -output-template, e.g. "{pattern}.json" -> "CAT.json"
+const patternPlaceholder = "{pattern}"
+
+// document is the layout of an output file, older versions wrote only the bare results array
+type document struct {
+	Results []result                 `json:"results"`
+	Stats   map[string]*patternStats `json:"stats"`
+}
+
+// patternStats tells how hard a -find pattern has been to find, keyed by pattern in the stats block of a document
+type patternStats struct {
+	Matches    int       `json:"matches"`            // results in the file that matched the pattern
+	Attempts   int64     `json:"attempts,omitempty"` // addresses the latest run scanned before its latest match
+	FirstMatch time.Time `json:"first_match"`        // when the earliest result was found
+	LastMatch  time.Time `json:"last_match"`         // when the latest result was found
+	Rate       float64   `json:"rate,omitempty"`     // addresses per second the latest run scanned up to its latest match
+}
+
+// decodeDocument reads an output file in either the document layout or the legacy bare results array
+func decodeDocument(data []byte) (document, error) {
+	doc := document{Results: make([]result, 0), Stats: make(map[string]*patternStats)}
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return doc, nil
+	}
+	if trimmed[0] == '[' {
+		return doc, json.Unmarshal(trimmed, &doc.Results)
+	}
+	if err := json.Unmarshal(trimmed, &doc); err != nil {
+		return doc, err
+	}
+	if doc.Stats == nil {
+		doc.Stats = make(map[string]*patternStats)
+	}
+	return doc, nil
+}
+
+// store keeps the address of every result of the -output file in memory and persists new results incrementally: each
+// result is appended as a single JSON line to path + ".journal", and every -compact results the journal is folded back
+// into the -output file with one atomic rewrite, so a short -find pattern with thousands of matches no longer thrashes
+// the disk. Seeds are never kept in memory between compactions, they are read back from disk when the file is rewritten.
+type store struct {
+	mu           sync.Mutex
+	path         string                   // the -output <path> holding the compacted JSON array of results
+	lock         *os.File                 // the advisory lock on path + ".lock", held until Close
+	journal      *os.File                 // the append-only journal of results not yet compacted into path
+	index        map[string]struct{}      // every address in path and the journal, consulted before anything is persisted
+	stats        map[string]*patternStats // the stats block, refreshed from the results on every compaction
+	started      time.Time                // when this run started searching, used for the search rate
+	pending      int                      // results appended to the journal since the last compaction
+	compactEvery int                      // compact after this many pending results, 0 compacts on Close only
+	backups      int                      // how many earlier versions of path to keep as path.1 to path.N, 0 for none
+}
+
+// openStore locks path, loads it and replays any journal left behind by a previous run, compacting it right away
+func openStore(path string, compactEvery, backups int, started time.Time) (*store, error) {
+	lock, lockErr := lockOutput(path) // two instances must never interleave writes to the same file
+	if lockErr != nil {
+		return nil, lockErr
+	}
+	s := &store{path: path, lock: lock, compactEvery: compactEvery, backups: backups, started: started, index: make(map[string]struct{})}
+	if err := s.load(); err != nil {
+		_ = lock.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// load fills the in-memory index from path and the journal; the caller must be the only reference to s
+func (s *store) load() error {
+	onDisk, readErr := s.readOutput()
+	if errors.Is(readErr, errCorruptOutput) {
+		onDisk, readErr = s.recoverOutput(readErr)
+	}
+	if readErr != nil {
+		return readErr
+	}
+	s.stats = onDisk.Stats
+	for _, r := range onDisk.Results {
+		if !s.remember(r.Address) {
+			s.pending++ // a duplicate already on disk, compacting will drop it
+		}
+	}
+
+	journal, openErr := os.OpenFile(s.journalPath(), os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+	if openErr != nil {
+		return fmt.Errorf("failed to open journal %s: %w", s.journalPath(), openErr)
+	}
+	s.journal = journal
+
+	buffer := make([]byte, 64*1024)
+	defer zeroize(buffer)
+	scanner := bufio.NewScanner(journal)
+	scanner.Buffer(buffer, bufio.MaxScanTokenSize)
+	for scanner.Scan() {
+		var r result
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			continue // a torn final line from a crash mid-append, the rest of the journal is still good
+		}
+		s.remember(r.Address)
+		s.pending++
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to replay journal %s: %w", s.journalPath(), err)
+	}
+
+	info, statErr := journal.Stat()
+	if statErr != nil {
+		return fmt.Errorf("failed to stat journal %s: %w", s.journalPath(), statErr)
+	}
+
+	debugf("loaded %d results from %s and replayed %d from %s", len(onDisk.Results), s.path, s.pending, s.journalPath())
+	// fold the previous run's deltas (and drop any duplicates) before we start appending our own; a journal holding
+	// only a torn line is emptied too, or the next result would be appended onto the fragment and lost with it
+	if s.pending > 0 || info.Size() > 0 {
+		return s.compact()
+	}
+	return nil
+}
+
+// journalPath is where deltas are appended between compactions
+func (s *store) journalPath() string {
+	return s.path + ".journal"
+}
+
+// readOutput decodes the -output file, an empty document when it doesn't exist yet; a file that doesn't match its
+// checksum or doesn't decode is reported with errCorruptOutput
+func (s *store) readOutput() (document, error) {
+	existing, readErr := os.ReadFile(s.path)
+	defer zeroize(existing)
+	if readErr != nil && !errors.Is(readErr, os.ErrNotExist) {
+		return document{}, fmt.Errorf("failed to read %s: %w", s.path, readErr)
+	}
+	if readErr == nil {
+		if err := checkChecksum(s.path, existing); err != nil {
+			return document{}, fmt.Errorf("%w %s: %w", errCorruptOutput, s.path, err)
+		}
+	}
+	onDisk, decodeErr := decodeDocument(existing)
+	if decodeErr != nil {
+		return document{}, fmt.Errorf("%w %s: failed to decode it: %w", errCorruptOutput, s.path, decodeErr)
+	}
+	return onDisk, nil
+}
+
+// recoverOutput keeps a copy of the damaged -output file next to it and replaces the file with every result that could
+// be salvaged from it, so a torn write or a flipped bit costs the records it touched rather than the whole run;
+// corruption is why the file needs recovering
+func (s *store) recoverOutput(corruption error) (document, error) {
+	damaged, err := os.ReadFile(s.path)
+	defer zeroize(damaged)
+	if err != nil {
+		return document{}, fmt.Errorf("failed to read %s: %w", s.path, err)
+	}
+	backupPath := fmt.Sprintf("%s.corrupt-%s", s.path, time.Now().UTC().Format("20060102T150405Z"))
+	if err := os.WriteFile(backupPath, damaged, 0600); err != nil { // the seeds in it may still be recovered by hand
+		return document{}, fmt.Errorf("failed to keep a copy of %s: %w", s.path, err)
+	}
+
+	results, lost := salvageResults(damaged)
+	s.stats = make(map[string]*patternStats)
+	if doc, err := decodeDocument(damaged); err == nil {
+		s.stats = doc.Stats // only the checksum was off, the attempts and rates are still worth keeping
+	}
+	s.refreshStats(results)
+	if err := s.write(document{Results: results, Stats: s.stats}); err != nil {
+		return document{}, err
+	}
+	log.Printf("Recovered %d results of %s (%d lost), the damaged file is kept as %s: %v", len(results), s.path, lost,
+		backupPath, corruption)
+	return document{Results: results, Stats: s.stats}, nil
+}
+
+// readAll reads every result of the -output file and the journal back from disk, in the order they were found and
+// without duplicate addresses; the caller must hold s.mu and drop the results as soon as it is done with them
+func (s *store) readAll() ([]result, error) {
+	onDisk, readErr := s.readOutput()
+	if readErr != nil {
+		return nil, readErr
+	}
+	journaled, journalErr := os.ReadFile(s.journalPath())
+	defer zeroize(journaled)
+	if journalErr != nil && !errors.Is(journalErr, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read journal %s: %w", s.journalPath(), journalErr)
+	}
+
+	seen := make(map[string]struct{}, len(s.index))
+	results := make([]result, 0, len(s.index))
+	add := func(r result) {
+		if _, exists := seen[r.Address]; !exists {
+			seen[r.Address] = struct{}{}
+			results = append(results, r)
+		}
+	}
+	for _, r := range onDisk.Results {
+		add(r)
+	}
+	for _, line := range bytes.Split(journaled, []byte{'\n'}) {
+		var r result
+		if err := json.Unmarshal(line, &r); err != nil {
+			continue // a blank or torn line, see load
+		}
+		add(r)
+	}
+	return results, nil
+}
+
+// remember adds address to the in-memory index unless it is already known, reporting whether it was added;
+// the caller must hold s.mu (or be the only reference to s)
+func (s *store) remember(address string) bool {
+	if _, exists := s.index[address]; exists {
+		return false
+	}
+	s.index[address] = struct{}{}
+	return true
+}
+
+// Add appends r to the journal and the in-memory index, compacting when -compact results are pending; a result whose
+// address is already stored is skipped and Add reports false
+func (s *store) Add(r result) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.index[r.Address]; exists {
+		debugf("skipped %s, already in %s", r.Address, s.path)
+		return false, nil
+	}
+
+	line, err := json.Marshal(r)
+	if err != nil {
+		return false, err
+	}
+	line = append(line, '\n')
+	defer zeroize(line) // the encoded seed is not needed once it is on disk
+	if _, err := s.journal.Write(line); err != nil {
+		return false, fmt.Errorf("failed to append to journal %s: %w", s.journalPath(), err)
+	}
+	if err := s.journal.Sync(); err != nil { // a found seed is worth an fsync
+		return false, fmt.Errorf("failed to sync journal %s: %w", s.journalPath(), err)
+	}
+	s.remember(r.Address)
+	s.pending++
+	tracef("journaled %s to %s (%d pending)", r.Address, s.journalPath(), s.pending)
+
+	stats := s.statsFor(r.Pattern)
+	stats.Attempts = r.Attempts
+	if elapsed := r.FoundAt.Sub(s.started).Seconds(); elapsed > 0 {
+		stats.Rate = float64(r.Attempts) / elapsed
+	}
+
+	if s.compactEvery > 0 && s.pending >= s.compactEvery {
+		return true, s.compact()
+	}
+	return true, nil
+}
+
+// Fund records f as the funding of the stored result of address, rewriting the -output file right away like a
+// compaction does, since the result may already be in it; it reports false when address isn't stored
+func (s *store) Fund(address string, f *funding) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.index[address]; !exists {
+		return false, nil
+	}
+	if err := s.rewrite(func(results []result) {
+		for i := range results {
+			if results[i].Address == address {
+				results[i].Funding = f
+			}
+		}
+	}); err != nil {
+		return false, err
+	}
+	debugf("recorded the funding of %s in %s", address, s.path)
+	return true, nil
+}
+
+// statsFor returns the stats of pattern, creating them on its first match; the caller must hold s.mu
+func (s *store) statsFor(pattern string) *patternStats {
+	stats, ok := s.stats[pattern]
+	if !ok {
+		stats = &patternStats{}
+		s.stats[pattern] = stats
+	}
+	return stats
+}
+
+// refreshStats recounts the matches and first/last timestamps of every pattern from results; the caller must hold s.mu
+func (s *store) refreshStats(results []result) {
+	for _, stats := range s.stats {
+		stats.Matches, stats.FirstMatch, stats.LastMatch = 0, time.Time{}, time.Time{}
+	}
+	for _, r := range results {
+		stats := s.statsFor(r.Pattern)
+		stats.Matches++
+		if r.FoundAt.IsZero() {
+			continue // results written before the stats block existed have no timestamp
+		}
+		if stats.FirstMatch.IsZero() || r.FoundAt.Before(stats.FirstMatch) {
+			stats.FirstMatch = r.FoundAt
+		}
+		if r.FoundAt.After(stats.LastMatch) {
+			stats.LastMatch = r.FoundAt
+		}
+	}
+}
+
+// Matches returns how many results of pattern the compacted file holds, which is every result right after opening
+func (s *store) Matches(pattern string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if stats, ok := s.stats[pattern]; ok {
+		return stats.Matches
+	}
+	return 0
+}
+
+// Len returns how many results are known, both compacted and pending
+func (s *store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.index)
+}
+
+// compact rewrites the -output file from what is on disk via a temp file and rename, then empties the journal;
+// the caller must hold s.mu (or be the only reference to s)
+func (s *store) compact() error {
+	return s.rewrite(nil)
+}
+
+// rewrite is compact applying edit, when not nil, to the results before they are written; the caller must hold s.mu
+// (or be the only reference to s)
+func (s *store) rewrite(edit func(results []result)) error {
+	results, err := s.readAll()
+	if err != nil {
+		return err
+	}
+	if edit != nil {
+		edit(results)
+	}
+	s.refreshStats(results)
+	if err := s.write(document{Results: results, Stats: s.stats}); err != nil {
+		return err
+	}
+
+	if err := s.journal.Truncate(0); err != nil { // everything in the journal now lives in s.path
+		return fmt.Errorf("failed to truncate journal %s: %w", s.journalPath(), err)
+	}
+	debugf("compacted %d results (%d pending) into %s", len(results), s.pending, s.path)
+	s.pending = 0
+	return nil
+}
+
+// write replaces the -output file with doc via a temp file and rename, and its checksum with the one of the new file;
+// the checksum is replaced first, so a crash in between leaves a mismatch that recovery resolves without losing a result
+func (s *store) write(doc document) error {
+	outputBytes, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	defer zeroize(outputBytes)
+	if err := s.rotateBackups(); err != nil {
+		return err
+	}
+
+	tmpPath := s.path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", tmpPath, err)
+	}
+	if _, err := tmp.Write(outputBytes); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", tmpPath, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to sync %s: %w", tmpPath, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", tmpPath, err)
+	}
+	if err := writeChecksum(s.path, outputBytes); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil { // atomic on the same filesystem, the old file stays intact on failure
+		return fmt.Errorf("failed to replace %s: %w", s.path, err)
+	}
+	return nil
+}
+
+// rotateBackups shifts path.1 to path.2 and so on, dropping the oldest, and keeps the current path as path.1 before
+// it is replaced; a hard link is enough since the file is replaced by a rename, with a copy where links aren't supported
+func (s *store) rotateBackups() error {
+	if s.backups < 1 {
+		return nil
+	}
+	if _, err := os.Stat(s.path); errors.Is(err, os.ErrNotExist) {
+		return nil // nothing written yet
+	}
+	for n := s.backups; n > 1; n-- {
+		older, newer := fmt.Sprintf("%s.%d", s.path, n), fmt.Sprintf("%s.%d", s.path, n-1)
+		if err := os.Rename(newer, older); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to rotate %s: %w", newer, err)
+		}
+	}
+	latest := s.path + ".1"
+	if err := os.Remove(latest); err != nil && !errors.Is(err, os.ErrNotExist) { // only left behind with -backups 1
+		return fmt.Errorf("failed to rotate %s: %w", latest, err)
+	}
+	if err := os.Link(s.path, latest); err == nil {
+		tracef("kept %s as %s", s.path, latest)
+		return nil
+	}
+	data, err := os.ReadFile(s.path)
+	defer zeroize(data)
+	if err != nil {
+		return fmt.Errorf("failed to back up %s: %w", s.path, err)
+	}
+	if err := os.WriteFile(latest, data, 0600); err != nil {
+		return fmt.Errorf("failed to back up %s: %w", s.path, err)
+	}
+	tracef("kept %s as %s", s.path, latest)
+	return nil
+}
+
+// Path returns the file this store persists to
+func (s *store) Path() string {
+	return s.path
+}
+
+// Close compacts any pending results into the -output file, removes the journal and releases the lock
+func (s *store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var compactErr error
+	if s.pending > 0 {
+		compactErr = s.compact()
+	}
+	closeErr := s.journal.Close()
+	if compactErr == nil && closeErr == nil {
+		_ = os.Remove(s.journalPath()) // only remove the journal once its contents are safely compacted
+	}
+	return errors.Join(compactErr, closeErr, s.lock.Close())
+}
+
+// resultStore persists the results of an -output: a file, or an object in a bucket for s3://, gs:// and azblob://
+type resultStore interface {
+	Add(r result) (bool, error)                    // persists r unless its address is already stored
+	Fund(address string, f *funding) (bool, error) // records how a stored result was funded, false if results can't change
+	Matches(pattern string) int                    // results of pattern stored when it was opened
+	Len() int                                      // results stored, pending ones included
+	Path() string                                  // where the results go, for the messages
+	Close() error                                  // persists what is pending and releases the lock
+}
+
+// openResultStore opens the store of path, the URL of an object in a bucket, STDOUT for - or a file
+func openResultStore(path string, compactEvery, backups int, started time.Time, remote remoteOptions) (resultStore, error) {
+	if path == outputStdout {
+		return &streamStore{w: os.Stdout, index: make(map[string]struct{})}, nil
+	}
+	if remoteURL(path) {
+		return openRemoteStore(path, compactEvery, remote) // keeping earlier versions is up to the versioning of the bucket
+	}
+	return openStore(path, compactEvery, backups, started)
+}
+
+// outputStdout is the -output that streams the results to STDOUT, for piping them into another program
+const outputStdout = "-"
+
+// streamStore is the store of -output -: every result is written as one JSON line the moment it is added, nothing is
+// read back, so only the results of this run are kept apart
+type streamStore struct {
+	mu    sync.Mutex
+	w     io.Writer           // STDOUT
+	index map[string]struct{} // every address written
+}
+
+// Add writes r as a JSON line unless its address was already written
+func (s *streamStore) Add(r result) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.index[r.Address]; ok {
+		return false, nil
+	}
+	line, err := json.Marshal(r)
+	if err != nil {
+		return false, err
+	}
+	line = append(line, '\n')
+	defer zeroize(line)
+	if _, err := s.w.Write(line); err != nil {
+		return false, fmt.Errorf("-output %s: %w", outputStdout, err)
+	}
+	s.index[r.Address] = struct{}{}
+	return true, nil
+}
+
+// Fund reports false, a line written to STDOUT can't be taken back
+func (s *streamStore) Fund(string, *funding) (bool, error) {
+	return false, nil
+}
+
+// Matches returns 0, nothing was written before the run
+func (s *streamStore) Matches(string) int {
+	return 0
+}
+
+// Len returns the results written
+func (s *streamStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.index)
+}
+
+// Path returns where the results go, for the messages
+func (s *streamStore) Path() string {
+	return "STDOUT"
+}
+
+// Close does nothing, every result was written as it was added
+func (s *streamStore) Close() error {
+	return nil
+}
+
+// outputs routes each result to the combined -output store and/or the -output-template store of its pattern, every
+// store keeping its own dedup index
+type outputs struct {
+	combined     resultStore            // the combined -output file, nil when only per-pattern files are written
+	perPattern   map[string]resultStore // -output-template expanded for each routed pattern, empty without a template
+	template     string                 // the -output-template, empty when every result goes to the combined file
+	compactEvery int                    // passed on to every store
+	backups      int                    // passed on to every file store
+	started      time.Time              // passed on to every store
+	remote       remoteOptions          // passed on to every remote store
+}
+
+// openOutputs opens the combined store at combinedPath (skipped when empty); per-pattern stores are opened by Route
+func openOutputs(combinedPath, template string, compactEvery, backups int, started time.Time, remote remoteOptions) (*outputs, error) {
+	o := &outputs{perPattern: make(map[string]resultStore), template: template, compactEvery: compactEvery, backups: backups,
+		started: started, remote: remote}
+	if combinedPath != "" {
+		combined, err := openResultStore(combinedPath, compactEvery, backups, started, remote)
+		if err != nil {
+			return nil, err
+		}
+		o.combined = combined
+	}
+	return o, nil
+}
+
+// Route opens the -output-template store of every pattern that doesn't have one yet; stores of patterns that are no
+// longer searched stay open, since results for them may still be in flight
+func (o *outputs) Route(patterns []string) error {
+	if o.template == "" {
+		return nil
+	}
+	for _, pattern := range patterns {
+		if _, ok := o.perPattern[pattern]; ok {
+			continue
+		}
+		s, err := openResultStore(strings.ReplaceAll(o.template, patternPlaceholder, pattern), o.compactEvery, o.backups, o.started,
+			o.remote)
+		if err != nil {
+			return err
+		}
+		o.perPattern[pattern] = s
+	}
+	return nil
+}
+
+// targets returns the stores the results of pattern are routed to
+func (o *outputs) targets(pattern string) []resultStore {
+	targets := make([]resultStore, 0, 2)
+	if o.combined != nil {
+		targets = append(targets, o.combined)
+	}
+	if s, ok := o.perPattern[pattern]; ok {
+		targets = append(targets, s)
+	}
+	return targets
+}
+
+// Add persists r to every store it is routed to, returning the stores that did not already have its address
+func (o *outputs) Add(r result) ([]resultStore, error) {
+	targets := o.targets(r.Pattern)
+	saved := make([]resultStore, 0, len(targets))
+	for _, s := range targets {
+		added, err := s.Add(r)
+		if err != nil {
+			return saved, err
+		}
+		if added {
+			saved = append(saved, s)
+		}
+	}
+	return saved, nil
+}
+
+// Fund records f as the funding of the result of address in every store pattern is routed to, returning the stores
+// that recorded it
+func (o *outputs) Fund(address, pattern string, f *funding) ([]resultStore, error) {
+	targets := o.targets(pattern)
+	recorded := make([]resultStore, 0, len(targets))
+	for _, s := range targets {
+		ok, err := s.Fund(address, f)
+		if err != nil {
+			return recorded, err
+		}
+		if ok {
+			recorded = append(recorded, s)
+		}
+	}
+	return recorded, nil
+}
+
+// Matches returns how many results of pattern were saved when the stores were opened, counted in the per-pattern file
+// of pattern when there is one and in the combined file otherwise
+func (o *outputs) Matches(pattern string) int {
+	s, ok := o.perPattern[pattern]
+	if !ok {
+		s = o.combined
+	}
+	if s == nil {
+		return 0
+	}
+	return s.Matches(pattern)
+}
+
+// Close closes every store, compacting pending results and releasing their locks
+func (o *outputs) Close() error {
+	var errs []error
+	if o.combined != nil {
+		errs = append(errs, o.combined.Close())
+	}
+	for _, s := range o.perPattern {
+		errs = append(errs, s.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"os"            // used for reading the -output file back
+	"path/filepath" // used for placing the -output file in the test directory
+	"testing"       // the test harness
+	"time"          // used for the start of the run and the found_at of the results
+)
+
+// TestStoreDeduplicates adds the same address twice in one run and once more after reopening, and expects it stored once
+func TestStoreDeduplicates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.json")
+	r := result{Address: "GABCDEFGHIJKLMNOPQRSTUVWXYZ234567ABCDEFGHIJKLMNOPQRSTUVW", Seed: "SSEED", Pattern: "ABC", FoundAt: time.Now()}
+
+	s, err := openStore(path, 0, 0, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []bool{true, false} {
+		added, err := s.Add(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if added != want {
+			t.Fatalf("Add #%d reported %v, want %v", i+1, added, want)
+		}
+	}
+	if s.Len() != 1 {
+		t.Fatalf("Len is %d after adding a duplicate, want 1", s.Len())
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = openStore(path, 0, 0, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added, err := s.Add(r); err != nil || added {
+		t.Fatalf("Add after reopening reported %v, %v, want false, nil", added, err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := decodeDocument(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Results) != 1 || doc.Results[0].Address != r.Address {
+		t.Fatalf("%s holds %d results, want the one address once", path, len(doc.Results))
+	}
+}
+
+// TestStoreDropsDuplicatesOnDisk expects a duplicate already in the -output file, as the old check wrote them, to be
+// dropped when the file is opened
+func TestStoreDropsDuplicatesOnDisk(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.json")
+	r := `{"address":"GABCDEFGHIJKLMNOPQRSTUVWXYZ234567ABCDEFGHIJKLMNOPQRSTUVW","seed":"SSEED","pattern":"ABC"}`
+	if err := os.WriteFile(path, []byte("["+r+","+r+"]"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := openStore(path, 0, 0, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Len() != 1 {
+		t.Fatalf("Len is %d, want 1", s.Len())
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := decodeDocument(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Results) != 1 {
+		t.Fatalf("%s holds %d results, want 1", path, len(doc.Results))
+	}
+}
+
+// TestStoreTornJournal expects a journal left with only a torn line by a crash mid-append not to swallow the next
+// result appended after it
+func TestStoreTornJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.json")
+	if err := os.WriteFile(path+".journal", []byte(`{"address":"GAB`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	r := result{Address: "GABCDEFGHIJKLMNOPQRSTUVWXYZ234567ABCDEFGHIJKLMNOPQRSTUVW", Seed: "SSEED", Pattern: "ABC", FoundAt: time.Now()}
+
+	s, err := openStore(path, 0, 0, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added, err := s.Add(r); err != nil || !added {
+		t.Fatalf("Add reported %v, %v, want true, nil", added, err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := decodeDocument(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Results) != 1 || doc.Results[0].Address != r.Address {
+		t.Fatalf("%s holds %d results, want the one added after the torn line", path, len(doc.Results))
+	}
+}
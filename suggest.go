@@ -0,0 +1,83 @@
+package main
+
+import (
+	"github.com/andreimerlescu/xlm-vanity-address-finder/vanity" // the odds of every suggested pattern
+	"sort"                                                       // used for ranking the candidate patterns
+	"strings"                                                    // used for building the candidate patterns
+)
+
+// lookalikes maps the digits strkey never encodes to the letters that read like them, best first
+var lookalikes = map[rune][]string{
+	'0': {"O", "D"},
+	'1': {"I", "L", "T"},
+	'8': {"B"},
+	'9': {"G", "Q"},
+}
+
+// dropCost is what leaving a character out costs when ranking suggestions, more than any lookalike since the pattern
+// no longer reads the same
+const dropCost = 4
+
+// maxSuggestions is how many alternatives are offered for an impossible pattern
+const maxSuggestions = 3
+
+// maxCandidates bounds the candidates kept while expanding a pattern with many impossible characters
+const maxCandidates = 64
+
+// candidate is a possible replacement pattern and the cost of the substitutions that produced it
+type candidate struct {
+	pattern string
+	cost    int
+}
+
+// suggestPatterns returns up to maxSuggestions patterns that can appear in an address and are closest to pattern,
+// replacing every character outside base32Alphabet with a lookalike or leaving it out; e.g. 2024 gives 2O24, 2D24
+// and 224. Cheaper substitutions come first and ties go to the easier pattern to find.
+func suggestPatterns(pattern string) []string {
+	candidates := []candidate{{}}
+	for _, r := range strings.ToUpper(pattern) {
+		if strings.ContainsRune(base32Alphabet, r) {
+			for i := range candidates {
+				candidates[i].pattern += string(r)
+			}
+			continue
+		}
+		expanded := make([]candidate, 0, len(candidates)*(len(lookalikes[r])+1))
+		for _, c := range candidates {
+			for cost, letter := range lookalikes[r] {
+				expanded = append(expanded, candidate{pattern: c.pattern + letter, cost: c.cost + cost + 1})
+			}
+			expanded = append(expanded, candidate{pattern: c.pattern, cost: c.cost + dropCost})
+		}
+		sort.SliceStable(expanded, func(i, j int) bool { return expanded[i].cost < expanded[j].cost })
+		candidates = expanded[:min(len(expanded), maxCandidates)]
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].cost != candidates[j].cost {
+			return candidates[i].cost < candidates[j].cost
+		}
+		return vanity.PatternProbability(candidates[i].pattern, false, positionAnywhere) > vanity.PatternProbability(candidates[j].pattern, false, positionAnywhere)
+	})
+	suggestions := make([]string, 0, maxSuggestions)
+	seen := make(map[string]bool)
+	for _, c := range candidates {
+		if len(suggestions) == maxSuggestions {
+			break
+		}
+		if c.pattern == "" || c.pattern == strings.ToUpper(pattern) || seen[c.pattern] || vanity.PatternProbability(c.pattern, false, positionAnywhere) == 0 {
+			continue
+		}
+		seen[c.pattern] = true
+		suggestions = append(suggestions, c.pattern)
+	}
+	return suggestions
+}
+
+// orList joins items as "a", "a or b" and "a, b or c"
+func orList(items []string) string {
+	if len(items) < 2 {
+		return strings.Join(items, "")
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " or " + items[len(items)-1]
+}
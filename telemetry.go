@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"         // used for posting the report
+	"encoding/json" // the report is a JSON document
+	"fmt"           // used for reporting a refused report
+	"net/http"      // the report is posted to the -telemetry URL
+	"runtime"       // used for the OS, architecture, Go version and CPUs of the report
+	"runtime/debug" // used for the version of the binary
+	"time"          // used for the length of the run and the timeout of the post
+)
+
+// telemetryTimeout is how long posting the -telemetry report may hold up the end of a run
+const telemetryTimeout = 10 * time.Second
+
+// telemetryReport is everything -telemetry sends, once when the search ends; it deliberately has no room for the
+// patterns, addresses, seeds, hostname or anything else that tells one run from another
+type telemetryReport struct {
+	Version   string  `json:"version"`    // the module version of the binary, (devel) when built from a checkout
+	GoVersion string  `json:"go_version"` // the Go release it was built with
+	OS        string  `json:"os"`
+	Arch      string  `json:"arch"`
+	CPUs      int     `json:"cpus"`    // the CPUs of the machine
+	Cores     int     `json:"cores"`   // the workers the search started with
+	Rate      float64 `json:"rate"`    // addresses per second, rounded to a thousand
+	Seconds   int64   `json:"seconds"` // how long the search ran, rounded to a minute
+}
+
+// newTelemetryReport returns the report of a search of cores workers that scanned attempts addresses in elapsed; the
+// figures are rounded so they describe the platform rather than the run
+func newTelemetryReport(cores int, attempts int64, elapsed time.Duration) telemetryReport {
+	report := telemetryReport{Version: "(devel)", GoVersion: runtime.Version(), OS: runtime.GOOS, Arch: runtime.GOARCH,
+		CPUs: runtime.NumCPU(), Cores: cores, Seconds: int64(elapsed.Round(time.Minute).Seconds())}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		report.Version = info.Main.Version
+	}
+	if seconds := elapsed.Seconds(); seconds > 0 {
+		report.Rate = float64(int64(float64(attempts)/seconds/1000+0.5) * 1000)
+	}
+	return report
+}
+
+// sendTelemetry posts report as JSON to url
+func sendTelemetry(url string, report telemetryReport) error {
+	body, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	debugf("-telemetry: posting %s to %s", body, redactURL(url))
+	client := &http.Client{Timeout: telemetryTimeout}
+	response, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = response.Body.Close() }()
+	if response.StatusCode/100 != 2 {
+		return fmt.Errorf("%s answered %s", redactURL(url), response.Status)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"                            // used for describing an invalid template and wrapping errors
+	"github.com/stellar/go/strkey"   // used for validating the -template-inflation-dest
+	"github.com/stellar/go/txnbuild" // used for building the follow-up transaction
+	"strings"                        // used for parsing the -template-data entries
+)
+
+// the limits Stellar puts on the follow-up operations, checked before the search starts rather than on every match
+const (
+	maxHomeDomain = 32 // bytes of a home domain
+	maxDataName   = 64 // bytes of the name of a data entry
+	maxDataValue  = 64 // bytes of the value of a data entry
+)
+
+// dataEntry is one name=value of -template-data, a ManageData operation tagging the found account
+type dataEntry struct {
+	Name  string
+	Value string // {pattern} is replaced by the pattern the address matched
+}
+
+// followUp is what the -template-* flags ask the follow-up transaction of every match to do
+type followUp struct {
+	HomeDomain    string      // -template-home-domain, set with SetOptions
+	Data          []dataEntry // -template-data, set with ManageData
+	InflationDest string      // -template-inflation-dest, set with SetOptions
+}
+
+// newFollowUp validates the -template-* flags and returns what they ask for, nil when none is set
+func newFollowUp(homeDomain, data, inflationDest string) (*followUp, error) {
+	if homeDomain == "" && data == "" && inflationDest == "" {
+		return nil, nil
+	}
+	f := &followUp{HomeDomain: homeDomain, InflationDest: inflationDest}
+	if len(homeDomain) > maxHomeDomain {
+		return nil, fmt.Errorf("invalid -template-home-domain %q: expected at most %d characters", homeDomain, maxHomeDomain)
+	}
+	if inflationDest != "" && !strkey.IsValidEd25519PublicKey(inflationDest) {
+		return nil, fmt.Errorf("invalid -template-inflation-dest %q: expected a G... public key", inflationDest)
+	}
+	for _, entry := range strings.Split(data, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok || name == "" || len(name) > maxDataName || len(value) > maxDataValue {
+			return nil, fmt.Errorf("invalid -template-data %q: expected name=value, a name of 1 to %d characters and a value of at most %d",
+				entry, maxDataName, maxDataValue)
+		}
+		f.Data = append(f.Data, dataEntry{Name: name, Value: value})
+	}
+	return f, nil
+}
+
+// operations returns the operations of the follow-up of r: one SetOptions for the home domain and the inflation
+// destination, then one ManageData per data entry; a value that is empty once {pattern} is replaced deletes its entry
+func (f *followUp) operations(r result) []txnbuild.Operation {
+	operations := make([]txnbuild.Operation, 0, 1+len(f.Data))
+	if f.HomeDomain != "" || f.InflationDest != "" {
+		options := &txnbuild.SetOptions{}
+		if f.HomeDomain != "" {
+			options.HomeDomain = &f.HomeDomain
+		}
+		if f.InflationDest != "" {
+			options.InflationDestination = &f.InflationDest
+		}
+		operations = append(operations, options)
+	}
+	for _, entry := range f.Data {
+		data := &txnbuild.ManageData{Name: entry.Name} // without a Value, it deletes the entry
+		if value := strings.ReplaceAll(entry.Value, "{pattern}", r.Pattern); value != "" {
+			data.Value = []byte(value)
+		}
+		operations = append(operations, data)
+	}
+	return operations
+}
+
+// followUpXDR builds the unsigned follow-up transaction of the account of r, on its current sequence number like the
+// multisig setup; the base64 envelope still has to be signed with the found seed before it is submitted
+func followUpXDR(n stellarNetwork, r result, f *followUp) (string, error) {
+	sequence, err := accountSequence(n, r)
+	if err != nil {
+		return "", err
+	}
+	tx, err := txnbuild.NewTransaction(txnbuild.TransactionParams{
+		SourceAccount:        &txnbuild.SimpleAccount{AccountID: r.Address, Sequence: sequence},
+		IncrementSequenceNum: true,
+		BaseFee:              txnbuild.MinBaseFee,
+		Preconditions:        txnbuild.Preconditions{TimeBounds: txnbuild.NewInfiniteTimeout()}, // signed at leisure
+		Operations:           f.operations(r),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to build transaction: %w", err)
+	}
+	return tx.Base64()
+}
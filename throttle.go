@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"     // a paused or sleeping worker still stops with the run
+	"sync/atomic" // the pause reasons are shared by every worker
+	"time"        // used for measuring the work and sleeping it off
+)
+
+// throttleCheck is how many keys a throttled worker generates between looking at the clock
+const throttleCheck = 64
+
+// throttleSlice is how long a throttled worker works before it sleeps, short enough that the fans never notice
+const throttleSlice = 50 * time.Millisecond
+
+// pausePoll is how often a paused worker checks whether it may resume
+const pausePoll = time.Second
+
+// throttle duty-cycles a worker for -throttle: after every throttleSlice of work it sleeps long enough for the work to
+// be about percent of the time, and it holds the worker while any reason of paused is set, -pause-on-battery, SIGUSR1
+// or POST /pause; a nil *throttle never sleeps, so an unthrottled hot loop only pays for a nil check
+type throttle struct {
+	percent int            // the share of the time spent working, 1 through 100
+	paused  *atomic.Uint32 // non-zero while the workers must not work at all, nil when nothing can pause them
+	keys    int            // keys generated since the clock was last read
+	started time.Time      // when the current slice of work started
+}
+
+// newThrottle returns the throttle of a worker, nil when percent is 100 or more and there is no paused flag
+func newThrottle(percent int, paused *atomic.Uint32) *throttle {
+	if percent >= 100 && paused == nil {
+		return nil
+	}
+	return &throttle{percent: min(percent, 100), paused: paused, started: time.Now()}
+}
+
+// Tick counts a generated key, waits while the workers are paused and sleeps once the current slice of work is over,
+// returning early when ctx is done
+func (t *throttle) Tick(ctx context.Context) {
+	if t == nil {
+		return
+	}
+	if t.keys++; t.keys < throttleCheck {
+		return
+	}
+	t.keys = 0
+	if t.paused != nil && t.paused.Load() != 0 {
+		for t.paused.Load() != 0 && sleep(ctx, pausePoll) {
+		}
+		t.started = time.Now()
+		return
+	}
+	if worked := time.Since(t.started); t.percent < 100 && worked >= throttleSlice {
+		sleep(ctx, worked*time.Duration(100-t.percent)/time.Duration(t.percent))
+		t.started = time.Now()
+	}
+}
+
+// sleep waits for d or until ctx is done, and reports whether ctx is still going
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
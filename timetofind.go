@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"     // used for formatting the report
+	"log"     // the report is printed with the end of the run
+	"math"    // used for the exponential distribution the gaps are compared with
+	"slices"  // used for ordering the matches and the gaps
+	"strings" // used for drawing the histogram
+)
+
+// minTimeToFind is how many matches a run needs before the end of the run reports its time-to-find, fewer say little
+// about the generator
+const minTimeToFind = 10
+
+// timeToFindResolution is how many addresses per worker the average gap must span to be compared with a uniform
+// generator: the workers count together, so a match is dated to within the addresses in hand of every other worker
+const timeToFindResolution = 64
+
+// timeToFindBuckets are the bounds of the histogram, in multiples of the expected addresses between two matches
+var timeToFindBuckets = []float64{0, 0.25, 0.5, 1, 2, 4, math.Inf(1)}
+
+// timeToFindBucket is a bar of the histogram of the addresses scanned between two matches
+type timeToFindBucket struct {
+	From     int64   `json:"from"`               // the fewest addresses of the gaps it counts
+	To       int64   `json:"to,omitempty"`       // the most addresses of the gaps it counts, none for the last bucket
+	Count    int     `json:"count"`              // the gaps between these bounds
+	Expected float64 `json:"expected,omitempty"` // the gaps a uniform random generator leaves between these bounds
+}
+
+// timeToFind is how many addresses the matches of a run took to find, the gaps between one match and the next; a
+// uniform random generator leaves gaps that follow an exponential distribution of mean 1/p, which it is compared with
+type timeToFind struct {
+	Matches     int                `json:"matches"`               // the gaps measured, one per match
+	Mean        float64            `json:"mean"`                  // the average gap
+	Expected    float64            `json:"expected,omitempty"`    // the average gap of a uniform random generator, 1/p
+	Percentiles map[string]int64   `json:"percentiles"`           // p10, p50, p90 and p99 of the gaps
+	Buckets     []timeToFindBucket `json:"buckets"`               // the histogram of the gaps
+	Distance    float64            `json:"ks_distance,omitempty"` // the Kolmogorov-Smirnov distance to a uniform generator
+	Critical    float64            `json:"ks_critical,omitempty"` // what a uniform generator stays below 99% of the time
+	Uniform     *bool              `json:"uniform,omitempty"`     // whether Distance stays below Critical
+}
+
+// newTimeToFind returns the time-to-find of matches found by workers after the attempts in matchedAt, compared with
+// the probability p of a match unless it is 0, or too high to tell the gaps apart; nil for fewer than minTimeToFind
+func newTimeToFind(matchedAt []int64, p float64, workers int) *timeToFind {
+	if len(matchedAt) < minTimeToFind {
+		return nil
+	}
+	matchedAt = slices.Clone(matchedAt)
+	slices.Sort(matchedAt) // the workers count together, so matches can arrive out of order
+	gaps := make([]int64, len(matchedAt))
+	var previous, total int64
+	for i, attempts := range matchedAt {
+		attempts += int64(i) + 1 // the counters leave the matches out, the gap ends with the address that matched
+		gaps[i], previous = attempts-previous, attempts
+		total += gaps[i]
+	}
+	slices.Sort(gaps)
+	n := len(gaps)
+	t := &timeToFind{Matches: n, Mean: float64(total) / float64(n), Percentiles: make(map[string]int64)}
+	for _, percentile := range []int{10, 50, 90, 99} {
+		t.Percentiles[fmt.Sprintf("p%d", percentile)] = gaps[min(n-1, int(math.Ceil(float64(percentile)/100*float64(n)))-1)]
+	}
+
+	scale := t.Mean // without a probability the histogram is drawn around the average gap
+	if p > 0 && 1/p >= float64(timeToFindResolution*workers) {
+		t.Expected, scale = 1/p, 1/p
+	}
+	cdf := func(gap float64) float64 { return -math.Expm1(-gap / scale) } // the exponential distribution of mean scale
+	for i := 0; i+1 < len(timeToFindBuckets); i++ {
+		bucket := timeToFindBucket{From: int64(math.Ceil(timeToFindBuckets[i] * scale))}
+		to := math.Inf(1)
+		if !math.IsInf(timeToFindBuckets[i+1], 1) {
+			to = timeToFindBuckets[i+1] * scale
+			bucket.To = int64(math.Ceil(to)) - 1
+		}
+		for _, gap := range gaps {
+			if float64(gap) >= float64(bucket.From) && float64(gap) < to {
+				bucket.Count++
+			}
+		}
+		if t.Expected > 0 {
+			bucket.Expected = float64(n) * (cdf(to) - cdf(float64(bucket.From)))
+		}
+		t.Buckets = append(t.Buckets, bucket)
+	}
+	if t.Expected > 0 {
+		for i, gap := range gaps {
+			f := cdf(float64(gap))
+			t.Distance = math.Max(t.Distance, math.Max(f-float64(i)/float64(n), float64(i+1)/float64(n)-f))
+		}
+		t.Critical = 1.63 / math.Sqrt(float64(n))
+		uniform := t.Distance < t.Critical
+		t.Uniform = &uniform
+	}
+	return t
+}
+
+// Print logs the percentiles, the histogram and how the gaps compare with those of a uniform random generator, warning
+// in colors when they don't
+func (t *timeToFind) Print(colors palette) {
+	expected := ""
+	if t.Expected > 0 {
+		expected = fmt.Sprintf(" (a uniform generator averages %s)", FormatInt64(int64(t.Expected)))
+	}
+	log.Printf("Time to find %s matches: %s addresses on average%s, p10 %s, p50 %s, p90 %s, p99 %s",
+		FormatInt64(int64(t.Matches)), FormatInt64(int64(t.Mean)), expected, FormatInt64(t.Percentiles["p10"]),
+		FormatInt64(t.Percentiles["p50"]), FormatInt64(t.Percentiles["p90"]), FormatInt64(t.Percentiles["p99"]))
+	most := 0
+	for _, bucket := range t.Buckets {
+		most = max(most, bucket.Count)
+	}
+	for _, bucket := range t.Buckets {
+		bounds := FormatInt64(bucket.From) + "+"
+		if bucket.To > 0 {
+			bounds = FormatInt64(bucket.From) + " - " + FormatInt64(bucket.To)
+		}
+		line := fmt.Sprintf("%25s %-40s %s", bounds, strings.Repeat("#", bucket.Count*40/max(most, 1)), FormatInt64(int64(bucket.Count)))
+		if t.Expected > 0 {
+			line += fmt.Sprintf(" (expected %.1f)", bucket.Expected)
+		}
+		log.Print(line)
+	}
+	switch {
+	case t.Uniform == nil:
+		log.Printf("The gaps aren't compared with those of a uniform generator: the patterns changed during the run, their odds are unknown or they match too often")
+	case *t.Uniform:
+		log.Printf("The gaps look like those of a uniform random generator (Kolmogorov-Smirnov distance %.3f, below %.3f)", t.Distance, t.Critical)
+	default:
+		colors.warnf("WARNING: the gaps don't look like those of a uniform random generator (Kolmogorov-Smirnov distance %.3f, above %.3f), run selftest and check the entropy of this machine",
+			t.Distance, t.Critical)
+	}
+}
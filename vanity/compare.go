@@ -0,0 +1,78 @@
+package vanity
+
+// the lengths of the strkeys the vector compare takes, read in registers of 16 or 32 bytes from their start and one
+// ending with their last byte
+const (
+	minCompareLength = 48
+	maxCompareLength = 64
+)
+
+// vectorFind returns the bits of offsets a pattern appears at in address with the SIMD instructions of the CPU, bit o
+// for offset o; pairs holds two characters per character of the pattern, the character and its lookalike or the
+// character again, and address is minCompareLength to maxCompareLength long. Set with vectorEncoder by the init of its
+// architecture, nil on other CPUs and with the purego build tag.
+var vectorFind func(address, pairs string, offsets uint64) uint64
+
+// findGeneric is vectorFind one character at a time, what the vector compare is checked against: a pattern appears at
+// offset o when character o+k of address is one of pair k for every k
+func findGeneric(address, pairs string, offsets uint64) uint64 {
+	for k := 0; 2*k < len(pairs) && offsets != 0; k++ {
+		var mask uint64
+		for i := 0; i < len(address); i++ {
+			if address[i] == pairs[2*k] || address[i] == pairs[2*k+1] {
+				mask |= 1 << i
+			}
+		}
+		offsets &= mask >> k
+	}
+	return offsets
+}
+
+// compare matches the patterns of a Matcher with the vector instructions of the CPU instead of one character at a
+// time: the characters of an address equal to character k of a pattern give a mask of 64 bits, shifted by k, and the
+// masks of all its characters combined leave a bit at each offset the pattern appears at. A pattern is given up on as
+// soon as no offset is left.
+type compare struct {
+	pairs   []string // per pattern, the two characters accepted in place of each of its characters
+	offsets []uint64 // per pattern, the bits of the offsets it may start at with the position
+}
+
+// newCompare returns the compare of the classes of every pattern at position in strkeys of layout, nil when the
+// strkeys are too short or too long for it or a character of a pattern has more than one lookalike
+func newCompare(classes [][]string, position string, layout *Layout) *compare {
+	if layout.Length() < minCompareLength || layout.Length() > maxCompareLength {
+		return nil
+	}
+	c := &compare{}
+	for _, pattern := range classes {
+		pairs := make([]byte, 0, 2*len(pattern))
+		for _, class := range pattern {
+			switch len(class) {
+			case 1:
+				pairs = append(pairs, class[0], class[0])
+			case 2:
+				pairs = append(pairs, class[0], class[1])
+			default:
+				return nil
+			}
+		}
+		var offsets uint64
+		first, last := layout.PatternOffsets(position, len(pattern))
+		for offset := max(first, 0); offset <= last; offset++ {
+			offsets |= 1 << offset
+		}
+		c.pairs, c.offsets = append(c.pairs, string(pairs)), append(c.offsets, offsets)
+	}
+	return c
+}
+
+// first returns the index of the first pattern appearing in address, -1 when none does; address must be a strkey of
+// the layout the compare was made for
+func (c *compare) first(address string) int {
+	for i, pairs := range c.pairs {
+		if vectorFind(address, pairs, c.offsets[i]) != 0 {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,6 @@
+// Package vanity is the search core of xlm-vanity-address-finder: the patterns an address is matched against, the odds
+// of a match and the workers generating key pairs, without the files, flags and services of the command around it. It
+// only needs the standard library, the Stellar keypair package, filippo.io/edwards25519 for the batch keygen and
+// golang.org/x/sys/cpu to pad the counters of the workers and pick the AVX2 or NEON encoder, so it builds for GOOS=js
+// and GOOS=wasip1 too.
+package vanity
@@ -0,0 +1,135 @@
+package vanity
+
+import (
+	"bytes"        // used for checking the vector encoder against the generic one
+	"fmt"          // used for reporting a strkey the vector encoder got wrong
+	"io"           // the random strkeys the vector encoder is checked with
+	"math/rand/v2" // the strkeys the vector encoder is checked with before it is used
+)
+
+// the sizes of the strkeys Keys encodes, a version byte, 32 bytes and a CRC16 checksum in 56 characters
+const (
+	strkeyBytes  = 1 + keyBytes + checksumBytes
+	strkeyLength = (8*strkeyBytes + 4) / 5
+)
+
+// vectorSize is the size of the buffers a strkey is encoded from and to, larger than the strkey since the vector
+// encoders read and write whole registers past its end
+const vectorSize = 64
+
+// crcTable is the CRC16-XModem of every byte, the checksum strkey ends with
+var crcTable = func() (table [256]uint16) {
+	for b := range table {
+		crc := uint16(b) << 8
+		for range 8 {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+		table[b] = crc
+	}
+	return table
+}()
+
+// vectorEncoder encodes a strkey with the SIMD instructions of the CPU, set by the init of its architecture once it
+// encoded a few strkeys like encodeBase32 does; nil on other CPUs and with the purego build tag
+var vectorEncoder func(dst, src *[vectorSize]byte)
+
+// vectorName names the instructions of vectorEncoder and vectorFind, like avx2
+var vectorName string
+
+// vectorErr is why the vector instructions of the CPU are left unused, nil when they are used or there are none
+var vectorErr error
+
+// checksum writes the little-endian CRC16 of the version byte and the 32 bytes starting raw after them
+func checksum(raw *[vectorSize]byte) {
+	var crc uint16
+	for _, b := range raw[:1+keyBytes] {
+		crc = crc<<8 ^ crcTable[byte(crc>>8)^b]
+	}
+	raw[1+keyBytes], raw[2+keyBytes] = byte(crc), byte(crc>>8)
+}
+
+// encodeStrkey writes the base32 of the strkey starting src to dst, with the vector encoder when the CPU has one
+func encodeStrkey(dst, src *[vectorSize]byte) {
+	if vectorEncoder != nil {
+		vectorEncoder(dst, src)
+		return
+	}
+	encodeBase32(dst[:strkeyLength], src[:strkeyBytes])
+}
+
+// encodeBase32 writes src to dst in the base32 of Alphabet without padding, 5 bytes to 8 characters at a time
+func encodeBase32(dst, src []byte) {
+	for len(src) > 0 {
+		var group [5]byte
+		n := copy(group[:], src)
+		bits := uint64(group[0])<<32 | uint64(group[1])<<24 | uint64(group[2])<<16 | uint64(group[3])<<8 | uint64(group[4])
+		characters := (8*n + 4) / 5
+		for i := range characters {
+			dst[i] = Alphabet[bits>>(35-5*i)&31]
+		}
+		src, dst = src[n:], dst[characters:]
+	}
+}
+
+// useVector makes encode and find the vector encoder and compare of the instructions name, unless they encode a
+// strkey differently from encodeBase32 or find a pattern findGeneric doesn't, in which case the generic code is kept
+// and VectorError tells why
+func useVector(name string, encode func(dst, src *[vectorSize]byte),
+	find func(address, pairs string, offsets uint64) uint64) {
+	if vectorErr = checkVector(name, encode, find, rand.NewChaCha8([32]byte{}), 256); vectorErr == nil {
+		vectorEncoder, vectorFind, vectorName = encode, find, name
+	}
+}
+
+// checkVector encodes n strkeys read from random with encode and with encodeBase32, then finds a pattern of a few of
+// their characters in each with find and with findGeneric, and reports the first that differs
+func checkVector(name string, encode func(dst, src *[vectorSize]byte),
+	find func(address, pairs string, offsets uint64) uint64, random io.Reader, n int) error {
+	var src, vector, generic [vectorSize]byte
+	for i := 0; i < n; i++ {
+		if _, err := io.ReadFull(random, src[:]); err != nil { // the padding too, which must not change a thing
+			return err
+		}
+		encode(&vector, &src)
+		encodeBase32(generic[:strkeyLength], src[:strkeyBytes])
+		if !bytes.Equal(vector[:strkeyLength], generic[:strkeyLength]) {
+			return fmt.Errorf("the %s encoder encodes %x as %s, expected %s", name, src[:strkeyBytes], vector[:strkeyLength],
+				generic[:strkeyLength])
+		}
+		at := int(src[0]) % (strkeyLength - 3) // a pattern the strkey has, with lookalikes that are mostly not in it
+		pairs := string([]byte{generic[at], generic[at], generic[at+1], src[1]%64 + '0', src[2]%64 + '0', generic[at+2]})
+		address, offsets := string(generic[:strkeyLength]), uint64(1)<<(strkeyLength-2)-1
+		if got, want := find(address, pairs, offsets), findGeneric(address, pairs, offsets); got != want {
+			return fmt.Errorf("the %s compare finds %q in %s at %064b, expected %064b", name, pairs, address, got, want)
+		}
+	}
+	return nil
+}
+
+// Encoder names the instructions Keys encodes the strkeys and Matcher compares the patterns with: avx2 or neon when
+// the CPU has them, generic otherwise
+func Encoder() string {
+	if vectorEncoder == nil {
+		return "generic"
+	}
+	return vectorName
+}
+
+// VectorError returns why the vector instructions of the CPU are left unused, after they encoded a strkey or found a
+// character differently from the generic code; nil when they are used, or when the CPU has none
+func VectorError() error {
+	return vectorErr
+}
+
+// CheckEncoder encodes n strkeys of bytes read from random and finds a character in each with the vector instructions
+// of the CPU and with the generic code, and reports the first that differs; there is nothing to check without them
+func CheckEncoder(random io.Reader, n int) error {
+	if vectorEncoder == nil {
+		return nil
+	}
+	return checkVector(vectorName, vectorEncoder, vectorFind, random, n)
+}
@@ -0,0 +1,23 @@
+//go:build !purego
+
+package vanity
+
+import (
+	"golang.org/x/sys/cpu" // used for detecting AVX2
+)
+
+// encodeAVX2 is encodeStrkey with AVX2, 16 characters at a time
+//
+//go:noescape
+func encodeAVX2(dst, src *[vectorSize]byte)
+
+// findAVX2 is vectorFind with AVX2, 32 characters at a time
+//
+//go:noescape
+func findAVX2(address, pairs string, offsets uint64) uint64
+
+func init() {
+	if cpu.X86.HasAVX2 {
+		useVector("avx2", encodeAVX2, findAVX2)
+	}
+}
@@ -0,0 +1,115 @@
+//go:build !purego
+
+#include "textflag.h"
+
+// Every character of a strkey is 5 bits of the big-endian 16 bits starting at the byte its first bit is in. Every
+// 128-bit lane encodes a group of 5 bytes into 8 characters: the shuffle puts the 16 bits of each character in a word,
+// the multiply moves its 5 bits to the top of the word and the shift to the bottom, then the 16 words of both lanes are
+// packed into bytes and turned into A to Z, or 2 to 7 past 25. The 7 groups of a strkey take 4 rounds of 2 groups,
+// the last reading and writing the padding of the buffers.
+
+// the bytes of every 16 bits, little-endian: 1 0, 1 0, 2 1, 2 1, 3 2, 4 3, 4 3 and 5 4
+DATA shuffle<>+0x00(SB)/8, $0x0102010200010001
+DATA shuffle<>+0x08(SB)/8, $0x0405030403040203
+DATA shuffle<>+0x10(SB)/8, $0x0102010200010001
+DATA shuffle<>+0x18(SB)/8, $0x0405030403040203
+GLOBL shuffle<>(SB), RODATA|NOPTR, $32
+
+// the multipliers moving the 5 bits of every character to the top of its word: 1, 32, 4, 128, 16, 2, 64 and 8
+DATA multiply<>+0x00(SB)/8, $0x0080000400200001
+DATA multiply<>+0x08(SB)/8, $0x0008004000020010
+DATA multiply<>+0x10(SB)/8, $0x0080000400200001
+DATA multiply<>+0x18(SB)/8, $0x0008004000020010
+GLOBL multiply<>(SB), RODATA|NOPTR, $32
+
+// 25, the value of Z, the last letter
+DATA letters<>+0x00(SB)/8, $0x1919191919191919
+DATA letters<>+0x08(SB)/8, $0x1919191919191919
+GLOBL letters<>(SB), RODATA|NOPTR, $16
+
+// '2' - 26 - 'A', from the letters to the digits
+DATA digits<>+0x00(SB)/8, $0xd7d7d7d7d7d7d7d7
+DATA digits<>+0x08(SB)/8, $0xd7d7d7d7d7d7d7d7
+GLOBL digits<>(SB), RODATA|NOPTR, $16
+
+// 'A'
+DATA first<>+0x00(SB)/8, $0x4141414141414141
+DATA first<>+0x08(SB)/8, $0x4141414141414141
+GLOBL first<>(SB), RODATA|NOPTR, $16
+
+// func encodeAVX2(dst, src *[vectorSize]byte)
+TEXT ·encodeAVX2(SB), NOSPLIT, $0-16
+	MOVQ dst+0(FP), DI
+	MOVQ src+8(FP), SI
+	VMOVDQU shuffle<>(SB), Y4
+	VMOVDQU multiply<>(SB), Y5
+	VMOVDQU letters<>(SB), X6
+	VMOVDQU digits<>(SB), X7
+	VMOVDQU first<>(SB), X8
+	MOVQ $4, CX
+
+loop:
+	VMOVDQU (SI), X0
+	VINSERTI128 $1, 5(SI), Y0, Y0
+	VPSHUFB Y4, Y0, Y0
+	VPMULLW Y5, Y0, Y0
+	VPSRLW $11, Y0, Y0
+	VPACKUSWB Y0, Y0, Y0
+	VPERMQ $0x08, Y0, Y0
+	VPCMPGTB X6, X0, X1
+	VPAND X7, X1, X1
+	VPADDB X8, X0, X0
+	VPADDB X1, X0, X0
+	VMOVDQU X0, (DI)
+	ADDQ $10, SI
+	ADDQ $16, DI
+	DECQ CX
+	JNZ loop
+	VZEROUPPER
+	RET
+
+// The address stays in two registers, its first 32 bytes and the 32 ending with its last byte, while every character
+// of the pattern is compared with them: the bytes equal to either character of its pair give a mask of each register,
+// which are combined into a mask of 64 bits, shifted by the offset of the character in the pattern and combined with
+// the offsets still possible, until none is left or the pattern ends.
+// func findAVX2(address, pairs string, offsets uint64) uint64
+TEXT ·findAVX2(SB), NOSPLIT, $0-48
+	MOVQ address_base+0(FP), SI
+	MOVQ address_len+8(FP), R10
+	MOVQ pairs_base+16(FP), DI
+	MOVQ pairs_len+24(FP), R9
+	SHRQ $1, R9
+	MOVQ offsets+32(FP), DX
+	VMOVDQU (SI), Y1
+	VMOVDQU -32(SI)(R10*1), Y2
+	SUBQ $32, R10
+	XORQ R8, R8
+	TESTQ R9, R9
+	JZ done
+
+loop:
+	VPBROADCASTB (DI)(R8*2), Y0
+	VPBROADCASTB 1(DI)(R8*2), Y3
+	VPCMPEQB Y1, Y0, Y4
+	VPCMPEQB Y1, Y3, Y5
+	VPOR Y5, Y4, Y4
+	VPMOVMSKB Y4, AX
+	VPCMPEQB Y2, Y0, Y4
+	VPCMPEQB Y2, Y3, Y5
+	VPOR Y5, Y4, Y4
+	VPMOVMSKB Y4, BX
+	MOVQ R10, CX
+	SHLQ CX, BX
+	ORQ BX, AX
+	MOVQ R8, CX
+	SHRQ CX, AX
+	ANDQ AX, DX
+	JZ done
+	INCQ R8
+	CMPQ R8, R9
+	JLT loop
+
+done:
+	MOVQ DX, ret+40(FP)
+	VZEROUPPER
+	RET
@@ -0,0 +1,23 @@
+//go:build !purego
+
+package vanity
+
+import (
+	"golang.org/x/sys/cpu" // used for detecting NEON
+)
+
+// encodeNEON is encodeStrkey with NEON, 16 characters at a time
+//
+//go:noescape
+func encodeNEON(dst, src *[vectorSize]byte)
+
+// findNEON is vectorFind with NEON, 16 characters at a time
+//
+//go:noescape
+func findNEON(address, pairs string, offsets uint64) uint64
+
+func init() {
+	if cpu.ARM64.HasASIMD {
+		useVector("neon", encodeNEON, findNEON)
+	}
+}
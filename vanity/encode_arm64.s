@@ -0,0 +1,127 @@
+//go:build !purego
+
+#include "textflag.h"
+
+// Every character of a strkey is 5 bits of the big-endian 16 bits starting at the byte its first bit is in. Every
+// register encodes a group of 5 bytes into 8 characters: the table lookup puts the 16 bits of each character in a
+// halfword, the multiply moves its 5 bits to the top of the halfword and the shift to the bottom, then a lookup packs
+// the halfwords of two groups into bytes and another turns them into the characters of the alphabet. The 7 groups of
+// a strkey take 4 rounds of 2 groups, the last reading and writing the padding of the buffers.
+
+// the bytes of every 16 bits, little-endian: 1 0, 1 0, 2 1, 2 1, 3 2, 4 3, 4 3 and 5 4
+DATA shuffle<>+0x00(SB)/8, $0x0102010200010001
+DATA shuffle<>+0x08(SB)/8, $0x0405030403040203
+// the low byte of every halfword of two registers
+DATA shuffle<>+0x10(SB)/8, $0x0e0c0a0806040200
+DATA shuffle<>+0x18(SB)/8, $0x1e1c1a1816141210
+GLOBL shuffle<>(SB), RODATA|NOPTR, $32
+
+// the multipliers moving the 5 bits of every character to the top of its halfword: 1, 32, 4, 128, 16, 2, 64 and 8
+DATA multiply<>+0x00(SB)/8, $0x0080000400200001
+DATA multiply<>+0x08(SB)/8, $0x0008004000020010
+GLOBL multiply<>(SB), RODATA|NOPTR, $16
+
+DATA alphabet<>+0x00(SB)/8, $"ABCDEFGH"
+DATA alphabet<>+0x08(SB)/8, $"IJKLMNOP"
+DATA alphabet<>+0x10(SB)/8, $"QRSTUVWX"
+DATA alphabet<>+0x18(SB)/8, $"YZ234567"
+GLOBL alphabet<>(SB), RODATA|NOPTR, $32
+
+// func encodeNEON(dst, src *[vectorSize]byte)
+TEXT ·encodeNEON(SB), NOSPLIT, $0-16
+	MOVD dst+0(FP), R0
+	MOVD src+8(FP), R1
+	MOVD $shuffle<>(SB), R2
+	VLD1 (R2), [V4.B16, V5.B16]
+	MOVD $multiply<>(SB), R2
+	VLD1 (R2), [V6.H8]
+	MOVD $alphabet<>(SB), R2
+	VLD1 (R2), [V16.B16, V17.B16]
+	MOVD $4, R3
+
+loop:
+	VLD1 (R1), [V0.B16]
+	ADD $5, R1, R4
+	VLD1 (R4), [V1.B16]
+	VTBL V4.B16, [V0.B16], V0.B16
+	VTBL V4.B16, [V1.B16], V1.B16
+	VMUL V6.H8, V0.H8, V0.H8
+	VMUL V6.H8, V1.H8, V1.H8
+	VUSHR $11, V0.H8, V0.H8
+	VUSHR $11, V1.H8, V1.H8
+	VTBL V5.B16, [V0.B16, V1.B16], V2.B16
+	VTBL V2.B16, [V16.B16, V17.B16], V2.B16
+	VST1 [V2.B16], (R0)
+	ADD $10, R1
+	ADD $16, R0
+	SUB $1, R3
+	CBNZ R3, loop
+	RET
+
+// the bit of every byte of a register in the mask of its 8 bytes
+DATA bits<>+0x00(SB)/8, $0x8040201008040201
+DATA bits<>+0x08(SB)/8, $0x8040201008040201
+GLOBL bits<>(SB), RODATA|NOPTR, $16
+
+// The address stays in four registers, its first 48 bytes and the 16 ending with its last byte, while every character
+// of the pattern is compared with them: the bytes equal to either character of its pair are turned into the bit of
+// each byte and 3 rounds of pairwise adds sum every 8 into a byte, a mask of 64 bits whose last 16 are moved to the
+// offset of the last register. It is shifted by the offset of the character in the pattern and combined with the
+// offsets still possible, until none is left or the pattern ends.
+// func findNEON(address, pairs string, offsets uint64) uint64
+TEXT ·findNEON(SB), NOSPLIT, $0-48
+	MOVD address_base+0(FP), R0
+	MOVD address_len+8(FP), R7
+	MOVD pairs_base+16(FP), R1
+	MOVD pairs_len+24(FP), R2
+	LSR $1, R2, R2
+	MOVD offsets+32(FP), R3
+	VLD1 (R0), [V0.B16, V1.B16, V2.B16]
+	SUB $16, R7, R7
+	ADD R7, R0, R8
+	VLD1 (R8), [V3.B16]
+	MOVD $bits<>(SB), R4
+	VLD1 (R4), [V7.B16]
+	MOVD $0, R5
+	CBZ R2, done
+
+loop:
+	MOVBU.P 1(R1), R6
+	VDUP R6, V4.B16
+	MOVBU.P 1(R1), R6
+	VDUP R6, V5.B16
+	VCMEQ V4.B16, V0.B16, V16.B16
+	VCMEQ V5.B16, V0.B16, V20.B16
+	VORR V20.B16, V16.B16, V16.B16
+	VCMEQ V4.B16, V1.B16, V17.B16
+	VCMEQ V5.B16, V1.B16, V21.B16
+	VORR V21.B16, V17.B16, V17.B16
+	VCMEQ V4.B16, V2.B16, V18.B16
+	VCMEQ V5.B16, V2.B16, V22.B16
+	VORR V22.B16, V18.B16, V18.B16
+	VCMEQ V4.B16, V3.B16, V19.B16
+	VCMEQ V5.B16, V3.B16, V23.B16
+	VORR V23.B16, V19.B16, V19.B16
+	VAND V7.B16, V16.B16, V16.B16
+	VAND V7.B16, V17.B16, V17.B16
+	VAND V7.B16, V18.B16, V18.B16
+	VAND V7.B16, V19.B16, V19.B16
+	VADDP V17.B16, V16.B16, V16.B16
+	VADDP V19.B16, V18.B16, V18.B16
+	VADDP V18.B16, V16.B16, V16.B16
+	VADDP V16.B16, V16.B16, V16.B16
+	VMOV V16.D[0], R6
+	LSR $48, R6, R9
+	LSL R7, R9, R9
+	AND $0xffffffffffff, R6, R6
+	ORR R9, R6, R6
+	LSR R5, R6, R6
+	AND R6, R3, R3
+	CBZ R3, done
+	ADD $1, R5
+	CMP R2, R5
+	BLT loop
+
+done:
+	MOVD R3, ret+40(FP)
+	RET
@@ -0,0 +1,194 @@
+package vanity
+
+import (
+	"bytes"                        // used for comparing the encodings
+	"encoding/hex"                 // the raw keys of the known vectors
+	"github.com/stellar/go/strkey" // the reference encoding of the fuzzed strkeys
+	"math/rand/v2"                 // the addresses the compare is checked with
+	"testing"                      // the test harness
+)
+
+// strkeyVectors are addresses and the raw keys they encode, from SEP-23 and the all-zero account
+var strkeyVectors = []struct{ raw, address string }{
+	{"0000000000000000000000000000000000000000000000000000000000000000", "GAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAWHF"},
+	{"3f0c34bf93ad0d9971d04ccc90f705511c838aad9734a4a2fb0d7a03fc7fe89a", "GA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJVSGZ"},
+}
+
+// encoders returns the strkey encoders of the CPU, the generic one and the vector one when there is one
+func encoders() map[string]func(dst, src *[vectorSize]byte) {
+	encoders := map[string]func(dst, src *[vectorSize]byte){"generic": func(dst, src *[vectorSize]byte) {
+		encodeBase32(dst[:strkeyLength], src[:strkeyBytes])
+	}}
+	if vectorEncoder != nil {
+		encoders[vectorName] = vectorEncoder
+	}
+	return encoders
+}
+
+// TestEncodeKnownVectors encodes the raw keys of strkeyVectors with every encoder and expects their addresses
+func TestEncodeKnownVectors(t *testing.T) {
+	if err := VectorError(); err != nil {
+		t.Errorf("the vector instructions of the CPU were left unused: %v", err)
+	}
+	for name, encode := range encoders() {
+		for _, vector := range strkeyVectors {
+			var raw, dst [vectorSize]byte
+			raw[0] = versionAccountID
+			if _, err := hex.Decode(raw[1:1+keyBytes], []byte(vector.raw)); err != nil {
+				t.Fatal(err)
+			}
+			checksum(&raw)
+			encode(&dst, &raw)
+			if got := string(dst[:strkeyLength]); got != vector.address {
+				t.Errorf("%s encodes %s as %s, want %s", name, vector.raw, got, vector.address)
+			}
+		}
+	}
+}
+
+// FuzzEncoder encodes the strkey of a fuzzed version and key with every encoder, and expects the encoding of the
+// stellar/go strkey package; the bytes past the key fill the padding, which must not change a thing
+func FuzzEncoder(f *testing.F) {
+	for _, vector := range strkeyVectors {
+		raw, _ := hex.DecodeString(vector.raw)
+		f.Add(byte(versionAccountID), raw)
+	}
+	f.Add(byte(versionSeed), bytes.Repeat([]byte{0xff}, vectorSize))
+	f.Fuzz(func(t *testing.T, version byte, key []byte) {
+		if len(key) < keyBytes {
+			t.Skip()
+		}
+		version &^= 7 // strkey versions keep the low 3 bits clear
+		want, err := strkey.Encode(strkey.VersionByte(version), key[:keyBytes])
+		if err != nil {
+			t.Skip() // a version byte strkey doesn't know
+		}
+		for name, encode := range encoders() {
+			var raw, dst [vectorSize]byte
+			raw[0] = version
+			copy(raw[1:], key)
+			checksum(&raw)
+			copy(raw[strkeyBytes:], key[min(len(key), keyBytes):])
+			encode(&dst, &raw)
+			if got := string(dst[:strkeyLength]); got != want {
+				t.Fatalf("%s encodes %x as %s, want %s", name, key[:keyBytes], got, want)
+			}
+		}
+	})
+}
+
+// FuzzFind expects the vector compare to find a pattern of pairs wherever findGeneric does in a fuzzed address, at the
+// fuzzed offsets
+func FuzzFind(f *testing.F) {
+	if vectorFind == nil {
+		f.Skip("no vector compare on this CPU")
+	}
+	for _, vector := range strkeyVectors {
+		f.Add([]byte(vector.address), "AAAA", uint64(1<<(strkeyLength-1)-1))
+		f.Add([]byte(vector.address), "SGZZ", ^uint64(0))
+	}
+	f.Add(bytes.Repeat([]byte{'7'}, vectorSize), "7Z7Z7Z", uint64(1))
+	f.Fuzz(func(t *testing.T, address []byte, pairs string, offsets uint64) {
+		if len(address) < minCompareLength || len(address) > maxCompareLength {
+			t.Skip()
+		}
+		if len(pairs)%2 != 0 {
+			pairs += pairs[len(pairs)-1:]
+		}
+		if got, want := vectorFind(string(address), pairs, offsets), findGeneric(string(address), pairs, offsets); got != want {
+			t.Fatalf("%s finds %q in %q at %064b, want %064b", vectorName, pairs, address, got, want)
+		}
+	})
+}
+
+// compareCases are patterns and options the vector compare must match like the scalar one
+var compareCases = []struct {
+	patterns []string
+	options  Options
+}{
+	{[]string{"AB"}, Options{}},
+	{[]string{"A", "Z7", "QQ"}, Options{}},
+	{[]string{"ZZ", "AB"}, Options{Position: PositionStart}},
+	{[]string{"B", "CD"}, Options{Position: PositionEnd}},
+	{[]string{"SOL", "G0"}, Options{Lowercase: true}},
+	{[]string{"B6", "Z"}, Options{Lowercase: true, Position: PositionEnd}},
+	{[]string{"AB", "C"}, Options{Prefix: "GA"}},
+	{[]string{""}, Options{}},
+}
+
+// TestCompareMatchesScalar matches random addresses against compareCases with the vector compare and without it, and
+// expects the same pattern from both
+func TestCompareMatchesScalar(t *testing.T) {
+	if vectorFind == nil {
+		t.Skip("no vector compare on this CPU")
+	}
+	keys := NewKeys(rand.NewChaCha8([32]byte{}))
+	for _, c := range compareCases {
+		vector, err := NewMatcher(c.patterns, c.options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if vector.compare == nil {
+			t.Fatalf("%v with %+v has no vector compare", c.patterns, c.options)
+		}
+		scalar := *vector
+		scalar.compare = nil
+		matches := 0
+		for i := 0; i < 20000; i++ {
+			if err := keys.Next(); err != nil {
+				t.Fatal(err)
+			}
+			got, gotFound := vector.Match(keys.Address())
+			want, wantFound := scalar.Match(keys.Address())
+			if got != want || gotFound != wantFound {
+				t.Fatalf("%v with %+v: %s matched %q, %v with the vector compare, want %q, %v", c.patterns, c.options,
+					keys.Address(), got, gotFound, want, wantFound)
+			}
+			if gotFound {
+				matches++
+			}
+		}
+		if matches == 0 {
+			t.Errorf("%v with %+v matched none of the addresses, the case checks nothing", c.patterns, c.options)
+		}
+	}
+}
+
+// BenchmarkMatch matches random addresses against a few patterns with the vector compare and without it
+func BenchmarkMatch(b *testing.B) {
+	keys := NewKeys(rand.NewChaCha8([32]byte{}))
+	addresses := make([]string, 1024)
+	for i := range addresses {
+		if err := keys.Next(); err != nil {
+			b.Fatal(err)
+		}
+		addresses[i] = string([]byte(keys.Address()))
+	}
+	for _, c := range []struct {
+		name     string
+		patterns []string
+		options  Options
+	}{
+		{"one", []string{"STELLAR"}, Options{}},
+		{"four", []string{"STELLAR", "LUMENS", "MOON", "XLM2"}, Options{}},
+		{"lowercase", []string{"STELLAR"}, Options{Lowercase: true}},
+	} {
+		vector, err := NewMatcher(c.patterns, c.options)
+		if err != nil {
+			b.Fatal(err)
+		}
+		scalar := *vector
+		scalar.compare = nil
+		for name, m := range map[string]*Matcher{"vector": vector, "scalar": &scalar} {
+			if m.compare == nil && name == "vector" {
+				continue
+			}
+			b.Run(c.name+"/"+name, func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					_, _ = m.Match(addresses[i%len(addresses)])
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package vanity
+
+import (
+	"crypto/ed25519"                // used for deriving the public key of a seed
+	"crypto/rand"                   // the system random source the seeds are read from by default
+	"fmt"                           // used for reporting an address the standard library derives differently
+	"github.com/stellar/go/keypair" // the key pair of a key that matched
+	"io"                            // the random source of the seeds
+	"runtime"                       // used for counting the allocations of AllocsPerKey
+	"time"                          // used for timestamping the matches
+	"unsafe"                        // used for matching the buffers without copying them into strings
+)
+
+// the version bytes of the strkeys Keys encodes
+const (
+	versionAccountID = 6 << 3  // G...
+	versionSeed      = 18 << 3 // S...
+)
+
+// Keys generates key pairs and encodes their addresses and seeds into buffers of its own, so a key that doesn't match
+// allocates nothing; only the key pair of a match is made. A Keys is not safe for concurrent use, every worker holds
+// one of its own.
+type Keys struct {
+	random  io.Reader
+	seed    [keyBytes]byte   // the raw seed of the last key
+	raw     [vectorSize]byte // the version byte, the 32 bytes and the checksum being encoded, then padding
+	address [vectorSize]byte // the G... address of the last key, then padding
+	secret  [vectorSize]byte // the S... seed of the last key once Seed encoded it, then padding
+	encoded bool             // whether secret holds the seed of the last key
+	batch   *batch           // with NewBatchKeys, the seeds and public keys derived at once, nil otherwise
+}
+
+// NewKeys returns the Keys of seeds read from random, the system random source when nil
+func NewKeys(random io.Reader) *Keys {
+	if random == nil {
+		random = rand.Reader
+	}
+	return &Keys{random: random}
+}
+
+// NewBatchKeys returns the Keys of seeds read from random like NewKeys, the same keys in the same order, but derives
+// their public keys BatchSize at a time, which is faster; one key of every batch and every key Pair returns are
+// checked against the standard library
+func NewBatchKeys(random io.Reader) *Keys {
+	k := NewKeys(random)
+	k.batch = &batch{next: BatchSize}
+	return k
+}
+
+// Next generates the next key and encodes its address
+func (k *Keys) Next() error {
+	k.encoded = false
+	if k.batch != nil {
+		return k.nextInBatch()
+	}
+	if _, err := io.ReadFull(k.random, k.seed[:]); err != nil {
+		return err
+	}
+	private := ed25519.NewKeyFromSeed(k.seed[:]) // inlined, so it stays on the stack
+	k.encode(&k.address, versionAccountID, private[ed25519.SeedSize:])
+	return nil
+}
+
+// nextInBatch is Next taking the next key of the batch, which reads and derives the next BatchSize keys once taken
+func (k *Keys) nextInBatch() error {
+	b := k.batch
+	if b.next == BatchSize {
+		if _, err := io.ReadFull(k.random, b.seeds[:]); err != nil {
+			return err
+		}
+		if err := b.derive(); err != nil {
+			return err
+		}
+	}
+	copy(k.seed[:], b.seeds[b.next*keyBytes:])
+	k.encode(&k.address, versionAccountID, b.public[b.next*keyBytes:(b.next+1)*keyBytes])
+	b.next++
+	return nil
+}
+
+// Address returns the address of the last key; it is only valid until the next call to Next, which overwrites it
+func (k *Keys) Address() string {
+	return unsafe.String(&k.address[0], strkeyLength)
+}
+
+// Seed returns the seed of the last key, encoded on the first call; it is only valid until the next call to Next
+func (k *Keys) Seed() string {
+	if !k.encoded {
+		k.encode(&k.secret, versionSeed, k.seed[:])
+		k.encoded = true
+	}
+	return unsafe.String(&k.secret[0], strkeyLength)
+}
+
+// Pair returns the key pair of the last key, which holds strings of its own; its address is derived again by the
+// standard library, which must agree with the one matched
+func (k *Keys) Pair() (*keypair.Full, error) {
+	pair, err := keypair.FromRawSeed(k.seed)
+	if err != nil {
+		return nil, err
+	}
+	if pair.Address() != k.Address() {
+		return nil, fmt.Errorf("the keygen derived %s from the seed of %s", k.Address(), pair.Address())
+	}
+	return pair, nil
+}
+
+// NextPair generates the next key and returns its key pair, like keypair.Random does from the system random source
+func (k *Keys) NextPair() (*keypair.Full, error) {
+	if err := k.Next(); err != nil {
+		return nil, err
+	}
+	return k.Pair()
+}
+
+// encode writes the strkey of version and payload to dst, like strkey.Encode
+func (k *Keys) encode(dst *[vectorSize]byte, version byte, payload []byte) {
+	k.raw[0] = version
+	copy(k.raw[1:], payload)
+	checksum(&k.raw)
+	encodeStrkey(dst, &k.raw)
+}
+
+// Scan is Scan generating the key pairs with k
+func (k *Keys) Scan(m *Matcher, n int) ([]Match, error) {
+	var matches []Match
+	for i := 0; i < n; i++ {
+		if err := k.Next(); err != nil {
+			return matches, err
+		}
+		pattern, found := m.Match(k.Address())
+		if !found {
+			continue
+		}
+		pair, err := k.Pair()
+		if err != nil {
+			return matches, err
+		}
+		matches = append(matches, Match{Address: pair.Address(), Seed: pair.Seed(), Pattern: pattern, FoundAt: time.Now()})
+	}
+	return matches, nil
+}
+
+// AllocsPerKey returns the heap allocations per key of generating n keys with k and matching them against m, the raw
+// malloc count divided by n so a single allocation in n keys still shows; with patterns that hardly ever match it is 0,
+// unless the keygen, the encoding or the matching regressed
+func AllocsPerKey(k *Keys, m *Matcher, n int) (float64, error) {
+	if _, err := k.Scan(m, 1); err != nil { // warm up, the first key may set up the random source
+		return 0, err
+	}
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(1)) // no other go-routine allocating in the meantime
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	for i := 0; i < n; i++ {
+		if err := k.Next(); err != nil {
+			return 0, err
+		}
+		_, _ = m.Match(k.Address())
+	}
+	runtime.ReadMemStats(&after)
+	return float64(after.Mallocs-before.Mallocs) / float64(n), nil
+}
@@ -0,0 +1,102 @@
+package vanity
+
+import (
+	"crypto/ed25519"               // used for deriving the reference keys of the known seeds
+	"github.com/stellar/go/strkey" // the reference encoding of the addresses and seeds
+	"testing"                      // the test harness
+)
+
+// keysPerRun is how many keys a run of the allocation tests generates, so one allocation among them still counts
+const keysPerRun = 10000
+
+// countingSource is a random source of seeds counting up from 1, every key different and known in advance
+type countingSource struct{ n byte }
+
+// Read fills p with the next values of the count
+func (c *countingSource) Read(p []byte) (int, error) {
+	for i := range p {
+		c.n++
+		p[i] = c.n
+	}
+	return len(p), nil
+}
+
+// newKeysMatcher returns the Keys of the test, with and without batching, and a matcher that hardly ever matches
+func newKeysMatcher(t testing.TB) (map[string]*Keys, *Matcher) {
+	m, err := NewMatcher([]string{"SELFTEST"}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]*Keys{"keys": NewKeys(nil), "batch": NewBatchKeys(nil)}, m
+}
+
+// TestKeysMatchStrkey expects the addresses and seeds of Keys to be those the stellar/go strkey package encodes
+func TestKeysMatchStrkey(t *testing.T) {
+	for name, k := range map[string]*Keys{"keys": NewKeys(&countingSource{}), "batch": NewBatchKeys(&countingSource{})} {
+		reference := &countingSource{}
+		for i := 0; i < 3*BatchSize; i++ {
+			if err := k.Next(); err != nil {
+				t.Fatal(err)
+			}
+			var seed [keyBytes]byte
+			_, _ = reference.Read(seed[:])
+			public := ed25519.NewKeyFromSeed(seed[:])[ed25519.SeedSize:]
+			if want := strkey.MustEncode(strkey.VersionByteAccountID, public); k.Address() != want {
+				t.Fatalf("%s: key %d has the address %s, want %s", name, i, k.Address(), want)
+			}
+			if want := strkey.MustEncode(strkey.VersionByteSeed, seed[:]); k.Seed() != want {
+				t.Fatalf("%s: key %d has a seed other than the strkey encoding of its raw seed", name, i)
+			}
+		}
+	}
+}
+
+// TestKeysZeroAlloc expects generating, encoding and matching keys that don't match to allocate nothing; one run
+// generates keysPerRun keys, so testing.AllocsPerRun reports the raw malloc count rather than an average rounded down
+func TestKeysZeroAlloc(t *testing.T) {
+	keys, m := newKeysMatcher(t)
+	for name, k := range keys {
+		allocs := testing.AllocsPerRun(1, func() {
+			for i := 0; i < keysPerRun; i++ {
+				if err := k.Next(); err != nil {
+					t.Fatal(err)
+				}
+				_, _ = m.Match(k.Address())
+			}
+		})
+		if allocs != 0 {
+			t.Errorf("%s: %v allocations in %d keys, want 0", name, allocs, keysPerRun)
+		}
+	}
+}
+
+// TestAllocsPerKey expects AllocsPerKey, which selftest relies on, to agree that the keygen allocates nothing
+func TestAllocsPerKey(t *testing.T) {
+	keys, m := newKeysMatcher(t)
+	for name, k := range keys {
+		allocs, err := AllocsPerKey(k, m, keysPerRun)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if allocs != 0 {
+			t.Errorf("%s: %v allocations per key, want 0", name, allocs)
+		}
+	}
+}
+
+// BenchmarkKeys measures generating, encoding and matching a key that doesn't match, with and without batching
+func BenchmarkKeys(b *testing.B) {
+	keys, m := newKeysMatcher(b)
+	for _, name := range []string{"keys", "batch"} {
+		k := keys[name]
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := k.Next(); err != nil {
+					b.Fatal(err)
+				}
+				_, _ = m.Match(k.Address())
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package vanity
+
+import (
+	"math"    // used for capping the probability of a pattern at 1
+	"strings" // used for checking patterns against the alphabet of every character
+)
+
+// checksumBytes is the length of the CRC16 checksum ending every strkey
+const checksumBytes = 2
+
+// keyBytes is the length of the random key every strkey searched for carries after its version byte
+const keyBytes = 32
+
+// Layout is where the bits of a strkey land in its characters: the version byte, the 32 random bytes of the key, the
+// bytes a search keeps fixed after it, like the id of a muxed account, the CRC16 checksum of all of them and the zero
+// bits padding the last character. The checksum depends on every bit of the key, so its characters are as random as
+// those of the key, while a fixed byte or a padding bit narrows the characters it falls in to a few; the naive 1 in 32
+// per character only holds for the characters in between
+type Layout struct {
+	alphabets []string // per character, the characters of Alphabet it can be
+	checksum  int      // the first character holding a bit of the checksum
+	uniform   [2]int   // the first and the last of the characters that can be any of Alphabet, all in a row
+}
+
+// AccountLayout is the layout of a G... address, and of every other strkey of a version byte and 32 random bytes
+var AccountLayout = NewLayout(6<<3, nil)
+
+// SeedLayout is the layout of an S... seed
+var SeedLayout = NewLayout(18<<3, nil)
+
+// NewLayout returns the layout of a strkey of version followed by 32 random bytes, the tail the search keeps fixed and
+// the checksum
+func NewLayout(version byte, tail []byte) *Layout {
+	fixed := append(append([]byte{version}, make([]byte, keyBytes)...), tail...) // the key is left at zero, it is random
+	bits := 8 * (len(fixed) + checksumBytes)
+	l := &Layout{alphabets: make([]string, (bits+4)/5), checksum: 8 * len(fixed) / 5}
+	isFixed := func(bit int) bool { // the version, the tail and the padding are fixed, the key and the checksum random
+		return bit >= bits || bit < 8 || (bit >= 8*(1+keyBytes) && bit < 8*len(fixed))
+	}
+	value := func(bit int) int {
+		if bit >= bits || bit >= 8*len(fixed) {
+			return 0
+		}
+		return int(fixed[bit/8]>>(7-bit%8)) & 1
+	}
+	for i := range l.alphabets {
+		var alphabet strings.Builder
+		for c := range len(Alphabet) {
+			possible := true
+			for j := range 5 {
+				bit := 5*i + j
+				if isFixed(bit) && (c>>(4-j))&1 != value(bit) {
+					possible = false
+				}
+			}
+			if possible {
+				alphabet.WriteByte(Alphabet[c])
+			}
+		}
+		l.alphabets[i] = alphabet.String()
+	}
+	l.uniform = [2]int{len(l.alphabets), -1}
+	for i, alphabet := range l.alphabets {
+		if len(alphabet) != len(Alphabet) {
+			if l.uniform[1] >= 0 {
+				break
+			}
+			continue
+		}
+		l.uniform[0], l.uniform[1] = min(l.uniform[0], i), i
+	}
+	return l
+}
+
+// Length returns the number of characters of the strkey
+func (l *Layout) Length() int {
+	return len(l.alphabets)
+}
+
+// Lead returns the character every strkey of the layout starts with, the G of an address
+func (l *Layout) Lead() byte {
+	return l.alphabets[0][0]
+}
+
+// Alphabet returns the characters that can appear at offset i
+func (l *Layout) Alphabet(i int) string {
+	return l.alphabets[i]
+}
+
+// Checksum returns the offset of the first character holding a bit of the checksum, every character from there on
+// spells the checksum and its padding
+func (l *Layout) Checksum() int {
+	return l.checksum
+}
+
+// PatternOffsets is PatternOffsets for a strkey of the layout
+func (l *Layout) PatternOffsets(position string, length int) (first, last int) {
+	switch position {
+	case PositionStart:
+		return 1, min(2, l.Length()-length)
+	case PositionEnd:
+		return l.Length() - length, l.Length() - length
+	default:
+		return 0, l.Length() - length
+	}
+}
+
+// OffsetProbability is OffsetProbability for a strkey of the layout
+func (l *Layout) OffsetProbability(classes []string, offset int) float64 {
+	if offset < 0 || offset+len(classes) > l.Length() {
+		return 0
+	}
+	p := 1.0
+	for i, class := range classes {
+		alphabet := l.alphabets[offset+i]
+		accepted := 0
+		for _, r := range alphabet {
+			if strings.ContainsRune(class, r) {
+				accepted++
+			}
+		}
+		if accepted == 0 {
+			return 0
+		}
+		p *= float64(accepted) / float64(len(alphabet))
+	}
+	return p
+}
+
+// ClassesProbability is ClassesProbability for a strkey of the layout: offsets whose characters can all be any of
+// Alphabet have the same chance, which is only worked out once, the others each their own
+func (l *Layout) ClassesProbability(classes []string, position string) float64 {
+	if len(classes) == 0 {
+		return 1
+	}
+	first, last := l.PatternOffsets(position, len(classes))
+	p, uniform := 0.0, -1.0
+	for offset := max(first, 0); offset <= last; offset++ {
+		if offset < l.uniform[0] || offset+len(classes)-1 > l.uniform[1] {
+			p += l.OffsetProbability(classes, offset)
+			continue
+		}
+		if uniform < 0 {
+			uniform = l.OffsetProbability(classes, offset)
+		}
+		p += uniform
+	}
+	return math.Min(1, p)
+}
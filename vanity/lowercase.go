@@ -0,0 +1,54 @@
+package vanity
+
+import (
+	"strings" // used for looking characters up in their lookalike classes
+)
+
+// Lookalikes pairs the characters that read as each other once a wallet shows an address in lowercase, such as s and 5
+// or z and 2; Options.Lowercase accepts either one for a pattern character. Digits strkey never encodes are listed
+// too, so 2024 with Lowercase can match 2O24, displayed as 2o24.
+var Lookalikes = map[rune]string{
+	'O': "0", '0': "O",
+	'L': "1", '1': "L",
+	'S': "5", '5': "S",
+	'Z': "2", '2': "Z",
+	'G': "9", '9': "G",
+	'B': "6", '6': "B",
+}
+
+// PatternClasses returns, for every character of pattern, the address characters accepted in its place: just the
+// character itself, plus its lowercase lookalike when lowercase is set
+func PatternClasses(pattern string, lowercase bool) []string {
+	classes := make([]string, 0, len(pattern))
+	for _, r := range pattern {
+		class := string(r)
+		if lowercase {
+			class += Lookalikes[r]
+		}
+		classes = append(classes, class)
+	}
+	return classes
+}
+
+// IndexClasses returns the offset of the first run of address whose characters fall in classes, or -1
+func IndexClasses(address string, classes []string) int {
+	for offset := 0; offset+len(classes) <= len(address); offset++ {
+		if MatchClasses(address, offset, classes) {
+			return offset
+		}
+	}
+	return -1
+}
+
+// MatchClasses reports whether the characters of address starting at offset fall in classes
+func MatchClasses(address string, offset int, classes []string) bool {
+	if offset < 0 || offset+len(classes) > len(address) {
+		return false
+	}
+	for i, class := range classes {
+		if strings.IndexByte(class, address[offset+i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,180 @@
+package vanity
+
+import (
+	"fmt"     // used for reporting a pattern that can never match
+	"strings" // used for finding the patterns in an address
+)
+
+// Options decide how a Matcher matches its patterns
+type Options struct {
+	Position  string  // where in the address the patterns must appear, PositionAnywhere when empty
+	Lowercase bool    // characters that read the same once the address is shown in lowercase match too
+	Prefix    string  // what every match must start with on top of the patterns, G included
+	Suffix    string  // what every match must end with on top of the patterns
+	Layout    *Layout // the strkey the patterns are matched against, AccountLayout when nil, like the layout of a muxed account
+}
+
+// Matcher finds patterns in addresses; it never changes once made, so every worker can share one
+type Matcher struct {
+	patterns    []string    // upper-cased, since addresses are upper-case
+	classes     [][]string  // per pattern, the characters accepted in place of each of its characters with Lowercase
+	position    string      // where in the address the patterns must appear
+	layout      *Layout     // the strkey the addresses are
+	prefix      string      // upper-cased Prefix every match must start with, checked before the patterns
+	suffix      string      // upper-cased Suffix every match must end with, checked before the patterns
+	affixes     [2][]string // with Lowercase, the characters accepted in place of each character of prefix and suffix
+	probability float64     // the chance a single address matches
+	compare     *compare    // the vector compare of the patterns, nil without vector instructions or when they don't fit
+}
+
+// NewMatcher returns the Matcher of patterns, rejecting a pattern, prefix or suffix that can never appear in an
+// address; an empty pattern matches every address
+func NewMatcher(patterns []string, options Options) (*Matcher, error) {
+	position := options.Position
+	if position == "" {
+		position = PositionAnywhere
+	}
+	if !ValidPosition(position) {
+		return nil, fmt.Errorf("invalid position %q: expected %s, %s or %s", position, PositionAnywhere, PositionStart, PositionEnd)
+	}
+	layout := options.Layout
+	if layout == nil {
+		layout = AccountLayout
+	}
+	m := &Matcher{patterns: make([]string, 0, len(patterns)), position: position, layout: layout,
+		prefix: strings.ToUpper(strings.TrimSpace(options.Prefix)), suffix: strings.ToUpper(strings.TrimSpace(options.Suffix))}
+	if len(m.prefix)+len(m.suffix) > layout.Length() {
+		return nil, fmt.Errorf("the prefix %s and the suffix %s are longer than an address together", m.prefix, m.suffix)
+	}
+	m.affixes = [2][]string{PatternClasses(m.prefix, options.Lowercase), PatternClasses(m.suffix, options.Lowercase)}
+	prefix, suffix := m.affixes[0], m.affixes[1]
+	if m.prefix != "" && layout.OffsetProbability(prefix, 0) == 0 {
+		return nil, fmt.Errorf("no address starts with %s", m.prefix)
+	}
+	if m.suffix != "" && layout.OffsetProbability(suffix, layout.Length()-len(suffix)) == 0 {
+		return nil, fmt.Errorf("no address ends with %s", m.suffix)
+	}
+	miss := 1.0 // the chance an address contains none of the patterns
+	all := make([][]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		pattern = strings.ToUpper(strings.TrimSpace(pattern))
+		classes := PatternClasses(pattern, options.Lowercase)
+		p := layout.ClassesProbability(classes, position)
+		if p == 0 {
+			return nil, fmt.Errorf("%s can never appear in an address at position %s", pattern, position)
+		}
+		m.patterns, all = append(m.patterns, pattern), append(all, classes)
+		if options.Lowercase {
+			m.classes = append(m.classes, classes)
+		}
+		miss *= 1 - p
+	}
+	if !options.Lowercase {
+		m.affixes = [2][]string{}
+	}
+	if vectorFind != nil {
+		m.compare = newCompare(all, position, layout)
+	}
+	m.probability = (1 - miss) * layout.OffsetProbability(prefix, 0) * layout.OffsetProbability(suffix, layout.Length()-len(suffix))
+	return m, nil
+}
+
+// Patterns returns the upper-cased patterns
+func (m *Matcher) Patterns() []string {
+	return m.patterns
+}
+
+// Probability returns the chance a single random address matches
+func (m *Matcher) Probability() float64 {
+	return m.probability
+}
+
+// Affixed reports whether address starts with the prefix and ends with the suffix, the cheap part of Match
+func (m *Matcher) Affixed(address string) bool {
+	if m.affixes[0] == nil {
+		return strings.HasPrefix(address, m.prefix) && strings.HasSuffix(address, m.suffix)
+	}
+	return MatchClasses(address, 0, m.affixes[0]) && MatchClasses(address, len(address)-len(m.affixes[1]), m.affixes[1])
+}
+
+// Index returns the offset pattern i appears at in address, -1 when it doesn't appear at the position
+func (m *Matcher) Index(address string, i int) int {
+	pattern := m.patterns[i]
+	if m.position == PositionAnywhere {
+		if m.classes != nil {
+			return IndexClasses(address, m.classes[i])
+		}
+		return strings.Index(address, pattern)
+	}
+	first, last := m.layout.PatternOffsets(m.position, len(pattern))
+	for offset := max(first, 0); offset <= last; offset++ {
+		if m.classes != nil {
+			if MatchClasses(address, offset, m.classes[i]) {
+				return offset
+			}
+		} else if address[offset:offset+len(pattern)] == pattern {
+			return offset
+		}
+	}
+	return -1
+}
+
+// Match returns the first pattern that address contains once it has the prefix and suffix, comparing the patterns
+// with the vector instructions of the CPU when it has them
+func (m *Matcher) Match(address string) (string, bool) {
+	if !m.Affixed(address) {
+		return "", false
+	}
+	if m.compare != nil && len(address) == m.layout.Length() {
+		if i := m.compare.first(address); i >= 0 {
+			return m.patterns[i], true
+		}
+		return "", false
+	}
+	for i, pattern := range m.patterns {
+		if m.Index(address, i) >= 0 {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+// Closest returns the pattern address comes closest to containing at the position and how many of its leading
+// characters it does contain there, 0 when not even the first; it ignores the prefix and the suffix, so a pattern it
+// contains in full doesn't need to be a match
+func (m *Matcher) Closest(address string) (pattern string, n int) {
+	for i, p := range m.patterns {
+		first, last := m.layout.PatternOffsets(m.position, len(p))
+		for offset := max(first, 0); offset <= last; offset++ {
+			k := 0
+			for k < len(p) && m.accepts(i, k, address[offset+k]) {
+				k++
+			}
+			if k > n {
+				pattern, n = p, k
+			}
+		}
+	}
+	return pattern, n
+}
+
+// accepts reports whether c can stand in for character k of pattern i
+func (m *Matcher) accepts(i, k int, c byte) bool {
+	if m.classes != nil {
+		return strings.IndexByte(m.classes[i][k], c) >= 0
+	}
+	return m.patterns[i][k] == c
+}
+
+// Spelling returns the characters of address that matched pattern, which differ from pattern with Lowercase
+func (m *Matcher) Spelling(address, pattern string) string {
+	for i, p := range m.patterns {
+		if p != pattern || m.classes == nil {
+			continue
+		}
+		if at := m.Index(address, i); at >= 0 {
+			return address[at : at+len(m.classes[i])]
+		}
+	}
+	return pattern
+}
@@ -0,0 +1,66 @@
+package vanity
+
+// Alphabet is the RFC 4648 alphabet strkey encodes addresses with, so 0, 1, 8 and 9 never appear in an address
+const Alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567"
+
+// AddressLength is the length of an encoded G... address
+const AddressLength = 56
+
+// the positions, where in an address the patterns must appear
+const (
+	PositionAnywhere = "anywhere" // at any offset
+	PositionStart    = "start"    // right after the leading G, or after the G and the A, B, C or D following it
+	PositionEnd      = "end"      // at the very end
+)
+
+// PatternOffsets returns the first and last offset a pattern of length characters is searched at for position; last
+// is below first when the pattern doesn't fit
+func PatternOffsets(position string, length int) (first, last int) {
+	return AccountLayout.PatternOffsets(position, length)
+}
+
+// ValidPosition reports whether position is one of the positions
+func ValidPosition(position string) bool {
+	return position == PositionAnywhere || position == PositionStart || position == PositionEnd
+}
+
+// PositionAlphabet is the set of characters that can appear at offset i of an address: the version byte fixes the
+// leading G and leaves only A, B, C or D for the second character, every other character is uniformly random, the
+// checksum of the last four included
+func PositionAlphabet(i int) string {
+	return AccountLayout.Alphabet(i)
+}
+
+// OffsetProbability is the chance that one random address has a run of characters falling in classes (see
+// PatternClasses) starting at offset, 0 when it can't fit there
+func OffsetProbability(classes []string, offset int) float64 {
+	return AccountLayout.OffsetProbability(classes, offset)
+}
+
+// PatternProbability estimates the chance that one random address contains pattern at position, with its lowercase
+// lookalikes when lowercase is set, by adding up the chance of it starting at every offset position allows; a pattern
+// with characters outside Alphabet can never match
+func PatternProbability(pattern string, lowercase bool, position string) float64 {
+	return ClassesProbability(PatternClasses(pattern, lowercase), position)
+}
+
+// ClassesProbability is PatternProbability of a pattern already turned into classes
+func ClassesProbability(classes []string, position string) float64 {
+	return AccountLayout.ClassesProbability(classes, position)
+}
+
+// AnyPatternProbability is the chance that one random address contains at least one of patterns at position
+func AnyPatternProbability(patterns []string, lowercase bool, position string) float64 {
+	miss := 1.0
+	for _, pattern := range patterns {
+		miss *= 1 - PatternProbability(pattern, lowercase, position)
+	}
+	return 1 - miss
+}
+
+// AffixProbability is the chance that one random address starts with prefix and ends with suffix, which don't overlap
+// once they fit an address together
+func AffixProbability(prefix, suffix string, lowercase bool) float64 {
+	suffixClasses := PatternClasses(suffix, lowercase)
+	return OffsetProbability(PatternClasses(prefix, lowercase), 0) * OffsetProbability(suffixClasses, AddressLength-len(suffixClasses))
+}
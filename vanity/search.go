@@ -0,0 +1,116 @@
+package vanity
+
+import (
+	"context"              // used for stopping the workers
+	"errors"               // used for refusing a search that already runs
+	"golang.org/x/sys/cpu" // used for padding the counters of the workers to a cache line
+	"sync"                 // used for waiting on the workers
+	"sync/atomic"          // used for counting the attempts of every worker
+	"time"                 // used for timestamping the matches
+)
+
+// Match is a key pair whose address matched a pattern
+type Match struct {
+	Address  string    `json:"address"`  // the public G... address
+	Seed     string    `json:"seed"`     // the secret S... seed, whoever holds it owns the account
+	Pattern  string    `json:"pattern"`  // the pattern the address contains
+	Attempts int64     `json:"attempts"` // key pairs generated by every worker when it was found
+	FoundAt  time.Time `json:"found_at"` // when it was found
+}
+
+// Scan generates n key pairs and returns those whose address m matches; it is what a caller that can't block for long,
+// like a browser page, runs one batch at a time, and what the workers of a Search run in a loop with Keys of their own
+func Scan(m *Matcher, n int) ([]Match, error) {
+	return NewKeys(nil).Scan(m, n)
+}
+
+// scanBatch is how many key pairs a worker generates between checking whether it was stopped
+const scanBatch = 256
+
+// counter counts the key pairs of one worker of a Search, padded so that no other counter shares its cache line, which
+// the cores of the workers would otherwise take turns owning
+type counter struct {
+	atomic.Int64
+	_ cpu.CacheLinePad
+}
+
+// Search runs workers generating key pairs until Stop, calling back for every match
+type Search struct {
+	matcher  *Matcher
+	attempts []counter // one per worker, summed up by Attempts
+	mu       sync.Mutex
+	cancel   context.CancelFunc // nil while stopped
+	done     sync.WaitGroup
+}
+
+// NewSearch returns a stopped search for the patterns of m on workers go-routines, at least one
+func NewSearch(m *Matcher, workers int) *Search {
+	return &Search{matcher: m, attempts: make([]counter, max(workers, 1))}
+}
+
+// Start starts the workers, which call onMatch for every match, from any of them but never two at once, and onError
+// when the keygen fails, after which that worker stops; neither may be nil
+func (s *Search) Start(onMatch func(Match), onError func(error)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		return errors.New("the search is already running")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	var callbacks sync.Mutex // the callbacks don't have to be safe for concurrent use
+	for i := range s.attempts {
+		s.done.Add(1)
+		go func(attempts *counter) {
+			defer s.done.Done()
+			keys := NewKeys(nil)
+			for ctx.Err() == nil {
+				matches, err := keys.Scan(s.matcher, scanBatch)
+				attempts.Add(scanBatch)
+				callbacks.Lock()
+				for _, match := range matches {
+					match.Attempts = s.Attempts()
+					onMatch(match)
+				}
+				if err != nil {
+					onError(err)
+				}
+				callbacks.Unlock()
+				if err != nil {
+					return
+				}
+			}
+		}(&s.attempts[i])
+	}
+	return nil
+}
+
+// Stop stops the workers and waits for them to finish the batch in hand, whose matches are still called back; a
+// callback waiting on itself never returns, so one that wants to stop the search calls Stop in a go-routine of its own
+func (s *Search) Stop() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.cancel = nil
+	s.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	s.done.Wait()
+}
+
+// Attempts returns the key pairs generated since the search was made
+func (s *Search) Attempts() int64 {
+	var total int64
+	for i := range s.attempts {
+		total += s.attempts[i].Load()
+	}
+	return total
+}
+
+// Running reports whether the workers run
+func (s *Search) Running() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cancel != nil
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bytes"         // used for sending request bodies
+	"encoding/json" // used for encoding requests to and decoding responses from the Vault HTTP API
+	"errors"        // used for reporting missing credentials
+	"fmt"           // used for wrapping errors with the Vault path
+	"io"            // used for reading error responses
+	"net/http"      // the Vault HTTP API
+	"os"            // used for reading the VAULT_* environment variables
+	"strings"       // used for parsing the vault:// -output
+	"time"          // used for the request timeout
+)
+
+// vaultScheme prefixes an -output that stores seeds in a HashiCorp Vault KV v2 secrets engine
+const vaultScheme = "vault://"
+
+// vaultTimeout bounds every request to Vault
+const vaultTimeout = 30 * time.Second
+
+// vaultBackend writes every seed to <path>/<address> in Vault, configured like the vault CLI: VAULT_ADDR, VAULT_TOKEN
+// or VAULT_ROLE_ID and VAULT_SECRET_ID for AppRole (mounted at VAULT_APPROLE_MOUNT, approle by default), and
+// VAULT_NAMESPACE on Vault Enterprise
+type vaultBackend struct {
+	addr      string        // VAULT_ADDR without the trailing slash
+	path      string        // the API path after /v1/, e.g. secret/data/xlm
+	token     string        // VAULT_TOKEN or the client token of the AppRole login
+	namespace string        // VAULT_NAMESPACE, sent as X-Vault-Namespace when set
+	client    *http.Client  // shared by every request
+	roleID    string        // VAULT_ROLE_ID, kept to log in again once the token can't be renewed
+	secretID  string        // VAULT_SECRET_ID, likewise
+	lease     time.Duration // how long the token was valid for when it was issued or renewed, 0 when it never expires
+	expires   time.Time     // when the token expires, zero when it never does
+	renewable bool          // whether the token can be renewed
+}
+
+// vaultAuth is the auth block of a login or a token renewal
+type vaultAuth struct {
+	ClientToken   string `json:"client_token"`
+	LeaseDuration int    `json:"lease_duration"` // seconds, 0 for a token that never expires
+	Renewable     bool   `json:"renewable"`
+}
+
+// vaultStatusError is a response of Vault outside 2xx
+type vaultStatusError struct {
+	status  string
+	code    int
+	message string
+}
+
+// Error returns the status and the errors Vault answered with
+func (e *vaultStatusError) Error() string {
+	return fmt.Sprintf("%s: %s", e.status, e.message)
+}
+
+// openVault parses output as vault://<path> and authenticates with the token or AppRole from the environment
+func openVault(output string) (*vaultBackend, error) {
+	v := &vaultBackend{
+		addr:      strings.TrimRight(os.Getenv("VAULT_ADDR"), "/"),
+		path:      strings.Trim(strings.TrimPrefix(output, vaultScheme), "/"),
+		token:     os.Getenv("VAULT_TOKEN"),
+		namespace: os.Getenv("VAULT_NAMESPACE"),
+		client:    &http.Client{Timeout: vaultTimeout},
+	}
+	if v.path == "" {
+		return nil, fmt.Errorf("invalid -output %s: expected %s<mount>/data/<path>", output, vaultScheme)
+	}
+	if v.addr == "" {
+		v.addr = "https://127.0.0.1:8200" // the vault CLI default
+	}
+	if v.token == "" {
+		roleID, secretID := os.Getenv("VAULT_ROLE_ID"), os.Getenv("VAULT_SECRET_ID")
+		if roleID == "" || secretID == "" {
+			return nil, errors.New("-output " + output + " needs VAULT_TOKEN, or VAULT_ROLE_ID and VAULT_SECRET_ID")
+		}
+		v.roleID, v.secretID = roleID, secretID
+		if err := v.loginAppRole(); err != nil {
+			return nil, err
+		}
+	} else {
+		var self struct {
+			Data struct {
+				TTL       int  `json:"ttl"`
+				Renewable bool `json:"renewable"`
+			} `json:"data"`
+		}
+		if err := v.request(http.MethodGet, "auth/token/lookup-self", nil, &self); err != nil {
+			return nil, fmt.Errorf("vault rejected VAULT_TOKEN: %w", err) // better now than after the first match
+		}
+		v.setLease(self.Data.TTL, self.Data.Renewable)
+	}
+	debugf("storing seeds in Vault at %s/v1/%s", v.addr, v.path)
+	return v, nil
+}
+
+// loginAppRole exchanges the AppRole role_id and secret_id for a client token
+func (v *vaultBackend) loginAppRole() error {
+	mount := os.Getenv("VAULT_APPROLE_MOUNT")
+	if mount == "" {
+		mount = "approle"
+	}
+	var login struct {
+		Auth vaultAuth `json:"auth"`
+	}
+	v.token = "" // a login doesn't take the expired token
+	if err := v.request(http.MethodPost, "auth/"+mount+"/login", map[string]string{"role_id": v.roleID, "secret_id": v.secretID}, &login); err != nil {
+		return fmt.Errorf("vault AppRole login failed: %w", err)
+	}
+	if login.Auth.ClientToken == "" {
+		return errors.New("vault AppRole login returned no token")
+	}
+	v.token = login.Auth.ClientToken
+	v.setLease(login.Auth.LeaseDuration, login.Auth.Renewable)
+	return nil
+}
+
+// setLease records how long the token is valid for, seconds from now, 0 for a token that never expires
+func (v *vaultBackend) setLease(seconds int, renewable bool) {
+	v.lease, v.expires, v.renewable = time.Duration(seconds)*time.Second, time.Time{}, renewable
+	if seconds > 0 {
+		v.expires = time.Now().Add(v.lease)
+	}
+}
+
+// refresh renews the token once less than a third of its lease is left, or right away when force is set, logging in
+// with the AppRole again when the token can't be renewed; a run outlives the TTL of its token this way
+func (v *vaultBackend) refresh(force bool) error {
+	if !force && (v.expires.IsZero() || time.Until(v.expires) > v.lease/3) {
+		return nil
+	}
+	var renewErr error
+	if v.renewable {
+		var renewed struct {
+			Auth vaultAuth `json:"auth"`
+		}
+		if renewErr = v.request(http.MethodPost, "auth/token/renew-self", nil, &renewed); renewErr == nil {
+			v.setLease(renewed.Auth.LeaseDuration, renewed.Auth.Renewable)
+			debugf("renewed the vault token for %s", v.lease)
+			return nil
+		}
+	}
+	switch {
+	case v.roleID == "" && renewErr != nil:
+		return fmt.Errorf("failed to renew the vault token: %w", renewErr)
+	case v.roleID == "":
+		return errors.New("the vault token isn't renewable, set VAULT_ROLE_ID and VAULT_SECRET_ID to log in again once it expires")
+	}
+	debugf("logging in to vault again with the AppRole")
+	return v.loginAppRole()
+}
+
+// Store writes the seed of r to <path>/<address> and leaves the vault:// reference in r instead
+func (v *vaultBackend) Store(r *result) error {
+	path := v.path + "/" + r.Address
+	secret := map[string]any{"data": map[string]any{"address": r.Address, "seed": r.Seed, "pattern": r.Pattern, "found_at": r.FoundAt}}
+	if err := v.refresh(false); err != nil {
+		debugf("%v", err) // the write tells whether the token still works
+	}
+	err := v.request(http.MethodPost, path, secret, nil)
+	var status *vaultStatusError
+	if errors.As(err, &status) && status.code == http.StatusForbidden { // expired or revoked early, renew or log in and try again
+		if refreshErr := v.refresh(true); refreshErr != nil {
+			err = errors.Join(err, refreshErr)
+		} else {
+			err = v.request(http.MethodPost, path, secret, nil)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %s to vault: %w", path, err)
+	}
+	r.SeedRef, r.Seed = vaultScheme+path, ""
+	return nil
+}
+
+// request sends body as JSON to /v1/<path> and decodes the response into out when out is not nil
+func (v *vaultBackend) request(method, path string, body, out any) error {
+	var payload []byte
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		payload = encoded
+		defer zeroize(payload) // it may carry a seed or a secret_id
+	}
+	req, err := http.NewRequest(method, v.addr+"/v1/"+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if v.token != "" {
+		req.Header.Set("X-Vault-Token", v.token)
+	}
+	if v.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.namespace)
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return &vaultStatusError{status: resp.Status, code: resp.StatusCode, message: strings.TrimSpace(string(message))}
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
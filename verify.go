@@ -0,0 +1,161 @@
+package main
+
+import (
+	"flag"                          // the verify subcommand parses its own arguments
+	"fmt"                           // used for writing the report to STDOUT
+	"github.com/stellar/go/keypair" // used for re-deriving the address from each seed
+	"github.com/stellar/go/strkey"  // used for telling a seed argument apart from a results file
+	"os"                            // access the filesystem
+)
+
+// verifyEntry is the outcome of re-deriving the address of one seed
+type verifyEntry struct {
+	Source  string // the results file and index, or "argument" for a seed passed directly
+	Address string // the address on record, empty for a seed passed directly
+	Derived string // the address derived from the seed, empty when the seed is corrupt
+	Err     error  // why the entry is corrupt, nil when it is consistent
+	Note    string // how the seed is stored when it isn't in the results file, checked by other means
+}
+
+// verifyReport is the --json document of verify
+type verifyReport struct {
+	Verified int               `json:"verified"` // entries whose seed derives their address
+	Corrupt  int               `json:"corrupt"`  // entries whose seed doesn't
+	Entries  []verifyJSONEntry `json:"entries"`  // every entry checked
+}
+
+// verifyJSONEntry is a verifyEntry in the --json document of verify
+type verifyJSONEntry struct {
+	Source  string `json:"source"`
+	Address string `json:"address,omitempty"`
+	Derived string `json:"derived,omitempty"`
+	OK      bool   `json:"ok"`
+	Error   string `json:"error,omitempty"`
+	Note    string `json:"note,omitempty"`
+}
+
+// runVerify implements `xlm-vanity-address-finder verify <seed|results.json>...`, re-deriving the address of every seed
+// with keypair.ParseFull and reporting whether each address/seed pair is consistent; it returns the process exit code
+func runVerify(args []string) int {
+	flags := flag.NewFlagSet("verify", flag.ExitOnError)
+	flags.Usage = func() {
+		_, _ = fmt.Fprintf(flags.Output(), "Usage: %s verify [-json] <seed|results.json>...\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	asJSON := flags.Bool("json", jsonOutput, "Print a single JSON document instead of the report")
+	_ = flags.Parse(args) // ExitOnError handles the error
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	entries := make([]verifyEntry, 0)
+	for _, arg := range flags.Args() {
+		if strkey.IsValidEd25519SecretSeed(arg) {
+			entries = append(entries, verifySeed("argument", "", arg))
+			continue
+		}
+		fileEntries, err := verifyFile(arg)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "%s: %v\n", arg, err)
+			return 1
+		}
+		entries = append(entries, fileEntries...)
+	}
+
+	corrupt := 0
+	doc := verifyReport{Entries: make([]verifyJSONEntry, 0, len(entries))}
+	for _, entry := range entries {
+		item := verifyJSONEntry{Source: entry.Source, Address: entry.Address, Derived: entry.Derived, OK: entry.Err == nil, Note: entry.Note}
+		switch {
+		case entry.Err != nil:
+			corrupt++
+			item.Error = entry.Err.Error()
+			if !*asJSON {
+				fmt.Printf("CORRUPT  %s %s: %v\n", entry.Source, entry.Address, entry.Err)
+			}
+		case *asJSON:
+		case entry.Note != "":
+			fmt.Printf("OK       %s %s (%s)\n", entry.Source, entry.Derived, entry.Note)
+		default:
+			fmt.Printf("OK       %s %s\n", entry.Source, entry.Derived)
+		}
+		doc.Entries = append(doc.Entries, item)
+	}
+	doc.Verified, doc.Corrupt = len(entries)-corrupt, corrupt
+	if *asJSON {
+		if err := printJSON(doc); err != nil {
+			return 1
+		}
+	} else {
+		fmt.Printf("%d verified, %d corrupt\n", doc.Verified, corrupt)
+	}
+	if corrupt > 0 {
+		return 1
+	}
+	return 0
+}
+
+// verifyFile re-derives every result of an output file
+func verifyFile(path string) ([]verifyEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := decodeDocument(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode: %w", err)
+	}
+	entries := make([]verifyEntry, 0, len(doc.Results))
+	for i, r := range doc.Results {
+		entries = append(entries, verifyResult(fmt.Sprintf("%s[%d]", path, i), r))
+	}
+	return entries, nil
+}
+
+// verifyResult checks the seed of r, and its mnemonic with -mnemonic, against its address; a seed that was split with -shamir-shares, sealed with -kms-key
+// or sent to a secret store can't be checked from the results file alone, so such a result is taken at its word, or
+// at its proof of possession when it has one; a proof that doesn't check out makes r corrupt
+func verifyResult(source string, r result) verifyEntry {
+	if r.Proof != nil {
+		if err := r.Proof.Verify(r.Address); err != nil {
+			return verifyEntry{Source: source, Address: r.Address, Err: fmt.Errorf("invalid proof of possession: %w", err)}
+		}
+	}
+	switch {
+	case r.Seed == "" && r.Shamir != nil:
+		return verifyEntry{Source: source, Address: r.Address, Derived: r.Address,
+			Note: fmt.Sprintf("seed split into %d-of-%d shares", r.Shamir.Threshold, len(r.Shamir.Files))}
+	case r.Seed == "" && r.SeedRef != "":
+		return verifyEntry{Source: source, Address: r.Address, Derived: r.Address, Note: "seed stored in " + r.SeedRef}
+	case r.Seed == "" && r.EncryptedSeed != nil:
+		return verifyEntry{Source: source, Address: r.Address, Derived: r.Address, Note: "seed sealed with " + r.EncryptedSeed.KeyID}
+	case r.Seed == "" && r.Proof != nil:
+		return verifyEntry{Source: source, Address: r.Address, Derived: r.Address, Note: "seed withheld, proven by " + r.Proof.Message}
+	}
+	if r.Mnemonic != "" {
+		if entry := verifyMnemonic(source, r.Address, r.Mnemonic, r.Path); entry.Err != nil {
+			return entry
+		}
+	}
+	entry := verifySeed(source, r.Address, r.Seed)
+	if entry.Err == nil && r.Strkey != "" {
+		entry.Err = verifyStrkey(r)
+	}
+	return entry
+}
+
+// verifySeed parses seed and compares the address it derives with address, unless address is empty
+func verifySeed(source, address, seed string) verifyEntry {
+	entry := verifyEntry{Source: source, Address: address}
+	pair, err := keypair.ParseFull(seed)
+	if err != nil {
+		entry.Err = fmt.Errorf("invalid seed: %w", err)
+		return entry
+	}
+	entry.Derived = pair.Address()
+	if address != "" && address != entry.Derived {
+		entry.Err = fmt.Errorf("seed derives %s", entry.Derived)
+	}
+	return entry
+}
@@ -0,0 +1,167 @@
+//go:build js && wasm
+
+// Command wasm is the search of xlm-vanity-address-finder for a browser page, built with
+// GOOS=js GOARCH=wasm go build -o wasm/vanity.wasm ./wasm and loaded with the wasm_exec.js of the same Go; it sets
+// globalThis.xlmVanity and the key pairs it generates never leave the page
+package main
+
+import (
+	"errors"                                                     // used for describing options that aren't an object
+	"github.com/andreimerlescu/xlm-vanity-address-finder/vanity" // the search core
+	"strings"                                                    // used for splitting the patterns
+	"syscall/js"                                                 // the page calling the search
+	"time"                                                       // used for yielding to the page between batches
+)
+
+// tickBudget is how long a batch runs before the page gets to draw and handle its events again
+const tickBudget = 50 * time.Millisecond
+
+// tickScan is how many key pairs are generated between looking at the clock
+const tickScan = 16
+
+// hunt is the search running in the page, one at a time since the page has a single thread
+type hunt struct {
+	matcher    *vanity.Matcher
+	onMatch    js.Value
+	onProgress js.Value
+	tick       js.Func
+	timer      js.Value // the setTimeout of the next tick
+	attempts   int64
+	started    time.Time
+}
+
+// running is the search started last, nil once it stopped
+var running *hunt
+
+func main() {
+	js.Global().Set("xlmVanity", js.ValueOf(map[string]any{
+		"check": js.FuncOf(check),
+		"start": js.FuncOf(start),
+		"stop":  js.FuncOf(stop),
+	}))
+	select {} // the functions above are called for as long as the page is open
+}
+
+// matcher builds the vanity.Matcher of args[0], patterns separated by commas, and args[1], an optional object of
+// position, lowercase, prefix and suffix
+func matcher(args []js.Value) (*vanity.Matcher, error) {
+	if len(args) == 0 || args[0].Type() != js.TypeString {
+		return nil, errors.New("expected the patterns, separated by commas")
+	}
+	var patterns []string
+	for _, pattern := range strings.Split(args[0].String(), ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	if len(patterns) == 0 {
+		return nil, errors.New("expected at least one pattern")
+	}
+	var options vanity.Options
+	if len(args) > 1 && !args[1].IsUndefined() && !args[1].IsNull() {
+		if args[1].Type() != js.TypeObject {
+			return nil, errors.New("expected the options to be an object")
+		}
+		option := func(name string) js.Value { return args[1].Get(name) }
+		if value := option("position"); value.Type() == js.TypeString {
+			options.Position = value.String()
+		}
+		options.Lowercase = option("lowercase").Truthy()
+		if value := option("prefix"); value.Type() == js.TypeString {
+			options.Prefix = value.String()
+		}
+		if value := option("suffix"); value.Type() == js.TypeString {
+			options.Suffix = value.String()
+		}
+	}
+	return vanity.NewMatcher(patterns, options)
+}
+
+// failure is what check and start return for err
+func failure(err error) any {
+	return map[string]any{"error": err.Error()}
+}
+
+// check is xlmVanity.check(patterns, options), returning {patterns, probability} or {error} without searching, for
+// validating a form as it is typed
+func check(_ js.Value, args []js.Value) any {
+	m, err := matcher(args)
+	if err != nil {
+		return failure(err)
+	}
+	patterns := make([]any, 0, len(m.Patterns()))
+	for _, pattern := range m.Patterns() {
+		patterns = append(patterns, pattern)
+	}
+	return map[string]any{"patterns": patterns, "probability": m.Probability()}
+}
+
+// start is xlmVanity.start(patterns, options, onMatch, onProgress), stopping the search running before; onMatch gets
+// every match as {address, seed, pattern, attempts, foundAt} and onProgress {attempts, rate, seconds} after every
+// batch; it returns {error} for patterns that can't be searched, null otherwise
+func start(_ js.Value, args []js.Value) any {
+	m, err := matcher(args)
+	if err != nil {
+		return failure(err)
+	}
+	if len(args) < 3 || args[2].Type() != js.TypeFunction {
+		return failure(errors.New("expected onMatch to be a function"))
+	}
+	stop(js.Undefined(), nil)
+	h := &hunt{matcher: m, onMatch: args[2], onProgress: js.Undefined(), started: time.Now()}
+	if len(args) > 3 && args[3].Type() == js.TypeFunction {
+		h.onProgress = args[3]
+	}
+	h.tick = js.FuncOf(func(js.Value, []js.Value) any {
+		h.run()
+		return nil
+	})
+	running = h
+	h.schedule()
+	return nil
+}
+
+// stop is xlmVanity.stop(), stopping the search running if there is one
+func stop(js.Value, []js.Value) any {
+	if running == nil {
+		return nil
+	}
+	js.Global().Call("clearTimeout", running.timer)
+	running.tick.Release()
+	running = nil
+	return nil
+}
+
+// schedule runs the next batch once the page handled its events
+func (h *hunt) schedule() {
+	h.timer = js.Global().Call("setTimeout", h.tick, 0)
+}
+
+// run generates key pairs for tickBudget, calls back for the matches and the progress, and schedules the next batch
+// unless a callback stopped the search
+func (h *hunt) run() {
+	deadline := time.Now().Add(tickBudget)
+	for time.Now().Before(deadline) {
+		matches, err := vanity.Scan(h.matcher, tickScan)
+		h.attempts += tickScan
+		for _, match := range matches {
+			h.onMatch.Invoke(map[string]any{"address": match.Address, "seed": match.Seed, "pattern": match.Pattern,
+				"attempts": h.attempts, "foundAt": match.FoundAt.Format(time.RFC3339)})
+			if running != h {
+				return
+			}
+		}
+		if err != nil {
+			js.Global().Get("console").Call("error", "xlmVanity: "+err.Error())
+			stop(js.Undefined(), nil)
+			return
+		}
+	}
+	if !h.onProgress.IsUndefined() {
+		seconds := time.Since(h.started).Seconds()
+		h.onProgress.Invoke(map[string]any{"attempts": h.attempts, "rate": float64(h.attempts) / seconds, "seconds": seconds})
+	}
+	if running == h {
+		h.schedule()
+	}
+}
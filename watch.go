@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"                                            // used for stopping the watcher
+	"github.com/stellar/go/clients/horizonclient"        // used for reading the first operation of a watched account
+	"github.com/stellar/go/protocols/horizon/operations" // the create_account operation that funded it
+	"sync"                                               // the main loop adds the addresses the watcher polls
+	"time"                                               // used for the polling interval
+)
+
+// minWatchEvery is the shortest -watch-every, Horizon allows every client 3600 requests an hour
+const minWatchEvery = 5 * time.Second
+
+// watched is an address -watch waits on, and how it was funded once it is
+type watched struct {
+	Address string
+	Pattern string   // the pattern of the result, which routes the funding to its -output-template file
+	Funding *funding // nil until the account exists
+}
+
+// watcher polls Horizon until every found address it is given receives its first payment; one goroutine polls every
+// address in turn every -watch-every, so a short pattern with many matches doesn't multiply the requests to Horizon
+type watcher struct {
+	network  stellarNetwork
+	every    time.Duration
+	mu       sync.Mutex
+	watching []watched    // the addresses not funded yet
+	funded   chan watched // every address once it is funded
+	pending  int          // addresses watched and not received from funded yet, only the main loop touches it
+}
+
+// newWatcher returns the watcher of the addresses found on n, polling until ctx is done
+func newWatcher(ctx context.Context, n stellarNetwork, every time.Duration) *watcher {
+	w := &watcher{network: n, every: every, funded: make(chan watched, 16)}
+	go w.run(ctx)
+	return w
+}
+
+// Watch starts watching address, the result of pattern
+func (w *watcher) Watch(address, pattern string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.watching = append(w.watching, watched{Address: address, Pattern: pattern})
+	w.pending++
+}
+
+// run polls every watched address every w.every and sends those that were funded on w.funded
+func (w *watcher) run(ctx context.Context) {
+	ticker := time.NewTicker(w.every)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		w.mu.Lock()
+		polled := append([]watched(nil), w.watching...)
+		w.mu.Unlock()
+		for _, a := range polled {
+			funded, err := firstFunding(w.network, a.Address)
+			if err != nil {
+				debugf("-watch failed to poll %s: %v", a.Address, err) // Horizon hiccups, the next tick tries again
+				continue
+			}
+			if funded == nil {
+				continue
+			}
+			w.forget(a.Address)
+			a.Funding = funded
+			select {
+			case w.funded <- a:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// forget stops polling address
+func (w *watcher) forget(address string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for i, a := range w.watching {
+		if a.Address == address {
+			w.watching = append(w.watching[:i], w.watching[i+1:]...)
+			return
+		}
+	}
+}
+
+// firstFunding returns how the account of address was funded on n, read from the first operation of its history; it is
+// nil while the account doesn't exist
+func firstFunding(n stellarNetwork, address string) (*funding, error) {
+	page, err := n.Horizon.Operations(horizonclient.OperationRequest{ForAccount: address, Order: horizonclient.OrderAsc, Limit: 1,
+		Join: "transactions"})
+	if horizonclient.IsNotFoundError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(page.Embedded.Records) == 0 {
+		return nil, nil
+	}
+	first := page.Embedded.Records[0]
+	base := first.GetBase()
+	funded := &funding{Network: n.Name, Source: base.SourceAccount, Hash: base.TransactionHash}
+	if created, ok := first.(operations.CreateAccount); ok { // the funder pays, even when the transaction came from elsewhere
+		funded.Source, funded.Amount = created.Funder, created.StartingBalance
+	}
+	if base.Transaction != nil {
+		funded.Ledger = base.Transaction.Ledger
+	}
+	return funded, nil
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bufio"             // used for reading the answers line by line
+	"fmt"               // used for asking the questions
+	"golang.org/x/term" // the wizard only starts on a terminal
+	"io"                // the questions and answers go through a reader and a writer
+	"os"                // used for checking the environment and naming the executable
+	"path/filepath"     // used for the default output file and the name of the executable
+	"runtime"           // used for measuring on every core
+	"strconv"           // used for parsing durations given in days or minutes
+	"strings"           // used for cleaning up the answers
+	"time"              // used for the duration of the search
+)
+
+// wizardMeasure is how long the wizard measures this machine to tell how long the search takes
+const wizardMeasure = 2 * time.Second
+
+// wantsWizard reports whether a launch with args should start the wizard: no arguments at all, no FIND, DICTIONARY or
+// CONFIG in the environment doing the configuring instead, and a terminal to ask the questions on
+func wantsWizard(args []string) bool {
+	for _, key := range []string{cKeyFind, cKeyDictionary, cKeyConfig} {
+		if os.Getenv(strings.ToUpper(key)) != "" {
+			return false
+		}
+	}
+	return len(args) == 0 && term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// wizard asks first-time users for a search one question at a time
+type wizard struct {
+	in  *bufio.Reader // the answers, one per line
+	out io.Writer     // the questions
+}
+
+// ask prints question with the answer taken on an empty line, and returns the answer; io.EOF means the user closed the
+// input with Ctrl+D
+func (w *wizard) ask(question, answer string) (string, error) {
+	if answer != "" {
+		_, _ = fmt.Fprintf(w.out, "%s [%s]: ", question, answer)
+	} else {
+		_, _ = fmt.Fprintf(w.out, "%s: ", question)
+	}
+	line, err := w.in.ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	if line = strings.TrimSpace(line); line != "" {
+		return line, nil
+	}
+	return answer, nil
+}
+
+// runWizard asks for the word, where it should appear, how long to search and where to save the results, shows how
+// hard that is on this machine and returns the flags of that search once the user confirms it; ok is false when the
+// user decided not to start
+func runWizard(in io.Reader, out io.Writer) (args []string, ok bool, err error) {
+	w := &wizard{in: bufio.NewReader(in), out: out}
+	_, _ = fmt.Fprintf(out, "No flags given, so let's set up a search. Press Enter to take the [answer], Ctrl+C to quit, -help lists every flag.\n\n")
+
+	var find string
+	for find == "" {
+		answer, err := w.ask("Word to look for, letters A-Z and digits 2-7 (several separated by commas)", "")
+		if err != nil {
+			return nil, false, err
+		}
+		find = answer
+		for _, pattern := range parsePatterns(answer) {
+			if pattern == "" { // nothing but commas
+				find = ""
+			} else if report := inspectPattern(pattern, false, positionAnywhere); !report.Feasible() {
+				_, _ = fmt.Fprintf(out, "%s can never appear in an address: %s\n", pattern, report.Reason())
+				find = ""
+			}
+		}
+	}
+
+	_, _ = fmt.Fprintf(out, "Where should it appear?\n  1) anywhere, the quickest to find\n  2) at the start, right after the G\n  3) at the end\n")
+	positions := map[string]string{"1": positionAnywhere, "2": positionStart, "3": positionEnd,
+		positionAnywhere: positionAnywhere, positionStart: positionStart, positionEnd: positionEnd}
+	var m *matcher
+	var position string
+	for m == nil {
+		answer, err := w.ask("Position", "1")
+		if err != nil {
+			return nil, false, err
+		}
+		if position = positions[strings.ToLower(answer)]; position == "" {
+			_, _ = fmt.Fprintf(out, "Answer 1, 2 or 3\n")
+			continue
+		}
+		if m, err = newMatcher(find, matchOptions{position: position}); err != nil {
+			_, _ = fmt.Fprintf(out, "%v\n", err)
+		}
+	}
+
+	var stop time.Duration
+	for stop == 0 {
+		answer, err := w.ask("How long to search at most, like 30m, 8h or 2d", "1h")
+		if err != nil {
+			return nil, false, err
+		}
+		if stop, err = parseWizardDuration(answer); err != nil {
+			_, _ = fmt.Fprintf(out, "%v\n", err)
+		}
+	}
+
+	output, err := w.ask("Where to save the results", filepath.Join(".", strings.Join(m.patterns, "-")+".json"))
+	if err != nil {
+		return nil, false, err
+	}
+
+	where := "anywhere in the address"
+	if position != positionAnywhere {
+		where = "at the " + position + " of the address"
+	}
+	_, _ = fmt.Fprintf(out, "\n%s %s: %s\n", orList(m.patterns), where, difficulty(m.probability))
+	_, _ = fmt.Fprintf(out, "Measuring this machine for %s...\n", wizardMeasure)
+	attempts, _, elapsed := benchmark(runtime.GOMAXPROCS(0), wizardMeasure, m, false)
+	rate := float64(attempts) / elapsed
+	e := newEstimate(m.probability)
+	_, _ = fmt.Fprintf(out, "At %s addresses per second: 50%% chance of a match after %s and 99%% after %s, %.1f%% within %s\n\n",
+		FormatInt64(int64(rate)), formatETA(e.Attempts50, rate), formatETA(e.Attempts99, rate),
+		cumulativeProbability(m.probability, int64(rate*stop.Seconds()))*100, stop)
+
+	answer, err := w.ask("Start searching? (y/n)", "y")
+	if err != nil {
+		return nil, false, err
+	}
+	if !strings.HasPrefix(strings.ToLower(answer), "y") {
+		return nil, false, nil
+	}
+
+	args = []string{"-" + cKeyFind, strings.Join(m.patterns, ","), "-" + cKeyStop, strconv.Itoa(int(stop.Seconds())), "-" + cKeyOutput, output}
+	if position != positionAnywhere {
+		args = append(args, "-"+cKeyPosition, position)
+	}
+	shown := make([]string, 0, len(args))
+	for _, arg := range args {
+		if strings.ContainsAny(arg, " \t'\"") {
+			arg = strconv.Quote(arg)
+		}
+		shown = append(shown, arg)
+	}
+	_, _ = fmt.Fprintf(out, "Next time, skip the questions with: %s %s\n\n", filepath.Base(os.Args[0]), strings.Join(shown, " "))
+	return args, true, nil
+}
+
+// parseWizardDuration parses how long to search: a time.Duration like 90m or 8h, whole days like 2d, or plain minutes
+func parseWizardDuration(answer string) (time.Duration, error) {
+	var stop time.Duration
+	var err error
+	if days, found := strings.CutSuffix(answer, "d"); found {
+		var n int
+		n, err = strconv.Atoi(days)
+		stop = time.Duration(n) * 24 * time.Hour
+	} else if minutes, atoiErr := strconv.Atoi(answer); atoiErr == nil {
+		stop = time.Duration(minutes) * time.Minute
+	} else {
+		stop, err = time.ParseDuration(answer)
+	}
+	if err != nil || stop < time.Second {
+		return 0, fmt.Errorf("invalid duration %q: expected something like 30m, 8h or 2d", answer)
+	}
+	return stop, nil
+}